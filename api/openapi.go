@@ -0,0 +1,11 @@
+// Package api holds the OpenAPI description of the versioned REST API
+package api
+
+import (
+	_ "embed"
+)
+
+// OpenAPISpec is the OpenAPI 3 document served at /api/v1/openapi.json
+//
+//go:embed openapi.json
+var OpenAPISpec []byte
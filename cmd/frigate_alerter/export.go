@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/vibin/frigate_alerter/internal/adapters"
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/logger"
+)
+
+// runExport implements the export subcommand and returns the process exit code
+func runExport(args []string) int {
+	// The export may be written to stdout, so logs go to stderr
+	logger.Configure(logger.Config{
+		Level:  slog.LevelWarn,
+		Output: os.Stderr,
+	})
+
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: frigate_alerter export [flags]")
+		fmt.Fprintln(flags.Output(), "Exports stored alerts, oldest first.")
+		flags.PrintDefaults()
+	}
+	formatName := flags.String("format", "csv", "export format: csv, ndjson or zip")
+	output := flags.String("output", "", "file to write to (default stdout)")
+	camera := flags.String("camera", "", "only export alerts of this camera")
+	label := flags.String("label", "", "only export alerts with this label")
+	alertType := flags.String("type", "", "only export alerts of this type")
+	severity := flags.String("severity", "", "only export alerts of this severity: info, warning or critical")
+	from := flags.String("from", "", "only export alerts at or after this time (RFC 3339 or YYYY-MM-DD)")
+	to := flags.String("to", "", "only export alerts before this time (RFC 3339 or YYYY-MM-DD)")
+	database := flags.String("db", "./data/alerts.db", "path of the alerts database")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	format, err := application.ParseExportFormat(*formatName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to load configuration:", err)
+		return 1
+	}
+
+	if *severity != "" && !domain.IsSeverity(*severity) {
+		fmt.Fprintln(os.Stderr, "Invalid -severity: must be info, warning or critical")
+		return 2
+	}
+
+	filter := domain.AlertFilter{Camera: *camera, Label: *label, Type: *alertType, Severity: *severity}
+	if *from != "" {
+		if filter.From, err = domain.ParseFilterTime(*from, cfg.Location); err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid -from:", err)
+			return 2
+		}
+	}
+	if *to != "" {
+		if filter.To, err = domain.ParseFilterTime(*to, cfg.Location); err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid -to:", err)
+			return 2
+		}
+	}
+
+	if _, err := os.Stat(*database); err != nil {
+		fmt.Fprintln(os.Stderr, "Alerts database not found:", err)
+		return 1
+	}
+	repository, err := adapters.NewSQLiteAlertRepository(*database, cfg.Location)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to open alerts database:", err)
+		return 1
+	}
+	defer repository.Close()
+
+	var w io.Writer = os.Stdout
+	var file *os.File
+	if *output != "" {
+		if file, err = os.Create(*output); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to create output file:", err)
+			return 1
+		}
+		w = file
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	exportService := application.NewExportService(repository, adapters.NewFrigateService(cfg), cfg)
+	err = exportService.Export(ctx, w, format, filter)
+	if file != nil {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -1,20 +1,30 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
-	"sync"
 	"syscall"
 
 	"github.com/vibin/frigate_alerter/internal/adapters"
 	"github.com/vibin/frigate_alerter/internal/application"
 	"github.com/vibin/frigate_alerter/internal/config"
-	"github.com/vibin/frigate_alerter/internal/domain"
 	"github.com/vibin/frigate_alerter/internal/logger"
+	"github.com/vibin/frigate_alerter/internal/ports"
 )
 
 func main() {
+	// Subcommands run instead of the service
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		os.Exit(runExport(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "test-rules" {
+		os.Exit(runTestRules(os.Args[2:]))
+	}
+
 	// Initialize logger with JSON formatting
 	logger.Configure(logger.Config{
 		Level: slog.LevelInfo,
@@ -45,73 +55,207 @@ func main() {
 		slog.Error("Failed to create SQLite repository", "error", err)
 		os.Exit(1)
 	}
-	defer repository.Close()
 
-	// Create Discord notifier
+	// Create Discord notifier; the gateway connection is retried in the background
 	notifier, err := adapters.NewDiscordNotifier(
 		cfg.DiscordToken,
 		cfg.DiscordChannelID,
 		cfg.FrigateServer,
 		cfg.FrigatePort,
+		cfg.DiscordCriticalMention,
 	)
 	if err != nil {
 		slog.Error("Failed to create Discord notifier", "error", err)
 		os.Exit(1)
 	}
-	defer notifier.Close()
 
-	// Create alert service
-	alertService := application.NewAlertService(repository, notifier, cfg)
+	// Create the snooze service that silences cameras for a while
+	snoozeService := application.NewSnoozeService(repository, cfg)
 
-	// Create MQTT subscriber
-	subscriber, err := adapters.NewMQTTSubscriber(cfg.MQTTServer)
-	if err != nil {
-		slog.Error("Failed to create MQTT subscriber", "error", err)
-		os.Exit(1)
-	}
-	defer subscriber.Close()
+	// Create the watchlist service that mutes known faces and plates and escalates unwanted ones
+	watchlistService := application.NewWatchlistService(repository, cfg)
 
-	// Subscribe to Frigate events
-	err = subscriber.Subscribe(func(event *domain.FrigateEvent) {
-		if err := alertService.ProcessEvent(event); err != nil {
-			slog.Error("Error processing event", "error", err, "event_type", event.Type)
-		}
-	})
-	if err != nil {
-		slog.Error("Failed to subscribe to MQTT topic", "error", err)
-		os.Exit(1)
-	}
+	// Create the mode service that arms and disarms cameras by presence, schedule or on request
+	presenceService := application.NewPresenceService(cfg)
+	modeService := application.NewModeService(repository, presenceService, cfg)
 
-	slog.Info("Frigate Alerter service started successfully")
-	slog.Info("Listening for events", "mqtt_server", cfg.MQTTServer)
+	// Create the incident service that groups alerts on adjacent cameras into one notification
+	incidentService := application.NewIncidentService(repository, repository, notifier, cfg)
+
+	// Create the detectors that follow objects through zones for loitering and zone sequence rules
+	loiteringDetector := application.NewLoiteringDetector(cfg)
+	zoneSequenceDetector := application.NewZoneSequenceDetector(cfg)
 
 	// Create the Frigate service
 	frigateService := adapters.NewFrigateService(cfg)
-	
+
+	// Create the digest service that summarizes alerts held back during quiet hours
+	digestService := application.NewDigestService(repository, notifier, frigateService, cfg)
+	go digestService.Run()
+
+	// Create alert service and the queue that feeds it
+	alertService := application.NewAlertService(application.AlertServiceDeps{
+		Repository: repository,
+		Notifier:   notifier,
+		Snoozes:    snoozeService,
+		Modes:      modeService,
+		Incidents:  incidentService,
+		Loitering:  loiteringDetector,
+		Sequences:  zoneSequenceDetector,
+		Watchlist:  watchlistService,
+		Digests:    digestService,
+	}, cfg)
+	eventQueue := application.NewEventQueue(alertService)
+	go eventQueue.Run()
+	go loiteringDetector.Run(alertService.RaiseLoitering)
+
+	// Create the detector that compares the alert volume of each camera with its usual volume
+	anomalyDetector := application.NewAnomalyDetector(repository, cfg)
+	go anomalyDetector.Run(alertService.RaiseAnomaly)
+
+	// Create the monitor that raises alerts from Frigate's live object counts
+	countMonitor := application.NewCountMonitor(alertService, cfg)
+
+	// Create MQTT subscriber; the broker connection is retried in the background
+	subscriber := adapters.NewMQTTSubscriber(cfg.MQTTServer)
+
+	// Create the health service used by the readiness endpoint
+	healthService := application.NewHealthService(cfg, subscriber, notifier, frigateService, repository)
+
+	// Create the export service used by the export endpoint
+	exportService := application.NewExportService(repository, frigateService, cfg)
+
+	// Create the review service used to acknowledge, annotate and flag alerts
+	reviewService := application.NewReviewService(repository, frigateService, cfg)
+
+	// Collect the notifiers escalation steps and reports can use; email and Telegram are optional
+	notifiers := map[string]ports.AlertNotifier{config.NotifierDiscord: notifier}
+	reportNotifiers := map[string]ports.ReportNotifier{config.NotifierDiscord: notifier}
+	if cfg.SMTPHost != "" {
+		emailNotifier := adapters.NewEmailNotifier(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.EmailFrom, cfg.EmailTo)
+		notifiers[config.NotifierEmail] = emailNotifier
+		reportNotifiers[config.NotifierEmail] = emailNotifier
+	}
+	if cfg.TelegramToken != "" {
+		telegramNotifier := adapters.NewTelegramNotifier(cfg.TelegramToken, cfg.TelegramChatID)
+		notifiers[config.NotifierTelegram] = telegramNotifier
+		reportNotifiers[config.NotifierTelegram] = telegramNotifier
+	}
+
+	// Create the escalation scheduler that re-notifies about unacknowledged alerts
+	escalationService := application.NewEscalationService(repository, notifiers, cfg)
+	go escalationService.Run()
+
+	// Record dependency outages and send the daily and weekly reports that list them
+	healthMonitor := application.NewHealthMonitor(healthService, repository, cfg)
+	go healthMonitor.Run()
+	reportService := application.NewReportService(repository, repository, reportNotifiers, cfg)
+	go reportService.Run()
+
 	// Create the HTTP server
-	httpServer := adapters.NewHTTPServer(repository, notifier, frigateService, cfg)
+	httpServer, err := adapters.NewHTTPServer(adapters.HTTPServerDeps{
+		Repository:       repository,
+		Notifier:         notifier,
+		FrigateService:   frigateService,
+		HealthService:    healthService,
+		ExportService:    exportService,
+		ReviewService:    reviewService,
+		SnoozeService:    snoozeService,
+		ModeService:      modeService,
+		IncidentService:  incidentService,
+		WatchlistService: watchlistService,
+		ReportService:    reportService,
+		AlertService:     alertService,
+	}, cfg)
+	if err != nil {
+		slog.Error("Failed to create HTTP server", "error", err)
+		os.Exit(1)
+	}
 	
-	// Start the HTTP server in a separate goroutine
-	var wg sync.WaitGroup
-	wg.Add(1)
+	// Start the HTTP server first so the UI is available while dependencies are still connecting
 	go func() {
-		defer wg.Done()
-		if err := httpServer.Start(); err != nil {
+		if err := httpServer.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			slog.Error("HTTP server error", "error", err)
 		}
 	}()
 	
 	slog.Info("Frigate Alerter service is running", "web_ui", "http://localhost:"+cfg.ServerPort)
+
+	// Subscribe to Frigate events
+	err = subscriber.Subscribe(eventQueue.Enqueue)
+	if err != nil {
+		slog.Error("Failed to subscribe to MQTT topic", "error", err)
+		os.Exit(1)
+	}
+
+	// Accept mode changes from alarm panels and home automation
+	if modeService.Enabled() {
+		if err := subscriber.SubscribeTopic(cfg.ModeCommandTopic, modeService.HandleCommand); err != nil {
+			slog.Error("Failed to subscribe to mode command topic", "error", err, "topic", cfg.ModeCommandTopic)
+		}
+		for _, topic := range presenceService.Topics() {
+			if err := subscriber.SubscribeTopic(topic, presenceService.HandleState); err != nil {
+				slog.Error("Failed to subscribe to presence topic", "error", err, "topic", topic)
+			}
+		}
+	}
+
+	// Follow the object counts and conditions of count rules
+	for _, topic := range countMonitor.Topics() {
+		if err := subscriber.SubscribeTopic(topic, countMonitor.HandleMessage); err != nil {
+			slog.Error("Failed to subscribe to count topic", "error", err, "topic", topic)
+		}
+	}
+
+	slog.Info("Frigate Alerter service started successfully")
+	slog.Info("Listening for events", "mqtt_server", cfg.MQTTServer)
 	
 	// Wait for termination signal
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
-	
-	slog.Info("Shutting down Frigate Alerter service")
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
 	
-	// Shut down the HTTP server
-	if err := httpServer.Stop(); err != nil {
+	slog.Info("Shutting down Frigate Alerter service", "timeout", cfg.ShutdownDeadline.String())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownDeadline)
+	defer cancel()
+
+	// Stop accepting MQTT messages first so nothing new enters the queue
+	if err := subscriber.Close(); err != nil {
+		slog.Error("Error closing MQTT subscriber", "error", err)
+	}
+
+	// Stop escalating, checking for loitering and anomalies and sending reports before the notifiers go away
+	escalationService.Stop()
+	loiteringDetector.Stop()
+	anomalyDetector.Stop()
+	reportService.Stop()
+	healthMonitor.Stop()
+
+	// Let queued events and count alerts finish processing, including their notifications
+	if err := eventQueue.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Error draining event queue", "error", err)
+	}
+	if err := countMonitor.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Error waiting for count alerts", "error", err)
+	}
+
+	// Alerts are held back for the digest until the last of them has been raised
+	digestService.Stop()
+
+	// Wait for in-flight HTTP requests such as manual snapshots
+	if err := httpServer.Stop(shutdownCtx); err != nil {
 		slog.Error("Error stopping HTTP server", "error", err)
 	}
+
+	if err := notifier.Close(); err != nil {
+		slog.Error("Error closing Discord notifier", "error", err)
+	}
+
+	// The database goes last because every other component may still write to it
+	if err := repository.Close(); err != nil {
+		slog.Error("Error closing SQLite repository", "error", err)
+	}
+
+	slog.Info("Frigate Alerter service stopped")
 }
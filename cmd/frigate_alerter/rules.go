@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/adapters"
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/logger"
+)
+
+// runTestRules implements the test-rules subcommand and returns the process exit code
+func runTestRules(args []string) int {
+	logger.Configure(logger.Config{
+		Level:  slog.LevelWarn,
+		Output: os.Stderr,
+	})
+
+	flags := flag.NewFlagSet("test-rules", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: frigate_alerter test-rules [flags]")
+		fmt.Fprintln(flags.Output(), "Traces what the current rules would do with a Frigate event or stored alerts, without saving or sending anything.")
+		fmt.Fprintln(flags.Output(), "Give exactly one of -event, -alert or a time range of -from and -to.")
+		flags.PrintDefaults()
+	}
+	eventFile := flags.String("event", "", "file holding a Frigate event as JSON, or - for stdin")
+	at := flags.String("at", "", "evaluate the event at this time instead of now (RFC 3339 or YYYY-MM-DD)")
+	alertID := flags.String("alert", "", "replay the stored alert with this ID")
+	from := flags.String("from", "", "replay the stored alerts at or after this time (RFC 3339 or YYYY-MM-DD)")
+	to := flags.String("to", "", "replay the stored alerts before this time (RFC 3339 or YYYY-MM-DD)")
+	limit := flags.Int("limit", 0, "replay at most this many of the latest alerts of the time range (default 100)")
+	asJSON := flags.Bool("json", false, "print the traces as JSON")
+	database := flags.String("db", "./data/alerts.db", "path of the alerts database")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to load configuration:", err)
+		return 1
+	}
+
+	test := application.RuleTest{AlertID: *alertID, Limit: *limit}
+	for _, field := range []struct {
+		name  string
+		value string
+		t     *time.Time
+	}{{"at", *at, &test.At}, {"from", *from, &test.From}, {"to", *to, &test.To}} {
+		if field.value == "" {
+			continue
+		}
+		if *field.t, err = domain.ParseFilterTime(field.value, cfg.Location); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -%s: %v\n", field.name, err)
+			return 2
+		}
+	}
+	if *eventFile != "" {
+		if test.Event, err = readEvent(*eventFile); err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid -event:", err)
+			return 2
+		}
+	}
+
+	if _, err := os.Stat(*database); err != nil {
+		fmt.Fprintln(os.Stderr, "Alerts database not found:", err)
+		return 1
+	}
+	repository, err := adapters.NewSQLiteAlertRepository(*database, cfg.Location)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to open alerts database:", err)
+		return 1
+	}
+	defer repository.Close()
+
+	// A dry run sends nothing, so no notifier is connected. Without MQTT the presence and alarm panel states
+	// are unknown, and modes come from a manual mode, the schedule or the default mode only.
+	modeService := application.NewModeService(repository, application.NewPresenceService(cfg), cfg)
+	alertService := application.NewAlertService(application.AlertServiceDeps{
+		Repository: repository,
+		Snoozes:    application.NewSnoozeService(repository, cfg),
+		Modes:      modeService,
+		Incidents:  application.NewIncidentService(repository, repository, nil, cfg),
+		Loitering:  application.NewLoiteringDetector(cfg),
+		Sequences:  application.NewZoneSequenceDetector(cfg),
+		Watchlist:  application.NewWatchlistService(repository, cfg),
+		Digests:    application.NewDigestService(repository, nil, nil, cfg),
+	}, cfg)
+
+	traces, err := alertService.TestRules(test)
+	if errors.Is(err, domain.ErrInvalidRuleTest) {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(traces); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	for i, trace := range traces {
+		if i > 0 {
+			fmt.Println()
+		}
+		printTrace(os.Stdout, trace, cfg.Location)
+	}
+	return 0
+}
+
+// readEvent reads a Frigate event from a file, or from stdin for "-"
+func readEvent(name string) (*domain.FrigateEvent, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	var event domain.FrigateEvent
+	if err := json.NewDecoder(r).Decode(&event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// printTrace writes a decision trace as one line per check, followed by the alert and its notifications
+func printTrace(w io.Writer, trace *domain.DecisionTrace, location *time.Location) {
+	switch {
+	case trace.AlertID != "":
+		fmt.Fprintf(w, "Alert %s\n", trace.AlertID)
+	case trace.Event != nil:
+		object := trace.Event.Object()
+		fmt.Fprintf(w, "Event %s %s on %s (%s)\n", trace.Event.Type, object.ID, object.Camera, object.Label)
+	}
+	for _, step := range trace.Steps {
+		fmt.Fprintf(w, "  %-12s %-7s %s\n", step.Check, step.Outcome, step.Detail)
+	}
+
+	alert := trace.Alert
+	if alert == nil {
+		fmt.Fprintln(w, "  No alert")
+		return
+	}
+	fmt.Fprintf(w, "  %s alert at %s: %s\n", alert.Severity, alert.TriggeredAt.In(location).Format(time.RFC3339), alert.AlertMessage)
+	if alert.SuppressedBy != "" {
+		fmt.Fprintf(w, "  Muted by %s\n", alert.SuppressedBy)
+	}
+	for _, notification := range trace.Notifications {
+		if notification.After != "" {
+			fmt.Fprintf(w, "  Notifies %s after %s: %s\n", notification.Notifier, notification.After, notification.Detail)
+			continue
+		}
+		fmt.Fprintf(w, "  Notifies %s: %s\n", notification.Notifier, notification.Detail)
+	}
+}
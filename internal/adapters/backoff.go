@@ -0,0 +1,42 @@
+package adapters
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	// initialBackoff is the delay before the first reconnection attempt
+	initialBackoff = time.Second
+	// maxBackoff caps the delay between reconnection attempts
+	maxBackoff = 2 * time.Minute
+)
+
+// retryWithBackoff calls connect until it succeeds or the context is cancelled,
+// doubling the delay between attempts up to maxBackoff
+func retryWithBackoff(ctx context.Context, dependency string, connect func() error) error {
+	delay := initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := connect()
+		if err == nil {
+			if attempt > 1 {
+				slog.Info("Connected after retrying", "dependency", dependency, "attempts", attempt)
+			}
+			return nil
+		}
+
+		slog.Warn("Connection attempt failed, retrying", "dependency", dependency, "attempt", attempt, "retry_in", delay.String(), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > maxBackoff {
+			delay = maxBackoff
+		}
+	}
+}
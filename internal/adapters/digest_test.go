@@ -0,0 +1,79 @@
+package adapters
+
+import (
+	"bytes"
+	"image/jpeg"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestQuietHoursDigest(t *testing.T) {
+	notifier := &stubNotifier{}
+	backend := newTestBackend(t, notifier)
+	// A window from midnight to midnight every day is always quiet
+	backend.config.QuietHours = []config.TimeWindow{{Weekdays: []time.Weekday{0, 1, 2, 3, 4, 5, 6}}}
+	backend.config.QuietHoursBypassCritical = true
+	backend.config.SeverityRules = []config.SeverityRule{{Severity: domain.SeverityCritical, Labels: []string{"dog"}}}
+	backend.config.DefaultSeverity = domain.SeverityWarning
+
+	digestService := application.NewDigestService(backend.repository, notifier, backend.frigate, backend.config)
+	alertService := backend.alertService(application.AlertServiceDeps{Digests: digestService})
+	for i, event := range []domain.FrigateBefore{
+		{ID: "1700000000.0-abc", Camera: "front_door", Label: "person"},
+		{ID: "2", Camera: "driveway", Label: "car"},
+		{ID: "3", Camera: "driveway", Label: "car"},
+		{ID: "4", Camera: "front_door", Label: "dog"},
+	} {
+		if err := alertService.ProcessEvent(&domain.FrigateEvent{Type: "new", Before: event}); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+	}
+
+	// Only the critical alert bypasses quiet hours; the digest waits for them to end
+	if len(notifier.sent) != 1 || notifier.sent[0].Label != "dog" {
+		t.Fatalf("sent %d notifications, want only the critical dog alert", len(notifier.sent))
+	}
+	if err := digestService.Check(time.Now()); err != nil || len(notifier.digests) != 0 {
+		t.Fatalf("sent %d digests during quiet hours (err %v), want none", len(notifier.digests), err)
+	}
+	backend.config.QuietHours = nil
+	if err := digestService.Check(time.Now()); err != nil {
+		t.Fatalf("failed to send digest: %v", err)
+	}
+	if err := digestService.Check(time.Now()); err != nil || len(notifier.digests) != 1 {
+		t.Fatalf("sent %d digests (err %v), want exactly one", len(notifier.digests), err)
+	}
+
+	digest := notifier.digests[0]
+	want := []domain.DigestCount{{Camera: "driveway", Label: "car", Count: 2}, {Camera: "front_door", Label: "person", Count: 1}}
+	if digest.Total != 3 || !slices.Equal(digest.Counts, want) {
+		t.Errorf("digest counts %d alerts as %+v, want 3 as %+v", digest.Total, digest.Counts, want)
+	}
+	// Frigate only has the snapshot of the person
+	if len(digest.Highlights) != 1 || digest.Highlights[0].EventID != "1700000000.0-abc" {
+		t.Fatalf("digest has %d highlights, want the person", len(digest.Highlights))
+	}
+	collage, err := jpeg.DecodeConfig(bytes.NewReader(digest.Collage))
+	if err != nil || collage.Width == 0 || collage.Height == 0 {
+		t.Errorf("collage is not a JPEG image: %v", err)
+	}
+
+	alerts, err := backend.repository.FindAlerts(domain.AlertFilter{})
+	if err != nil {
+		t.Fatalf("failed to get alerts: %v", err)
+	}
+	held := 0
+	for _, alert := range alerts {
+		if alert.SuppressedBy == domain.SuppressedByQuietHours {
+			held++
+		}
+	}
+	if held != 3 {
+		t.Errorf("stored %d alerts held back by quiet hours, want 3", held)
+	}
+}
@@ -0,0 +1,78 @@
+package adapters
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// maxEmbedFields is the number of fields Discord allows in one embed
+const maxEmbedFields = 25
+
+// SendDigest posts the summary of the alerts held back during quiet hours, with the collage as its image
+func (d *DiscordNotifier) SendDigest(digest *domain.Digest) error {
+	slog.Info("Sending quiet hours digest to Discord", "alerts", digest.Total)
+
+	embed := digestEmbed(digest)
+	message := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+	if digest.Collage != nil {
+		embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://digest.jpg"}
+		message.Files = []*discordgo.File{{
+			Name:        "digest.jpg",
+			ContentType: "image/jpeg",
+			Reader:      bytes.NewReader(digest.Collage),
+		}}
+	}
+
+	if _, err := d.session.ChannelMessageSendComplex(d.channelID, message); err != nil {
+		slog.Error("Failed to send Discord message", "error", err, "channel_id", d.channelID)
+		return err
+	}
+	return nil
+}
+
+// digestEmbed lists the alert counts of a digest per camera, followed by its highlights
+func digestEmbed(digest *domain.Digest) *discordgo.MessageEmbed {
+	var cameras []string
+	lines := make(map[string][]string)
+	for _, count := range digest.Counts {
+		if _, ok := lines[count.Camera]; !ok {
+			cameras = append(cameras, count.Camera)
+		}
+		label := count.Label
+		if label == "" {
+			label = "object"
+		}
+		lines[count.Camera] = append(lines[count.Camera], fmt.Sprintf("%s: %d", label, count.Count))
+	}
+
+	var fields []*discordgo.MessageEmbedField
+	for i, camera := range cameras {
+		// One field is kept for the highlights, and one for the cameras that do not fit
+		if i == maxEmbedFields-2 {
+			fields = append(fields, &discordgo.MessageEmbedField{Name: "More cameras", Value: fmt.Sprintf("%d more", len(cameras)-i)})
+			break
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{Name: camera, Value: strings.Join(lines[camera], "\n"), Inline: true})
+	}
+	if len(digest.Highlights) > 0 {
+		var highlights []string
+		for _, alert := range digest.Highlights {
+			highlights = append(highlights, fmt.Sprintf("%s %s", alert.TriggeredAt.Format("15:04"), alert.AlertMessage))
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Highlights", Value: strings.Join(highlights, "\n")})
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: "Quiet hours digest",
+		Description: fmt.Sprintf("%d alerts from %s to %s", digest.Total,
+			digest.From.Format("2006-01-02 15:04"), digest.To.Format("2006-01-02 15:04")),
+		Color:     severityColor(domain.SeverityInfo),
+		Fields:    fields,
+		Timestamp: digest.To.Format("2006-01-02T15:04:05-0700"),
+	}
+}
@@ -0,0 +1,90 @@
+package adapters
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// SendIncident posts the message of a new incident and returns its ID, so it can be edited as the incident grows
+func (d *DiscordNotifier) SendIncident(incident *domain.Incident, alert *domain.Alert) (string, error) {
+	slog.Info("Sending incident to Discord", "incident_id", incident.ID, "camera", alert.CameraName, "alert_id", alert.ID)
+
+	message := d.message(incident.Severity, incidentEmbed(incident, alert))
+	if file := d.cameraImage(alert.CameraName); file != nil {
+		message.Files = []*discordgo.File{file}
+	}
+
+	sent, err := d.session.ChannelMessageSendComplex(d.channelID, message)
+	if err != nil {
+		slog.Error("Failed to send Discord message", "error", err, "channel_id", d.channelID)
+		return "", err
+	}
+	return sent.ID, nil
+}
+
+// UpdateIncident edits the message of an incident to show its path so far and replaces the image with the latest camera
+func (d *DiscordNotifier) UpdateIncident(incident *domain.Incident, alert *domain.Alert, messageID string) error {
+	slog.Info("Updating incident on Discord", "incident_id", incident.ID, "camera", alert.CameraName, "alert_id", alert.ID)
+
+	embeds := []*discordgo.MessageEmbed{incidentEmbed(incident, alert)}
+	edit := discordgo.NewMessageEdit(d.channelID, messageID)
+	edit.Embeds = &embeds
+	if file := d.cameraImage(alert.CameraName); file != nil {
+		edit.Attachments = &[]*discordgo.MessageAttachment{}
+		edit.Files = []*discordgo.File{file}
+	}
+
+	if _, err := d.session.ChannelMessageEditComplex(edit); err != nil {
+		slog.Error("Failed to edit Discord message", "error", err, "channel_id", d.channelID, "message_id", messageID)
+		return err
+	}
+	return nil
+}
+
+// cameraImage fetches the latest image of a camera as an attachment, or returns nil when Frigate does not deliver one
+func (d *DiscordNotifier) cameraImage(camera string) *discordgo.File {
+	imageURL := fmt.Sprintf("%s/api/%s/latest.jpg?h=300&_t=%d", d.frigateURL, camera, time.Now().Unix())
+	imageData, err := d.fetchImage(imageURL)
+	if err != nil {
+		slog.Error("Failed to fetch image from Frigate", "error", err, "url", imageURL)
+		return nil
+	}
+	return &discordgo.File{
+		Name:   fmt.Sprintf("%s_alert_%s.jpg", camera, time.Now().Format("20060102_150405")),
+		Reader: bytes.NewReader(imageData),
+	}
+}
+
+// incidentEmbed describes an incident with its latest alert
+func incidentEmbed(incident *domain.Incident, alert *domain.Alert) *discordgo.MessageEmbed {
+	title := fmt.Sprintf("Alert from %s camera", alert.CameraName)
+	if incident.AlertCount > 1 {
+		title = fmt.Sprintf("Incident: %s", strings.Join(incident.Cameras, " → "))
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Camera", Value: alert.CameraName, Inline: true},
+		{Name: "Alerts", Value: strconv.Itoa(incident.AlertCount), Inline: true},
+		{Name: "Started", Value: incident.StartedAt.Format("2006-01-02 15:04:05"), Inline: true},
+		{Name: "Last Seen", Value: alert.TriggeredAt.Format("2006-01-02 15:04:05"), Inline: true},
+		{Name: "Incident ID", Value: incident.ID, Inline: true},
+	}
+	if incident.Severity != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Severity", Value: incident.Severity, Inline: true})
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       title,
+		Description: alert.AlertMessage,
+		Color:       severityColor(incident.Severity),
+		Fields:      fields,
+		Timestamp:   alert.TriggeredAt.Format("2006-01-02T15:04:05-0700"),
+	}
+}
@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
@@ -17,10 +18,22 @@ type DiscordNotifier struct {
 	session     *discordgo.Session
 	channelID   string
 	frigateURL  string
+	mention     string
+	cancel      context.CancelFunc
+	opened      chan struct{}
 }
 
-// NewDiscordNotifier creates a new Discord notifier
-func NewDiscordNotifier(token string, channelID string, frigateServer string, frigatePort string) (*DiscordNotifier, error) {
+// severityColors maps severities to embed colours; alerts without a severity are red like critical ones
+var severityColors = map[string]int{
+	domain.SeverityInfo:     0x3498db, // Blue
+	domain.SeverityWarning:  0xf39c12, // Orange
+	domain.SeverityCritical: 0xff0000, // Red
+}
+
+// NewDiscordNotifier creates a new Discord notifier; notifications of critical alerts start with criticalMention.
+// The gateway connection is opened in the background and retried with backoff,
+// so the notifier starts in the connecting state when Discord is unreachable.
+func NewDiscordNotifier(token string, channelID string, frigateServer string, frigatePort string, criticalMention string) (*DiscordNotifier, error) {
 	slog.Info("Initializing Discord notifier")
 	
 	session, err := discordgo.New("Bot " + token)
@@ -29,22 +42,31 @@ func NewDiscordNotifier(token string, channelID string, frigateServer string, fr
 		return nil, err
 	}
 
-	// Open a websocket connection to Discord
-	slog.Debug("Opening websocket connection to Discord")
-	err = session.Open()
-	if err != nil {
-		slog.Error("Failed to open Discord connection", "error", err)
-		return nil, err
-	}
-
 	frigateURL := fmt.Sprintf("http://%s:%s", frigateServer, frigatePort)
-	slog.Info("Discord notifier initialized", "channel_id", channelID, "frigate_url", frigateURL)
-
-	return &DiscordNotifier{
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &DiscordNotifier{
 		session:     session,
 		channelID:   channelID,
 		frigateURL:  frigateURL,
-	}, nil
+		mention:     criticalMention,
+		cancel:      cancel,
+		opened:      make(chan struct{}),
+	}
+
+	// Open a websocket connection to Discord
+	go func() {
+		err := retryWithBackoff(ctx, "discord", func() error {
+			slog.Debug("Opening websocket connection to Discord")
+			return session.Open()
+		})
+		if err == nil {
+			slog.Info("Discord gateway connection opened")
+			close(d.opened)
+		}
+	}()
+
+	slog.Info("Discord notifier initialized", "channel_id", channelID, "frigate_url", frigateURL)
+	return d, nil
 }
 
 // SendAlert sends an alert notification to Discord
@@ -80,12 +102,19 @@ func (d *DiscordNotifier) SendAlert(alert *domain.Alert) error {
 			Inline: true,
 		},
 	}
+	if alert.Severity != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Severity",
+			Value:  alert.Severity,
+			Inline: true,
+		})
+	}
 
 	// Create the message embed
 	embed := &discordgo.MessageEmbed{
 		Title:       fmt.Sprintf("Alert from %s camera", alert.CameraName),
 		Description: alert.AlertMessage,
-		Color:       0xff0000, // Red color for alerts
+		Color:       severityColor(alert.Severity),
 		Fields:      fields,
 		Timestamp:   alert.TriggeredAt.Format("2006-01-02T15:04:05-0700"),
 	}
@@ -93,27 +122,16 @@ func (d *DiscordNotifier) SendAlert(alert *domain.Alert) error {
 	// Prepare file name for the image
 	imageFileName := fmt.Sprintf("%s_alert_%s.jpg", alert.CameraName, time.Now().Format("20060102_150405"))
 
-	var sendErr error
-
+	messageData := d.message(alert.Severity, embed)
 	if imageData != nil {
-		// Send the message with embed and file attachment
-		file := &discordgo.File{
+		// Attach the image; if fetching it failed, just the embed is sent
+		messageData.Files = []*discordgo.File{{
 			Name:   imageFileName,
 			Reader: bytes.NewReader(imageData),
-		}
-
-		messageData := &discordgo.MessageSend{
-			Embed: embed,
-			Files: []*discordgo.File{file},
-		}
-
-		_, sendErr = d.session.ChannelMessageSendComplex(d.channelID, messageData)
-	} else {
-		// If image fetch failed, just send the embed
-		_, sendErr = d.session.ChannelMessageSendEmbed(d.channelID, embed)
+		}}
 	}
 
-	if sendErr != nil {
+	if _, sendErr := d.session.ChannelMessageSendComplex(d.channelID, messageData); sendErr != nil {
 		slog.Error("Failed to send Discord message", "error", sendErr, "channel_id", d.channelID)
 		return sendErr
 	}
@@ -122,6 +140,30 @@ func (d *DiscordNotifier) SendAlert(alert *domain.Alert) error {
 	return nil
 }
 
+// message creates a message carrying an embed, mentioning the configured people when the severity is critical
+func (d *DiscordNotifier) message(severity string, embed *discordgo.MessageEmbed) *discordgo.MessageSend {
+	message := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+	if severity == domain.SeverityCritical && d.mention != "" {
+		message.Content = d.mention
+		message.AllowedMentions = &discordgo.MessageAllowedMentions{
+			Parse: []discordgo.AllowedMentionType{
+				discordgo.AllowedMentionTypeEveryone,
+				discordgo.AllowedMentionTypeRoles,
+				discordgo.AllowedMentionTypeUsers,
+			},
+		}
+	}
+	return message
+}
+
+// severityColor returns the embed colour of a severity
+func severityColor(severity string) int {
+	if color, ok := severityColors[severity]; ok {
+		return color
+	}
+	return severityColors[domain.SeverityCritical]
+}
+
 // fetchImage downloads the image from the provided URL and returns it as a byte slice
 func (d *DiscordNotifier) fetchImage(imageURL string) ([]byte, error) {
 	// Create HTTP client with timeout
@@ -151,8 +193,36 @@ func (d *DiscordNotifier) fetchImage(imageURL string) ([]byte, error) {
 	return imageData, nil
 }
 
-// Close closes the Discord session
+// CheckHealth reports whether the Discord gateway session is ready
+func (d *DiscordNotifier) CheckHealth(ctx context.Context) domain.DependencyStatus {
+	status := domain.DependencyStatus{
+		Name:      "discord",
+		State:     domain.StateDisconnected,
+		CheckedAt: time.Now(),
+	}
+
+	select {
+	case <-d.opened:
+	default:
+		status.State = domain.StateConnecting
+		return status
+	}
+
+	d.session.RLock()
+	ready := d.session.DataReady
+	d.session.RUnlock()
+
+	if ready {
+		status.Healthy = true
+		status.State = domain.StateConnected
+		status.Detail = fmt.Sprintf("heartbeat latency %s", d.session.HeartbeatLatency())
+	}
+	return status
+}
+
+// Close stops any pending connection attempts and closes the Discord session
 func (d *DiscordNotifier) Close() error {
 	slog.Info("Closing Discord session")
+	d.cancel()
 	return d.session.Close()
 }
@@ -0,0 +1,35 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestDiscordCriticalMention(t *testing.T) {
+	tests := []struct {
+		name     string
+		mention  string
+		severity string
+		want     string
+	}{
+		{"critical", "@here", domain.SeverityCritical, "@here"},
+		{"warning", "@here", domain.SeverityWarning, ""},
+		{"info", "@here", domain.SeverityInfo, ""},
+		{"mention disabled", "", domain.SeverityCritical, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			notifier := &DiscordNotifier{mention: test.mention}
+			message := notifier.message(test.severity, &discordgo.MessageEmbed{})
+			if message.Content != test.want {
+				t.Errorf("content = %q, want %q", message.Content, test.want)
+			}
+			if mentions := message.AllowedMentions != nil; mentions != (test.want != "") {
+				t.Errorf("allowed mentions set = %v, want %v", mentions, test.want != "")
+			}
+		})
+	}
+}
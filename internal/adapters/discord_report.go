@@ -0,0 +1,66 @@
+package adapters
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// maxEmbedFieldLength is the number of characters Discord allows in the value of an embed field
+const maxEmbedFieldLength = 1024
+
+// SendReport posts a daily or weekly report to Discord
+func (d *DiscordNotifier) SendReport(report *domain.Report) error {
+	slog.Info("Sending report to Discord", "period", report.Period)
+
+	message := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{reportEmbed(report)}}
+	if _, err := d.session.ChannelMessageSendComplex(d.channelID, message); err != nil {
+		slog.Error("Failed to send Discord message", "error", err, "channel_id", d.channelID)
+		return err
+	}
+	return nil
+}
+
+// reportEmbed shows a report with one field per camera
+func reportEmbed(report *domain.Report) *discordgo.MessageEmbed {
+	var fields []*discordgo.MessageEmbedField
+	for i, camera := range report.Cameras {
+		// Two fields are kept for the busiest hours and the health, and one for the cameras that do not fit
+		if i == maxEmbedFields-3 {
+			fields = append(fields, &discordgo.MessageEmbedField{Name: "More cameras", Value: fmt.Sprintf("%d more", len(report.Cameras)-i)})
+			break
+		}
+		value := fmt.Sprintf("%d (%s)", camera.Total, camera.Change())
+		if labels := reportLabels(camera); labels != "" {
+			value += "\n" + labels
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{Name: camera.Camera, Value: value, Inline: true})
+	}
+	if hours := reportHours(report); hours != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Busiest Hours", Value: hours})
+	}
+
+	health := "No outages"
+	if len(report.Outages) > 0 {
+		outages := make([]string, 0, len(report.Outages))
+		for _, outage := range report.Outages {
+			outages = append(outages, reportOutage(outage))
+		}
+		health = strings.Join(outages, "\n")
+		if len(health) > maxEmbedFieldLength {
+			health = health[:maxEmbedFieldLength-1] + "…"
+		}
+	}
+	fields = append(fields, &discordgo.MessageEmbedField{Name: "Health", Value: health})
+
+	return &discordgo.MessageEmbed{
+		Title:       reportTitle(report),
+		Description: fmt.Sprintf("%d alerts (%s, %d the period before)", report.Total, report.Change(), report.Previous),
+		Color:       severityColor(domain.SeverityInfo),
+		Fields:      fields,
+		Timestamp:   report.To.Format("2006-01-02T15:04:05-0700"),
+	}
+}
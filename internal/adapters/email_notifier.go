@@ -0,0 +1,150 @@
+package adapters
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// emailTimeout bounds a whole SMTP conversation, so a stalled server cannot hold up escalations or shutdown
+const emailTimeout = 10 * time.Second
+
+// EmailNotifier implements the AlertNotifier interface by sending plain text emails over SMTP
+type EmailNotifier struct {
+	host    string
+	addr    string
+	timeout time.Duration
+	auth    smtp.Auth
+	from    string
+	to      []string
+}
+
+// NewEmailNotifier creates a new email notifier; without a username the server is used unauthenticated
+func NewEmailNotifier(host string, port string, username string, password string, from string, to []string) *EmailNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &EmailNotifier{
+		host:    host,
+		addr:    net.JoinHostPort(host, port),
+		timeout: emailTimeout,
+		auth:    auth,
+		from:    from,
+		to:      to,
+	}
+}
+
+// SendAlert emails an alert notification to every recipient
+func (e *EmailNotifier) SendAlert(alert *domain.Alert) error {
+	slog.Info("Sending alert by email", "camera", alert.CameraName, "alert_id", alert.ID, "recipients", len(e.to))
+
+	var message bytes.Buffer
+	e.writeHeaders(&message, fmt.Sprintf("Alert from %s camera", alert.CameraName), alert.Severity)
+	fmt.Fprintf(&message, "%s\r\n\r\n", alert.AlertMessage)
+	fmt.Fprintf(&message, "Camera: %s\r\n", alert.CameraName)
+	if alert.Label != "" {
+		fmt.Fprintf(&message, "Object: %s\r\n", alert.Label)
+	}
+	if alert.Severity != "" {
+		fmt.Fprintf(&message, "Severity: %s\r\n", alert.Severity)
+	}
+	fmt.Fprintf(&message, "Time: %s\r\n", alert.TriggeredAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&message, "Alert ID: %s\r\n", alert.ID)
+
+	if err := e.send(message.Bytes()); err != nil {
+		slog.Error("Failed to send email", "error", err, "server", e.addr)
+		return err
+	}
+
+	slog.Info("Successfully sent alert by email", "camera", alert.CameraName, "alert_id", alert.ID)
+	return nil
+}
+
+// SendReport emails a daily or weekly report to every recipient
+func (e *EmailNotifier) SendReport(report *domain.Report) error {
+	slog.Info("Sending report by email", "period", report.Period, "recipients", len(e.to))
+
+	var message bytes.Buffer
+	e.writeHeaders(&message, reportTitle(report), domain.SeverityInfo)
+	message.WriteString(strings.ReplaceAll(reportText(report), "\n", "\r\n"))
+
+	if err := e.send(message.Bytes()); err != nil {
+		slog.Error("Failed to send email", "error", err, "server", e.addr)
+		return err
+	}
+	return nil
+}
+
+// send delivers a message to every recipient like smtp.SendMail, but gives up after the timeout
+func (e *EmailNotifier) send(message []byte) error {
+	conn, err := net.DialTimeout("tcp", e.addr, e.timeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(e.timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+	client, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: e.host}); err != nil {
+			return err
+		}
+	}
+	if e.auth != nil {
+		if err := client.Auth(e.auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(e.from); err != nil {
+		return err
+	}
+	for _, recipient := range e.to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// writeHeaders writes the headers of a plain text email; the severity sets its priority
+func (e *EmailNotifier) writeHeaders(message *bytes.Buffer, subject string, severity string) {
+	fmt.Fprintf(message, "From: %s\r\n", e.from)
+	fmt.Fprintf(message, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	// Mail clients highlight high priority messages and may file low priority ones away
+	switch severity {
+	case domain.SeverityCritical:
+		message.WriteString("X-Priority: 1 (Highest)\r\nImportance: high\r\n")
+	case domain.SeverityInfo:
+		message.WriteString("X-Priority: 5 (Lowest)\r\nImportance: low\r\n")
+	}
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+}
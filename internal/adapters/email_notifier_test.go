@@ -0,0 +1,44 @@
+package adapters
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestEmailNotifierStalledServer(t *testing.T) {
+	// The server accepts connections but never sends its greeting
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	notifier := NewEmailNotifier(host, port, "", "", "alerter@example.com", []string{"owner@example.com"})
+	notifier.timeout = 100 * time.Millisecond
+
+	done := make(chan error, 1)
+	go func() {
+		done <- notifier.SendAlert(&domain.Alert{ID: "1", CameraName: "driveway", AlertMessage: "Person detected"})
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("SendAlert() to a stalled server succeeded")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SendAlert() to a stalled server did not time out")
+	}
+}
@@ -1,20 +1,25 @@
 package adapters
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"sync/atomic"
 	"time"
 
 	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
 )
 
 // FrigateService provides methods for interacting with the Frigate API
 type FrigateService struct {
-	config *config.Config
-	client *http.Client
+	config  *config.Config
+	client  *http.Client
+	reached atomic.Bool
 }
 
 // NewFrigateService creates a new Frigate service
@@ -57,6 +62,7 @@ func (s *FrigateService) GetCameras() ([]string, error) {
 	if err := json.Unmarshal(body, &config); err != nil {
 		return nil, fmt.Errorf("Failed to unmarshal config: %w", err)
 	}
+	s.reached.Store(true)
 	
 	cameras := make([]string, 0, len(config.Cameras))
 	for camera := range config.Cameras {
@@ -87,6 +93,88 @@ func (s *FrigateService) GetSnapshot(camera string) ([]byte, error) {
 	return io.ReadAll(resp.Body)
 }
 
+// GetEventSnapshot opens the snapshot Frigate archived for an event
+func (s *FrigateService) GetEventSnapshot(ctx context.Context, eventID string) (io.ReadCloser, error) {
+	snapshotURL := fmt.Sprintf("%s/api/events/%s/snapshot.jpg", s.getBaseURL(), url.PathEscape(eventID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, snapshotURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get event snapshot: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("Unexpected status code: %d", resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+// ReportFalsePositive marks an event as a false positive in Frigate
+func (s *FrigateService) ReportFalsePositive(ctx context.Context, eventID string) error {
+	falsePositiveURL := fmt.Sprintf("%s/api/events/%s/false_positive", s.getBaseURL(), url.PathEscape(eventID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, falsePositiveURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed to report false positive: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("Unexpected status code: %d: %s", resp.StatusCode, message)
+	}
+	return nil
+}
+
+// CheckHealth reports whether the Frigate API is reachable
+func (s *FrigateService) CheckHealth(ctx context.Context) domain.DependencyStatus {
+	status := domain.DependencyStatus{
+		Name:      "frigate",
+		State:     domain.StateUnreachable,
+		CheckedAt: time.Now(),
+	}
+	if !s.reached.Load() {
+		// Frigate has not answered since startup, so it is most likely still booting
+		status.State = domain.StateConnecting
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.getBaseURL()+"/api/version", nil)
+	if err != nil {
+		status.Detail = err.Error()
+		return status
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		status.Detail = err.Error()
+		return status
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		status.Detail = fmt.Sprintf("unexpected status code: %d", resp.StatusCode)
+		return status
+	}
+
+	version, _ := io.ReadAll(io.LimitReader(resp.Body, 64))
+	s.reached.Store(true)
+	status.Healthy = true
+	status.State = domain.StateConnected
+	status.Detail = fmt.Sprintf("version %s", version)
+	return status
+}
+
 // getBaseURL returns the base URL for the Frigate API
 func (s *FrigateService) getBaseURL() string {
 	return fmt.Sprintf("http://%s:%s", s.config.FrigateServer, s.config.FrigatePort)
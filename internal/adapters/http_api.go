@@ -0,0 +1,415 @@
+package adapters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vibin/frigate_alerter/api"
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// apiVersionPrefix is the path prefix of the versioned REST API
+const apiVersionPrefix = "/api/v1"
+
+// apiRoute describes a single operation of the REST API
+type apiRoute struct {
+	Method  string
+	Path    string
+	Handler http.HandlerFunc
+}
+
+// APIError is the error envelope returned by every API endpoint
+type APIError struct {
+	Error APIErrorBody `json:"error"`
+}
+
+// APIErrorBody describes what went wrong
+type APIErrorBody struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// CameraInfo represents information about a camera
+type CameraInfo struct {
+	Name        string     `json:"name"`
+	Count       int        `json:"alert_count"`
+	LastAlertAt *time.Time `json:"last_alert_at,omitempty"`
+}
+
+// AlertResponse represents the result of an on-demand alert
+type AlertResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	AlertID string `json:"alert_id,omitempty"`
+}
+
+// apiRoutes returns every operation of the versioned API, with paths relative to the version prefix.
+// The OpenAPI document in api/openapi.json must describe exactly these routes.
+func (s *HTTPServer) apiRoutes() []apiRoute {
+	return []apiRoute{
+		{http.MethodGet, "/openapi.json", s.handleAPIGetOpenAPI},
+		{http.MethodGet, "/cameras", s.handleAPIGetCameras},
+		{http.MethodGet, "/cameras/{name}/snooze", s.handleAPIGetSnoozes},
+		{http.MethodPost, "/cameras/{name}/snooze", s.handleAPISnoozeCamera},
+		{http.MethodDelete, "/cameras/{name}/snooze", s.handleAPIUnsnoozeCamera},
+		{http.MethodGet, "/watchlist", s.handleAPIGetWatchlist},
+		{http.MethodPut, "/watchlist/{kind}/{value}", s.handleAPISetWatchlistEntry},
+		{http.MethodDelete, "/watchlist/{kind}/{value}", s.handleAPIRemoveWatchlistEntry},
+		{http.MethodGet, "/alerts", s.handleAPIGetAlerts},
+		{http.MethodGet, "/alerts/export", s.handleAPIExportAlerts},
+		{http.MethodGet, "/alerts/{id}", s.handleAPIGetAlert},
+		{http.MethodPost, "/alerts/{id}/acknowledge", s.handleAPIAcknowledgeAlert},
+		{http.MethodPost, "/alerts/{id}/notes", s.handleAPIAddAlertNote},
+		{http.MethodPost, "/alerts/{id}/false-positive", s.handleAPISetFalsePositive},
+		{http.MethodGet, "/incidents", s.handleAPIGetIncidents},
+		{http.MethodGet, "/incidents/{id}", s.handleAPIGetIncident},
+		{http.MethodGet, "/mode", s.handleAPIGetMode},
+		{http.MethodPut, "/mode", s.handleAPISetMode},
+		{http.MethodDelete, "/mode", s.handleAPIClearMode},
+		{http.MethodPost, "/trigger", s.handleAPITriggerSnapshot},
+		{http.MethodPost, "/rules/test", s.handleAPITestRules},
+		{http.MethodGet, "/stats", s.handleAPIGetStats},
+	}
+}
+
+// registerAPIRoutes registers the API under /api/v1 and, for existing clients, under the unversioned /api prefix
+func (s *HTTPServer) registerAPIRoutes(router *http.ServeMux) {
+	byPath := make(map[string]map[string]http.HandlerFunc)
+	var paths []string
+	for _, route := range s.apiRoutes() {
+		if byPath[route.Path] == nil {
+			byPath[route.Path] = make(map[string]http.HandlerFunc)
+			paths = append(paths, route.Path)
+		}
+		byPath[route.Path][route.Method] = route.Handler
+	}
+
+	for _, path := range paths {
+		handler := methodRouter(byPath[path])
+		router.Handle(apiVersionPrefix+path, handler)
+		router.Handle("/api"+path, handler)
+	}
+
+	// Anything else under /api is answered with a JSON 404 instead of the HTML home page
+	router.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, "not_found", fmt.Sprintf("no API endpoint at %s", r.URL.Path), nil)
+	})
+}
+
+// methodRouter dispatches a request to the handler registered for its method and answers
+// other methods with 405 and an Allow header
+func methodRouter(handlers map[string]http.HandlerFunc) http.Handler {
+	allowed := make([]string, 0, len(handlers))
+	for method := range handlers {
+		allowed = append(allowed, method)
+	}
+	sort.Strings(allowed)
+	allow := strings.Join(allowed, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := handlers[r.Method]
+		if !ok && r.Method == http.MethodHead {
+			handler, ok = handlers[http.MethodGet]
+		}
+		if !ok {
+			w.Header().Set("Allow", allow)
+			writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed",
+				fmt.Sprintf("method %s is not allowed, use %s", r.Method, allow), nil)
+			return
+		}
+		handler(w, r)
+	})
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+// writeAPIError writes an error envelope with the given status code
+func writeAPIError(w http.ResponseWriter, status int, code string, message string, details map[string]string) {
+	writeJSON(w, status, APIError{Error: APIErrorBody{Code: code, Message: message, Details: details}})
+}
+
+// errInvalidParameter is returned when a query parameter cannot be parsed
+var errInvalidParameter = errors.New("invalid parameter")
+
+// queryInt parses an optional non-negative integer query parameter
+func queryInt(r *http.Request, name string, defaultValue int, min int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < min {
+		return 0, fmt.Errorf("%w: %s must be an integer >= %d", errInvalidParameter, name, min)
+	}
+	return parsed, nil
+}
+
+// queryTime parses an optional time query parameter given as RFC 3339 or as a date in the configured time zone
+func (s *HTTPServer) queryTime(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := domain.ParseFilterTime(value, s.config.Location); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: %s must be an RFC 3339 timestamp or a YYYY-MM-DD date", errInvalidParameter, name)
+}
+
+// querySeverity parses the optional severity query parameter
+func querySeverity(r *http.Request) (string, error) {
+	severity := r.URL.Query().Get("severity")
+	if severity != "" && !domain.IsSeverity(severity) {
+		return "", fmt.Errorf("%w: severity must be info, warning or critical", errInvalidParameter)
+	}
+	return severity, nil
+}
+
+// queryAlertFilter parses the camera, label, type, severity and time range query parameters shared by alert endpoints
+func (s *HTTPServer) queryAlertFilter(r *http.Request) (domain.AlertFilter, error) {
+	query := r.URL.Query()
+	filter := domain.AlertFilter{
+		Camera: query.Get("camera"),
+		Label:  query.Get("label"),
+		Type:   query.Get("type"),
+	}
+
+	var err error
+	if filter.Severity, err = querySeverity(r); err != nil {
+		return filter, err
+	}
+	if filter.From, err = s.queryTime(r, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = s.queryTime(r, "to"); err != nil {
+		return filter, err
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("%w: from must be before to", errInvalidParameter)
+	}
+	return filter, nil
+}
+
+// handleAPIGetOpenAPI serves the OpenAPI document describing this API
+func (s *HTTPServer) handleAPIGetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(api.OpenAPISpec)
+}
+
+// handleAPIGetCameras returns a list of cameras as JSON
+func (s *HTTPServer) handleAPIGetCameras(w http.ResponseWriter, r *http.Request) {
+	cameras, err := s.frigateService.GetCameras()
+	if err != nil {
+		slog.Error("Failed to get cameras", "error", err)
+		writeAPIError(w, http.StatusBadGateway, "frigate_unavailable", "Failed to get cameras from Frigate", nil)
+		return
+	}
+
+	// Get alert counts for all cameras in a single aggregation query
+	stats, err := s.repository.GetCameraStats(domain.AlertFilter{})
+	if err != nil {
+		slog.Error("Failed to get alert statistics", "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to get alert counts", nil)
+		return
+	}
+	byCamera := make(map[string]domain.CameraStats, len(stats))
+	for _, cameraStats := range stats {
+		byCamera[cameraStats.Camera] = cameraStats
+	}
+
+	cameraInfos := make([]CameraInfo, 0, len(cameras))
+	for _, camera := range cameras {
+		cameraStats := byCamera[camera]
+		cameraInfos = append(cameraInfos, CameraInfo{
+			Name:        camera,
+			Count:       cameraStats.Total,
+			LastAlertAt: cameraStats.LastAlertAt,
+		})
+	}
+	sort.Slice(cameraInfos, func(i, j int) bool {
+		return cameraInfos[i].Name < cameraInfos[j].Name
+	})
+
+	writeJSON(w, http.StatusOK, cameraInfos)
+}
+
+// handleAPIGetAlerts returns alerts as JSON
+func (s *HTTPServer) handleAPIGetAlerts(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt(r, "limit", 100, 1)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error(), nil)
+		return
+	}
+	offset, err := queryInt(r, "offset", 0, 0)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error(), nil)
+		return
+	}
+	camera := r.URL.Query().Get("camera")
+	severity, err := querySeverity(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error(), nil)
+		return
+	}
+
+	var alerts []*domain.Alert
+	if severity != "" {
+		// Get alerts of a severity, optionally for a specific camera
+		alerts, err = s.repository.FindAlerts(domain.AlertFilter{Camera: camera, Severity: severity, Limit: limit, Offset: offset})
+	} else if camera != "" {
+		// Get alerts for a specific camera
+		alerts, err = s.repository.GetAlertsByCameraName(camera, limit, offset)
+	} else {
+		// Get all alerts
+		alerts, err = s.repository.GetAlerts(limit, offset)
+	}
+
+	if err != nil {
+		slog.Error("Failed to get alerts", "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to get alerts", nil)
+		return
+	}
+
+	if alerts == nil {
+		alerts = []*domain.Alert{}
+	}
+	writeJSON(w, http.StatusOK, alerts)
+}
+
+// exportContentTypes maps export formats to the content type of the response
+var exportContentTypes = map[application.ExportFormat]string{
+	application.ExportCSV:    "text/csv; charset=utf-8",
+	application.ExportNDJSON: "application/x-ndjson",
+	application.ExportZIP:    "application/zip",
+}
+
+// handleAPIExportAlerts streams the alerts matching the filters as a CSV, NDJSON or ZIP download
+func (s *HTTPServer) handleAPIExportAlerts(w http.ResponseWriter, r *http.Request) {
+	format := application.ExportCSV
+	if value := r.URL.Query().Get("format"); value != "" {
+		var err error
+		if format, err = application.ParseExportFormat(value); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error(), nil)
+			return
+		}
+	}
+	filter, err := s.queryAlertFilter(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error(), nil)
+		return
+	}
+	// Alerts raised while the export runs are left out so the file has a well-defined end
+	if filter.To.IsZero() {
+		filter.To = time.Now().In(s.config.Location)
+	}
+
+	// Large exports take longer than the server's write timeout allows for regular requests
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.Warn("Failed to extend write deadline for export", "error", err)
+	}
+
+	filename := fmt.Sprintf("alerts-%s.%s", filter.To.Format("20060102-150405"), format)
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	// Once streaming has started the status can no longer change, so a failure can only cut the download short
+	if err := s.exportService.Export(r.Context(), w, format, filter); err != nil {
+		slog.Error("Failed to export alerts", "error", err, "format", format)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// handleAPITriggerSnapshot handles requests to trigger a snapshot and send to Discord
+func (s *HTTPServer) handleAPITriggerSnapshot(w http.ResponseWriter, r *http.Request) {
+	// Parse JSON request
+	var requestBody struct {
+		Camera string `json:"camera"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		slog.Error("Failed to decode request body", "error", err)
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", "Request body must be a JSON object", nil)
+		return
+	}
+
+	if requestBody.Camera == "" {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", "Camera name is required", nil)
+		return
+	}
+
+	// Create manual alert
+	currentTime := time.Now().In(s.config.Location)
+	alertID := fmt.Sprintf("manual_%s_%d", requestBody.Camera, currentTime.UnixNano())
+
+	alert := &domain.Alert{
+		ID:           alertID,
+		Type:         domain.AlertTypeManual,
+		CameraName:   requestBody.Camera,
+		TriggeredAt:  currentTime,
+		AlertMessage: fmt.Sprintf("Manual snapshot from %s camera", requestBody.Camera),
+		Severity:     domain.SeverityInfo,
+	}
+
+	// Save alert to database
+	if err := s.repository.SaveAlert(alert); err != nil {
+		slog.Error("Failed to save manual alert", "error", err, "camera", requestBody.Camera)
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to save alert", nil)
+		return
+	}
+
+	// Send alert to Discord; the alert is kept even if the notification fails
+	if err := s.notifier.SendAlert(alert); err != nil {
+		slog.Error("Failed to send manual alert to Discord", "error", err, "camera", requestBody.Camera)
+		writeAPIError(w, http.StatusBadGateway, "notification_failed",
+			fmt.Sprintf("Alert saved but sending to Discord failed: %v", err),
+			map[string]string{"alert_id": alertID})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, AlertResponse{
+		Success: true,
+		Message: fmt.Sprintf("Manual snapshot from %s camera sent to Discord", requestBody.Camera),
+		AlertID: alertID,
+	})
+}
+
+// handleAPIGetStats returns aggregated alert counts; without a range it covers the last 7 days
+func (s *HTTPServer) handleAPIGetStats(w http.ResponseWriter, r *http.Request) {
+	filter, err := s.queryAlertFilter(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error(), nil)
+		return
+	}
+	if filter.To.IsZero() {
+		filter.To = time.Now().In(s.config.Location)
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, 0, -7)
+	}
+
+	stats, err := s.repository.GetAlertStats(filter)
+	if err != nil {
+		slog.Error("Failed to get alert statistics", "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to get alert statistics", nil)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
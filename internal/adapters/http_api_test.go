@@ -0,0 +1,175 @@
+package adapters
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// newTestServer creates an HTTP server with every service, for the tests that cover all routes
+func newTestServer(t *testing.T, notifier *stubNotifier) *HTTPServer {
+	t.Helper()
+
+	backend := newTestBackend(t, notifier)
+	snoozes := application.NewSnoozeService(backend.repository, backend.config)
+	modes := application.NewModeService(backend.repository, application.NewPresenceService(backend.config), backend.config)
+	incidents := application.NewIncidentService(backend.repository, backend.repository, notifier, backend.config)
+	watchlist := application.NewWatchlistService(backend.repository, backend.config)
+	return backend.server(t, HTTPServerDeps{
+		ExportService:    application.NewExportService(backend.repository, backend.frigate, backend.config),
+		ReviewService:    application.NewReviewService(backend.repository, backend.frigate, backend.config),
+		SnoozeService:    snoozes,
+		ModeService:      modes,
+		IncidentService:  incidents,
+		WatchlistService: watchlist,
+		ReportService:    application.NewReportService(backend.repository, backend.repository, map[string]ports.ReportNotifier{config.NotifierDiscord: notifier}, backend.config),
+		AlertService:     backend.alertService(application.AlertServiceDeps{Snoozes: snoozes, Modes: modes, Incidents: incidents, Watchlist: watchlist}),
+	})
+}
+
+func TestAPIRoutesMatchOpenAPISpec(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+	server := newTestServer(t, &stubNotifier{})
+
+	implemented := make(map[string]bool)
+	for _, route := range server.apiRoutes() {
+		implemented[strings.ToLower(route.Method)+" "+route.Path] = true
+	}
+
+	documented := make(map[string]bool)
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			documented[method+" "+path] = true
+		}
+	}
+
+	for operation := range implemented {
+		if !documented[operation] {
+			t.Errorf("route %q is implemented but missing from api/openapi.json", operation)
+		}
+	}
+	for operation := range documented {
+		if !implemented[operation] {
+			t.Errorf("operation %q is documented in api/openapi.json but not implemented", operation)
+		}
+	}
+}
+
+func TestAPIResponsesAreDocumented(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		notifyErr  error
+		wantStatus int
+	}{
+		{"openapi", http.MethodGet, "/openapi.json", "", nil, http.StatusOK},
+		{"list cameras", http.MethodGet, "/cameras", "", nil, http.StatusOK},
+		{"list alerts", http.MethodGet, "/alerts?camera=front_door&limit=5", "", nil, http.StatusOK},
+		{"list alerts with invalid limit", http.MethodGet, "/alerts?limit=abc", "", nil, http.StatusBadRequest},
+		{"list alerts by severity", http.MethodGet, "/alerts?severity=critical", "", nil, http.StatusOK},
+		{"list alerts with invalid severity", http.MethodGet, "/alerts?severity=urgent", "", nil, http.StatusBadRequest},
+		{"export with invalid format", http.MethodGet, "/alerts/export?format=xml", "", nil, http.StatusBadRequest},
+		{"stats", http.MethodGet, "/stats?from=2025-01-01&to=2025-01-08", "", nil, http.StatusOK},
+		{"stats with invalid range", http.MethodGet, "/stats?from=2025-01-08&to=2025-01-01", "", nil, http.StatusBadRequest},
+		{"list incidents", http.MethodGet, "/incidents?limit=10", "", nil, http.StatusOK},
+		{"trigger", http.MethodPost, "/trigger", `{"camera":"front_door"}`, nil, http.StatusOK},
+		{"trigger without camera", http.MethodPost, "/trigger", `{}`, nil, http.StatusBadRequest},
+		{"trigger with invalid body", http.MethodPost, "/trigger", `not json`, nil, http.StatusBadRequest},
+		{"trigger with failing notifier", http.MethodPost, "/trigger", `{"camera":"front_door"}`, errors.New("discord down"), http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, &stubNotifier{err: tt.notifyErr})
+			req := httptest.NewRequest(tt.method, apiVersionPrefix+tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			server.routes().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+
+			path := strings.SplitN(tt.path, "?", 2)[0]
+			operation, ok := doc.Paths[path][strings.ToLower(tt.method)]
+			if !ok {
+				t.Fatalf("operation %s %s is not documented", tt.method, path)
+			}
+			if _, ok := operation.Responses[strconv.Itoa(rec.Code)]; !ok {
+				t.Errorf("status %d of %s %s is not documented", rec.Code, tt.method, path)
+			}
+
+			if rec.Code >= 400 {
+				assertErrorEnvelope(t, rec)
+			}
+		})
+	}
+}
+
+func TestAPIMethodNotAllowed(t *testing.T) {
+	server := newTestServer(t, &stubNotifier{})
+
+	for _, route := range server.apiRoutes() {
+		method := http.MethodPatch
+		req := httptest.NewRequest(method, apiVersionPrefix+route.Path, nil)
+		rec := httptest.NewRecorder()
+		server.routes().ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: status = %d, want %d", method, route.Path, rec.Code, http.StatusMethodNotAllowed)
+			continue
+		}
+		if rec.Header().Get("Allow") == "" {
+			t.Errorf("%s %s: missing Allow header", method, route.Path)
+		}
+		assertErrorEnvelope(t, rec)
+	}
+}
+
+func TestAPIUnknownEndpoint(t *testing.T) {
+	server := newTestServer(t, &stubNotifier{})
+
+	req := httptest.NewRequest(http.MethodGet, apiVersionPrefix+"/does-not-exist", nil)
+	rec := httptest.NewRecorder()
+	server.routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	assertErrorEnvelope(t, rec)
+}
+
+func TestAPIUnversionedAliases(t *testing.T) {
+	server := newTestServer(t, &stubNotifier{})
+
+	var paths []string
+	for _, route := range server.apiRoutes() {
+		if route.Method == http.MethodGet && !strings.Contains(route.Path, "{") {
+			paths = append(paths, route.Path)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		req := httptest.NewRequest(http.MethodGet, "/api"+path, nil)
+		rec := httptest.NewRecorder()
+		server.routes().ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET /api%s: status = %d, want %d", path, rec.Code, http.StatusOK)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package adapters
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+const (
+	// heatmapCellWidth and heatmapCellHeight size one hour of one weekday in the heatmap SVG
+	heatmapCellWidth  = 16
+	heatmapCellHeight = 14
+	// heatmapLabelWidth and heatmapLabelHeight reserve room for the weekday and hour labels
+	heatmapLabelWidth  = 34
+	heatmapLabelHeight = 14
+	// busiestCameraCount is how many cameras the top-N chart shows
+	busiestCameraCount = 5
+	// barHeight is the height of one row of the busiest cameras chart
+	barHeight = 22
+	// barLabelWidth and barMaxWidth split the busiest cameras chart into names and bars
+	barLabelWidth = 110
+	barMaxWidth   = 260
+	// timelinePageSize is the number of alerts shown per timeline page
+	timelinePageSize = 50
+)
+
+// dashboardRange is a selectable time range of the dashboard
+type dashboardRange struct {
+	Key      string
+	Label    string
+	Duration time.Duration
+}
+
+// dashboardRanges lists the ranges offered on the dashboard; the first one is the default
+var dashboardRanges = []dashboardRange{
+	{Key: "24h", Label: "Last 24 hours", Duration: 24 * time.Hour},
+	{Key: "7d", Label: "Last 7 days", Duration: 7 * 24 * time.Hour},
+	{Key: "30d", Label: "Last 30 days", Duration: 30 * 24 * time.Hour},
+}
+
+// heatmapWeekdays orders the heatmap rows from Monday to Sunday
+var heatmapWeekdays = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+// heatmapView is the hour-of-day by day-of-week chart of a single camera
+type heatmapView struct {
+	Camera string
+	Total  int
+	Width  int
+	Height int
+	Rows   []heatmapLabelView
+	Hours  []heatmapLabelView
+	Cells  []heatmapCellView
+}
+
+// heatmapLabelView positions an axis label
+type heatmapLabelView struct {
+	X, Y int
+	Text string
+}
+
+// heatmapCellView positions and shades one cell of a heatmap
+type heatmapCellView struct {
+	X, Y    int
+	Width   int
+	Height  int
+	Count   int
+	Fill    string
+	Opacity string
+	Title   string
+}
+
+// barView is one bar of the busiest cameras chart
+type barView struct {
+	Camera string
+	Count  int
+	Y      int
+	Width  int
+	TextX  int
+}
+
+// handleDashboard renders the activity dashboard for the selected range
+func (s *HTTPServer) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	selected := dashboardRanges[0]
+	for _, candidate := range dashboardRanges {
+		if candidate.Key == r.URL.Query().Get("range") {
+			selected = candidate
+		}
+	}
+	offset, err := queryInt(r, "offset", 0, 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().In(s.config.Location)
+	filter := domain.AlertFilter{From: now.Add(-selected.Duration), To: now}
+
+	cells, err := s.repository.GetActivityHeatmap(filter)
+	if err != nil {
+		slog.Error("Failed to get activity heatmap", "error", err)
+		http.Error(w, "Failed to get activity", http.StatusInternalServerError)
+		return
+	}
+
+	cameraStats, err := s.repository.GetCameraStats(filter)
+	if err != nil {
+		slog.Error("Failed to get camera statistics", "error", err)
+		http.Error(w, "Failed to get activity", http.StatusInternalServerError)
+		return
+	}
+
+	timelineFilter := filter
+	timelineFilter.Limit = timelinePageSize + 1
+	timelineFilter.Offset = offset
+	timeline, err := s.repository.FindAlerts(timelineFilter)
+	if err != nil {
+		slog.Error("Failed to get alert timeline", "error", err)
+		http.Error(w, "Failed to get alerts", http.StatusInternalServerError)
+		return
+	}
+	hasMore := len(timeline) > timelinePageSize
+	if hasMore {
+		timeline = timeline[:timelinePageSize]
+	}
+
+	total := 0
+	for _, stats := range cameraStats {
+		total += stats.Total
+	}
+	busiest := buildBusiestBars(cameraStats)
+
+	data := struct {
+		Title      string
+		Ranges     []dashboardRange
+		Range      dashboardRange
+		From       time.Time
+		To         time.Time
+		Total      int
+		Heatmaps   []heatmapView
+		Busiest    []barView
+		BarsHeight int
+		BarsWidth  int
+		BarsX      int
+		Timeline   []*domain.Alert
+		PrevOffset int
+		NextOffset int
+		HasPrev    bool
+		HasMore    bool
+	}{
+		Title:      "Frigate Alerter - Dashboard",
+		Ranges:     dashboardRanges,
+		Range:      selected,
+		From:       filter.From,
+		To:         filter.To,
+		Total:      total,
+		Heatmaps:   buildHeatmaps(cells),
+		Busiest:    busiest,
+		BarsHeight: len(busiest) * barHeight,
+		BarsWidth:  barLabelWidth + barMaxWidth + 50,
+		BarsX:      barLabelWidth,
+		Timeline:   timeline,
+		PrevOffset: max(offset-timelinePageSize, 0),
+		NextOffset: offset + timelinePageSize,
+		HasPrev:    offset > 0,
+		HasMore:    hasMore,
+	}
+
+	if err := s.templates.render(w, "dashboard", data); err != nil {
+		slog.Error("Failed to render template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// buildHeatmaps lays out one heatmap per camera, shading each cell relative to the camera's busiest hour
+func buildHeatmaps(cells []domain.HeatmapCell) []heatmapView {
+	type grid struct {
+		counts [7][24]int
+		total  int
+		max    int
+	}
+	grids := make(map[string]*grid)
+	var cameras []string
+	for _, cell := range cells {
+		g, ok := grids[cell.Camera]
+		if !ok {
+			g = &grid{}
+			grids[cell.Camera] = g
+			cameras = append(cameras, cell.Camera)
+		}
+		g.counts[cell.Weekday][cell.Hour] += cell.Count
+		g.total += cell.Count
+		g.max = max(g.max, g.counts[cell.Weekday][cell.Hour])
+	}
+	sort.Strings(cameras)
+
+	views := make([]heatmapView, 0, len(cameras))
+	for _, camera := range cameras {
+		g := grids[camera]
+		view := heatmapView{
+			Camera: camera,
+			Total:  g.total,
+			Width:  heatmapLabelWidth + 24*heatmapCellWidth,
+			Height: heatmapLabelHeight + len(heatmapWeekdays)*heatmapCellHeight,
+		}
+
+		for hour := 0; hour < 24; hour += 3 {
+			view.Hours = append(view.Hours, heatmapLabelView{
+				X:    heatmapLabelWidth + hour*heatmapCellWidth,
+				Y:    heatmapLabelHeight - 3,
+				Text: fmt.Sprintf("%02d", hour),
+			})
+		}
+
+		for row, weekday := range heatmapWeekdays {
+			y := heatmapLabelHeight + row*heatmapCellHeight
+			view.Rows = append(view.Rows, heatmapLabelView{
+				X:    0,
+				Y:    y + heatmapCellHeight - 3,
+				Text: weekday.String()[:3],
+			})
+
+			for hour := 0; hour < 24; hour++ {
+				count := g.counts[weekday][hour]
+				cell := heatmapCellView{
+					X:       heatmapLabelWidth + hour*heatmapCellWidth,
+					Y:       y,
+					Width:   heatmapCellWidth - 1,
+					Height:  heatmapCellHeight - 1,
+					Count:   count,
+					Fill:    "#e9ecef",
+					Opacity: "1",
+					Title:   fmt.Sprintf("%s %02d:00 - %d alerts", weekday, hour, count),
+				}
+				if count > 0 {
+					// Keep even a single alert visible against the empty cells
+					cell.Fill = "#dc3545"
+					cell.Opacity = fmt.Sprintf("%.2f", 0.15+0.85*float64(count)/float64(g.max))
+				}
+				view.Cells = append(view.Cells, cell)
+			}
+		}
+
+		views = append(views, view)
+	}
+
+	return views
+}
+
+// buildBusiestBars lays out the cameras with the most alerts, widest bar first
+func buildBusiestBars(stats []domain.CameraStats) []barView {
+	sorted := make([]domain.CameraStats, len(stats))
+	copy(sorted, stats)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Total > sorted[j].Total
+	})
+	if len(sorted) > busiestCameraCount {
+		sorted = sorted[:busiestCameraCount]
+	}
+
+	bars := make([]barView, 0, len(sorted))
+	for i, camera := range sorted {
+		// Bars are scaled relative to the busiest camera
+		width := max(camera.Total*barMaxWidth/sorted[0].Total, 1)
+		bars = append(bars, barView{
+			Camera: camera.Camera,
+			Count:  camera.Total,
+			Y:      i * barHeight,
+			Width:  width,
+			TextX:  barLabelWidth + width + 6,
+		})
+	}
+	return bars
+}
@@ -0,0 +1,118 @@
+package adapters
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestAPIExportFormats(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+	documented := doc.Paths["/alerts/export"]["get"].Responses["200"]
+
+	backend := newTestBackend(t, &stubNotifier{})
+	server := backend.server(t, HTTPServerDeps{ExportService: application.NewExportService(backend.repository, backend.frigate, backend.config)})
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	alerts := []*domain.Alert{
+		{ID: "1700000000.0-abc", Type: "new", CameraName: "front_door", Label: "person", EventID: "1700000000.0-abc", TriggeredAt: start, AlertMessage: "A person detected in the front_door camera"},
+		{ID: "1700000001.0-def", Type: "new", CameraName: "driveway", Label: "car", EventID: "1700000001.0-def", TriggeredAt: start.Add(time.Minute), AlertMessage: "A car detected in the driveway camera"},
+		{ID: "manual_front_door_1", Type: "manual", CameraName: "front_door", TriggeredAt: start.Add(2 * time.Minute), AlertMessage: "Manual snapshot from front_door camera"},
+	}
+	for _, alert := range alerts {
+		if err := backend.repository.SaveAlert(alert); err != nil {
+			t.Fatalf("failed to save alert: %v", err)
+		}
+	}
+
+	export := func(t *testing.T, query string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, apiVersionPrefix+"/alerts/export?"+query, nil)
+		rec := httptest.NewRecorder()
+		server.routes().ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+		if !strings.HasPrefix(rec.Header().Get("Content-Disposition"), "attachment;") {
+			t.Errorf("Content-Disposition = %q, want an attachment", rec.Header().Get("Content-Disposition"))
+		}
+		mediaType := strings.SplitN(rec.Header().Get("Content-Type"), ";", 2)[0]
+		if !strings.Contains(string(documented), `"`+mediaType+`"`) {
+			t.Errorf("content type %q is not documented", mediaType)
+		}
+		return rec
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		rec := export(t, "format=csv&camera=front_door")
+		records, err := csv.NewReader(rec.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("invalid CSV: %v", err)
+		}
+		if len(records) != 3 {
+			t.Fatalf("got %d rows, want header and 2 alerts", len(records))
+		}
+		if records[1][0] != "1700000000.0-abc" || records[2][0] != "manual_front_door_1" {
+			t.Errorf("rows are not the front_door alerts oldest first: %v", records[1:])
+		}
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		rec := export(t, "format=ndjson&from=2025-01-01T12:00:30Z")
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("got %d lines, want 2", len(lines))
+		}
+		var alert domain.Alert
+		if err := json.Unmarshal([]byte(lines[0]), &alert); err != nil {
+			t.Fatalf("invalid JSON line: %v", err)
+		}
+		if alert.ID != "1700000001.0-def" {
+			t.Errorf("first alert = %q, want 1700000001.0-def", alert.ID)
+		}
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		rec := export(t, "format=zip")
+		archive, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+		if err != nil {
+			t.Fatalf("invalid ZIP: %v", err)
+		}
+		files := make(map[string]*zip.File)
+		for _, file := range archive.File {
+			files[file.Name] = file
+		}
+		for _, alert := range alerts {
+			if files["alerts/"+alert.ID+".json"] == nil {
+				t.Errorf("missing alerts/%s.json", alert.ID)
+			}
+		}
+
+		// Only the first event has a snapshot in the fake Frigate API
+		snapshot := files["snapshots/1700000000.0-abc.jpg"]
+		if snapshot == nil {
+			t.Fatal("missing snapshot of the first alert")
+		}
+		r, err := snapshot.Open()
+		if err != nil {
+			t.Fatalf("failed to open snapshot: %v", err)
+		}
+		defer r.Close()
+		data, _ := io.ReadAll(r)
+		if !bytes.Equal(data, testSnapshot) {
+			t.Errorf("snapshot = %q, want %q", data, testSnapshot)
+		}
+		if len(files) != len(alerts)+1 {
+			t.Errorf("archive has %d files, want %d", len(files), len(alerts)+1)
+		}
+	})
+}
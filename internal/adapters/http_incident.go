@@ -0,0 +1,100 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// handleAPIGetIncidents returns incidents, most recently active first
+func (s *HTTPServer) handleAPIGetIncidents(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt(r, "limit", 50, 1)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error(), nil)
+		return
+	}
+	offset, err := queryInt(r, "offset", 0, 0)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error(), nil)
+		return
+	}
+
+	incidents, err := s.incidentService.GetIncidents(limit, offset)
+	if err != nil {
+		slog.Error("Failed to get incidents", "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to get incidents", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, incidents)
+}
+
+// handleAPIGetIncident returns an incident with its alerts
+func (s *HTTPServer) handleAPIGetIncident(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	details, err := s.incidentService.GetIncidentDetails(id)
+	if errors.Is(err, domain.ErrIncidentNotFound) {
+		writeAPIError(w, http.StatusNotFound, "not_found", fmt.Sprintf("Incident %s does not exist", id), nil)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to get incident", "incident_id", id, "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to get incident", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, details)
+}
+
+// handleIncidents renders the list of recent incidents
+func (s *HTTPServer) handleIncidents(w http.ResponseWriter, r *http.Request) {
+	incidents, err := s.incidentService.GetIncidents(50, 0)
+	if err != nil {
+		slog.Error("Failed to get incidents", "error", err)
+		http.Error(w, "Failed to get incidents", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Title     string
+		Incidents []*domain.Incident
+		Enabled   bool
+	}{
+		Title:     "Frigate Alerter - Incidents",
+		Incidents: incidents,
+		Enabled:   s.incidentService.Enabled(),
+	}
+
+	if err := s.templates.render(w, "incidents", data); err != nil {
+		slog.Error("Failed to render template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// handleIncidentDetails renders an incident as a timeline of its alerts
+func (s *HTTPServer) handleIncidentDetails(w http.ResponseWriter, r *http.Request) {
+	details, err := s.incidentService.GetIncidentDetails(r.PathValue("id"))
+	if errors.Is(err, domain.ErrIncidentNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to get incident", "incident_id", r.PathValue("id"), "error", err)
+		http.Error(w, "Failed to get incident", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Title   string
+		Details *domain.IncidentDetails
+	}{
+		Title:   fmt.Sprintf("Frigate Alerter - Incident %s", details.Incident.ID),
+		Details: details,
+	}
+
+	if err := s.templates.render(w, "incident_details", data); err != nil {
+		slog.Error("Failed to render template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
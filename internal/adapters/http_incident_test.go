@@ -0,0 +1,54 @@
+package adapters
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestAPIIncidents(t *testing.T) {
+	notifier := &stubNotifier{}
+	backend := newTestBackend(t, notifier)
+	incidents := application.NewIncidentService(backend.repository, backend.repository, notifier, backend.config)
+	server := backend.server(t, HTTPServerDeps{IncidentService: incidents})
+	backend.config.CameraAdjacency = map[string][]string{"driveway": {"side_gate"}, "side_gate": {"front_door"}}
+	backend.config.IncidentGap = time.Minute
+
+	// A person walks up the driveway to the front door while a cat crosses the unrelated backyard
+	alertService := backend.alertService(application.AlertServiceDeps{Incidents: incidents})
+	for i, event := range []domain.FrigateBefore{
+		{ID: "1", Camera: "driveway", Label: "person"},
+		{ID: "2", Camera: "backyard", Label: "cat"},
+		{ID: "3", Camera: "side_gate", Label: "person"},
+		{ID: "4", Camera: "front_door", Label: "person"},
+	} {
+		if err := alertService.ProcessEvent(&domain.FrigateEvent{Type: "new", Before: event}); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+	}
+	if len(notifier.sent) != 2 || len(notifier.updated) != 2 {
+		t.Fatalf("sent %d and updated %d messages, want 2 and 2", len(notifier.sent), len(notifier.updated))
+	}
+
+	rec := httptest.NewRecorder()
+	server.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, apiVersionPrefix+"/incidents/"+notifier.sent[0].IncidentID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+	var details domain.IncidentDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &details); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got := strings.Join(details.Incident.Cameras, ","); got != "driveway,side_gate,front_door" {
+		t.Errorf("incident path = %s, want driveway,side_gate,front_door", got)
+	}
+	if len(details.Alerts) != 3 || details.Alerts[0].CameraName != "driveway" {
+		t.Errorf("incident has %d alerts, want 3 starting at the driveway", len(details.Alerts))
+	}
+}
@@ -0,0 +1,78 @@
+package adapters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// modeRequest is the body of a request setting the mode; without duration or until the mode stays until it is cleared
+type modeRequest struct {
+	Mode     string     `json:"mode"`
+	Duration string     `json:"duration"`
+	Until    *time.Time `json:"until"`
+	User     string     `json:"user"`
+}
+
+// handleAPIGetMode returns the mode in effect and where it comes from
+func (s *HTTPServer) handleAPIGetMode(w http.ResponseWriter, r *http.Request) {
+	state, err := s.modeService.Current(time.Now())
+	if err != nil {
+		slog.Error("Failed to get mode", "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to get mode", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// handleAPISetMode sets the mode manually, overriding the schedule
+func (s *HTTPServer) handleAPISetMode(w http.ResponseWriter, r *http.Request) {
+	var request modeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", "Request body must be a JSON object", nil)
+		return
+	}
+
+	until := request.Until
+	if request.Duration != "" {
+		if until != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_request", "Give either duration or until, not both", nil)
+			return
+		}
+		duration, err := time.ParseDuration(request.Duration)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("Invalid duration %q, use e.g. 30m or 1h", request.Duration), nil)
+			return
+		}
+		t := time.Now().Add(duration)
+		until = &t
+	}
+
+	state, err := s.modeService.SetMode(request.Mode, until, request.User)
+	if errors.Is(err, domain.ErrInvalidMode) {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to set mode", "error", err, "mode", request.Mode)
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to set mode", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// handleAPIClearMode removes the manual mode so the schedule or default mode applies again
+func (s *HTTPServer) handleAPIClearMode(w http.ResponseWriter, r *http.Request) {
+	state, err := s.modeService.ClearMode()
+	if err != nil {
+		slog.Error("Failed to clear mode", "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to clear mode", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
@@ -0,0 +1,92 @@
+package adapters
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestAPIArmingModes(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+	notifier := &stubNotifier{}
+	backend := newTestBackend(t, notifier)
+	modes := application.NewModeService(backend.repository, application.NewPresenceService(backend.config), backend.config)
+	server := backend.server(t, HTTPServerDeps{ModeService: modes})
+	backend.config.Modes = map[string]config.ModeConfig{
+		"home": {Default: false, Rules: map[string]bool{"front_door/person": true}},
+		"away": {Default: true},
+	}
+	backend.config.DefaultMode = "home"
+
+	steps := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantMode   string
+		wantSource string
+	}{
+		{"default mode", http.MethodGet, "", http.StatusOK, "home", domain.ModeSourceDefault},
+		{"unknown mode", http.MethodPut, `{"mode":"vacation"}`, http.StatusBadRequest, "", ""},
+		{"mode in the past", http.MethodPut, `{"mode":"away","duration":"-1h"}`, http.StatusBadRequest, "", ""},
+		{"set mode", http.MethodPut, `{"mode":"away","duration":"1h","user":"alice"}`, http.StatusOK, "away", domain.ModeSourceManual},
+		{"clear mode", http.MethodDelete, "", http.StatusOK, "home", domain.ModeSourceDefault},
+	}
+	for _, step := range steps {
+		req := httptest.NewRequest(step.method, apiVersionPrefix+"/mode", strings.NewReader(step.body))
+		rec := httptest.NewRecorder()
+		server.routes().ServeHTTP(rec, req)
+
+		if rec.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d, body: %s", step.name, rec.Code, step.wantStatus, rec.Body.String())
+		}
+		if _, ok := doc.Paths["/mode"][strings.ToLower(step.method)].Responses[strconv.Itoa(rec.Code)]; !ok {
+			t.Errorf("%s: status %d is not documented", step.name, rec.Code)
+		}
+		if rec.Code >= 400 {
+			assertErrorEnvelope(t, rec)
+			continue
+		}
+		var state domain.ModeState
+		if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+			t.Fatalf("%s: invalid JSON: %v", step.name, err)
+		}
+		if state.Mode != step.wantMode || state.Source != step.wantSource {
+			t.Errorf("%s: mode = %s (%s), want %s (%s)", step.name, state.Mode, state.Source, step.wantMode, step.wantSource)
+		}
+	}
+
+	// At home only people at the front door notify; every alert records the mode
+	alertService := backend.alertService(application.AlertServiceDeps{Modes: modes})
+	for i, event := range []domain.FrigateBefore{
+		{ID: "1", Camera: "front_door", Label: "person"},
+		{ID: "2", Camera: "front_door", Label: "car"},
+	} {
+		if err := alertService.ProcessEvent(&domain.FrigateEvent{Type: "new", Before: event}); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+	}
+
+	if len(notifier.sent) != 1 || notifier.sent[0].Label != "person" {
+		t.Errorf("sent %d notifications, want only the person alert", len(notifier.sent))
+	}
+	alerts, err := backend.repository.FindAlerts(domain.AlertFilter{})
+	if err != nil {
+		t.Fatalf("failed to get alerts: %v", err)
+	}
+	for _, alert := range alerts {
+		if alert.Mode != "home" {
+			t.Errorf("alert %s recorded mode %q, want home", alert.ID, alert.Mode)
+		}
+		if wantMuted := alert.Label == "car"; (alert.SuppressedBy == domain.SuppressedByMode) != wantMuted {
+			t.Errorf("alert of %s was muted by %q", alert.Label, alert.SuppressedBy)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package adapters
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// handleReport renders the daily or weekly report. Without a "to" parameter it shows the latest scheduled report,
+// or the period up to now when the report is not scheduled.
+func (s *HTTPServer) handleReport(w http.ResponseWriter, r *http.Request) {
+	period := r.PathValue("period")
+	to := s.reportService.LatestEnd(period, time.Now())
+	if value := r.URL.Query().Get("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid to parameter, use RFC 3339", http.StatusBadRequest)
+			return
+		}
+		to = parsed
+	}
+
+	report, err := s.reportService.Generate(period, to)
+	if errors.Is(err, domain.ErrUnknownReportPeriod) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to generate report", "error", err, "period", period)
+		http.Error(w, "Failed to generate report", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Title   string
+		Report  *domain.Report
+		Earlier string
+		Later   string
+	}{
+		Title:   "Frigate Alerter - " + reportTitle(report),
+		Report:  report,
+		Earlier: report.From.Format(time.RFC3339),
+	}
+	days := 1
+	if report.Period == domain.ReportWeekly {
+		days = 7
+	}
+	if later := report.To.AddDate(0, 0, days); !later.After(time.Now()) {
+		data.Later = later.Format(time.RFC3339)
+	}
+
+	if err := s.templates.render(w, "report", data); err != nil {
+		slog.Error("Failed to render template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,48 @@
+package adapters
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+func TestAPIReports(t *testing.T) {
+	notifier := &stubNotifier{}
+	backend := newTestBackend(t, notifier)
+	reports := application.NewReportService(backend.repository, backend.repository, map[string]ports.ReportNotifier{config.NotifierDiscord: notifier}, backend.config)
+	server := backend.server(t, HTTPServerDeps{ReportService: reports})
+
+	// Two cars and a person on the driveway this day, one car the day before
+	to := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	for i, alert := range []domain.Alert{
+		{CameraName: "driveway", Label: "car", TriggeredAt: to.Add(-2 * time.Hour)},
+		{CameraName: "driveway", Label: "car", TriggeredAt: to.Add(-2*time.Hour + time.Minute)},
+		{CameraName: "driveway", Label: "person", TriggeredAt: to.Add(-5 * time.Hour)},
+		{CameraName: "driveway", Label: "car", TriggeredAt: to.Add(-30 * time.Hour)},
+	} {
+		alert.ID = fmt.Sprintf("report_%d", i)
+		alert.Type = "new"
+		alert.AlertMessage = "A " + alert.Label + " detected in the driveway camera"
+		if err := backend.repository.SaveAlert(&alert); err != nil {
+			t.Fatalf("failed to save alert: %v", err)
+		}
+	}
+	rec := httptest.NewRecorder()
+	server.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reports/daily?to="+to.Format(time.RFC3339), nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "200%") {
+		t.Errorf("report page status = %d, want 200 showing the change", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	server.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reports/monthly", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown period status = %d, want 404", rec.Code)
+	}
+}
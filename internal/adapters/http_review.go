@@ -0,0 +1,130 @@
+package adapters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// reviewRequest is the body of the acknowledge, notes and false-positive endpoints
+type reviewRequest struct {
+	User          string `json:"user"`
+	Text          string `json:"text"`
+	FalsePositive *bool  `json:"false_positive"`
+}
+
+// decodeReviewRequest decodes a review request body, writing an error response if it is not valid JSON
+func decodeReviewRequest(w http.ResponseWriter, r *http.Request) (*reviewRequest, bool) {
+	var request reviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", "Request body must be a JSON object", nil)
+		return nil, false
+	}
+	return &request, true
+}
+
+// writeReviewError maps errors of the review service to API errors
+func writeReviewError(w http.ResponseWriter, id string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrAlertNotFound):
+		writeAPIError(w, http.StatusNotFound, "not_found", fmt.Sprintf("Alert %s does not exist", id), nil)
+	case errors.Is(err, domain.ErrInvalidReview):
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+	default:
+		slog.Error("Failed to review alert", "alert_id", id, "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to update alert", nil)
+	}
+}
+
+// handleAPIGetAlert returns an alert with its notes and audit trail
+func (s *HTTPServer) handleAPIGetAlert(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	details, err := s.reviewService.GetAlertDetails(id)
+	if err != nil {
+		writeReviewError(w, id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, details)
+}
+
+// handleAPIAcknowledgeAlert records who acknowledged an alert
+func (s *HTTPServer) handleAPIAcknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeReviewRequest(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("id")
+	details, err := s.reviewService.Acknowledge(id, request.User)
+	if err != nil {
+		writeReviewError(w, id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, details)
+}
+
+// handleAPIAddAlertNote attaches a note to an alert
+func (s *HTTPServer) handleAPIAddAlertNote(w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeReviewRequest(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("id")
+	details, err := s.reviewService.AddNote(id, request.User, request.Text)
+	if err != nil {
+		writeReviewError(w, id, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, details)
+}
+
+// handleAPISetFalsePositive flags or unflags an alert as a false positive
+func (s *HTTPServer) handleAPISetFalsePositive(w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeReviewRequest(w, r)
+	if !ok {
+		return
+	}
+	if request.FalsePositive == nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", "false_positive is required", nil)
+		return
+	}
+	id := r.PathValue("id")
+	details, err := s.reviewService.SetFalsePositive(r.Context(), id, request.User, *request.FalsePositive)
+	if err != nil {
+		writeReviewError(w, id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, details)
+}
+
+// handleAlertDetails renders a single alert with controls to acknowledge, annotate and flag it
+func (s *HTTPServer) handleAlertDetails(w http.ResponseWriter, r *http.Request) {
+	details, err := s.reviewService.GetAlertDetails(r.PathValue("id"))
+	if errors.Is(err, domain.ErrAlertNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to get alert", "alert_id", r.PathValue("id"), "error", err)
+		http.Error(w, "Failed to get alert", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Title   string
+		Details *domain.AlertDetails
+		Sync    bool
+	}{
+		Title:   fmt.Sprintf("Frigate Alerter - Alert %s", details.Alert.ID),
+		Details: details,
+		Sync:    s.config.FrigateSyncFalsePositives && details.Alert.EventID != "",
+	}
+
+	if err := s.templates.render(w, "alert_details", data); err != nil {
+		slog.Error("Failed to render template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,85 @@
+package adapters
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestAPIReviewAlert(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+	backend := newTestBackend(t, &stubNotifier{})
+	server := backend.server(t, HTTPServerDeps{ReviewService: application.NewReviewService(backend.repository, backend.frigate, backend.config)})
+	alert := &domain.Alert{ID: "1700000000.0-abc", Type: "new", CameraName: "front_door", Label: "cat", EventID: "1700000000.0-abc", TriggeredAt: time.Now(), AlertMessage: "A cat detected in the front_door camera"}
+	if err := backend.repository.SaveAlert(alert); err != nil {
+		t.Fatalf("failed to save alert: %v", err)
+	}
+
+	steps := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"unknown alert", http.MethodGet, "/alerts/missing", "", http.StatusNotFound},
+		{"acknowledge without user", http.MethodPost, "/alerts/{id}/acknowledge", `{}`, http.StatusBadRequest},
+		{"acknowledge unknown alert", http.MethodPost, "/alerts/missing/acknowledge", `{"user":"alice"}`, http.StatusNotFound},
+		{"acknowledge", http.MethodPost, "/alerts/{id}/acknowledge", `{"user":"alice"}`, http.StatusOK},
+		{"acknowledge again", http.MethodPost, "/alerts/{id}/acknowledge", `{"user":"bob"}`, http.StatusOK},
+		{"empty note", http.MethodPost, "/alerts/{id}/notes", `{"user":"bob","text":"  "}`, http.StatusBadRequest},
+		{"add note", http.MethodPost, "/alerts/{id}/notes", `{"user":"bob","text":"Neighbour's cat"}`, http.StatusCreated},
+		{"false positive without flag", http.MethodPost, "/alerts/{id}/false-positive", `{"user":"bob"}`, http.StatusBadRequest},
+		{"mark false positive", http.MethodPost, "/alerts/{id}/false-positive", `{"user":"bob","false_positive":true}`, http.StatusOK},
+		{"get alert", http.MethodGet, "/alerts/{id}", "", http.StatusOK},
+	}
+
+	var details domain.AlertDetails
+	for _, step := range steps {
+		path := strings.Replace(step.path, "{id}", alert.ID, 1)
+		req := httptest.NewRequest(step.method, apiVersionPrefix+path, strings.NewReader(step.body))
+		rec := httptest.NewRecorder()
+		server.routes().ServeHTTP(rec, req)
+
+		if rec.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d, body: %s", step.name, rec.Code, step.wantStatus, rec.Body.String())
+		}
+		if _, ok := doc.Paths[strings.Replace(step.path, "missing", "{id}", 1)][strings.ToLower(step.method)].Responses[strconv.Itoa(rec.Code)]; !ok {
+			t.Errorf("%s: status %d is not documented", step.name, rec.Code)
+		}
+		if rec.Code >= 400 {
+			assertErrorEnvelope(t, rec)
+			continue
+		}
+		details = domain.AlertDetails{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &details); err != nil {
+			t.Fatalf("%s: invalid response: %v", step.name, err)
+		}
+	}
+
+	if details.Alert.AcknowledgedBy != "alice" || details.Alert.AcknowledgedAt == nil {
+		t.Errorf("acknowledgement = %q at %v, want the first one by alice", details.Alert.AcknowledgedBy, details.Alert.AcknowledgedAt)
+	}
+	if !details.Alert.FalsePositive {
+		t.Error("alert is not flagged as a false positive")
+	}
+	if len(details.Notes) != 1 || details.Notes[0].Text != "Neighbour's cat" {
+		t.Errorf("notes = %+v, want the single note", details.Notes)
+	}
+
+	var actions []string
+	for _, entry := range details.Audit {
+		actions = append(actions, entry.Action)
+	}
+	want := []string{domain.AuditAcknowledged, domain.AuditNoteAdded, domain.AuditMarkedFalsePositive, domain.AuditFrigateSynced}
+	if strings.Join(actions, ",") != strings.Join(want, ",") {
+		t.Errorf("audit trail = %v, want %v", actions, want)
+	}
+}
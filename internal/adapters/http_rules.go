@@ -0,0 +1,62 @@
+package adapters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// ruleTestRequest is the body of a dry run; exactly one of the event, the alert ID or the time range is given
+type ruleTestRequest struct {
+	Event   *domain.FrigateEvent `json:"event"`
+	At      string               `json:"at"`
+	AlertID string               `json:"alert_id"`
+	From    string               `json:"from"`
+	To      string               `json:"to"`
+	Limit   int                  `json:"limit"`
+}
+
+// handleAPITestRules traces what the alerter would do with an event or stored alerts under the current rules
+func (s *HTTPServer) handleAPITestRules(w http.ResponseWriter, r *http.Request) {
+	var request ruleTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", "Request body must be a JSON object", nil)
+		return
+	}
+
+	test := application.RuleTest{Event: request.Event, AlertID: request.AlertID, Limit: request.Limit}
+	for _, field := range []struct {
+		name  string
+		value string
+		t     *time.Time
+	}{{"at", request.At, &test.At}, {"from", request.From, &test.From}, {"to", request.To, &test.To}} {
+		if field.value == "" {
+			continue
+		}
+		t, err := domain.ParseFilterTime(field.value, s.config.Location)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("Invalid %s: %v", field.name, err), nil)
+			return
+		}
+		*field.t = t
+	}
+
+	traces, err := s.alertService.TestRules(test)
+	switch {
+	case errors.Is(err, domain.ErrInvalidRuleTest):
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+	case errors.Is(err, domain.ErrAlertNotFound):
+		writeAPIError(w, http.StatusNotFound, "not_found", fmt.Sprintf("Alert %s does not exist", request.AlertID), nil)
+	case err != nil:
+		slog.Error("Failed to test rules", "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to test rules", nil)
+	default:
+		writeJSON(w, http.StatusOK, traces)
+	}
+}
@@ -0,0 +1,119 @@
+package adapters
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestAPIRulesDryRun(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+	notifier := &stubNotifier{}
+	backend := newTestBackend(t, notifier)
+	backend.config.DefaultSeverity = domain.SeverityInfo
+	backend.config.SeverityRules = []config.SeverityRule{{Severity: domain.SeverityWarning, Labels: []string{"car"}}}
+	backend.config.DetectionFilters = []config.DetectionFilter{{Labels: []string{"person"}, MinArea: 2500}}
+	backend.config.Escalations = []config.EscalationPolicy{{Name: "cars", Labels: []string{"car"}, Steps: []config.EscalationStep{{After: "5m", Notifier: config.NotifierEmail}}}}
+	snoozes := application.NewSnoozeService(backend.repository, backend.config)
+	alertService := backend.alertService(application.AlertServiceDeps{Snoozes: snoozes})
+	server := backend.server(t, HTTPServerDeps{SnoozeService: snoozes, AlertService: alertService})
+	if _, err := snoozes.Snooze("front_door", "", time.Now().Add(time.Hour), "bob"); err != nil {
+		t.Fatalf("failed to snooze: %v", err)
+	}
+	if err := alertService.ProcessEvent(&domain.FrigateEvent{Type: "new", Before: domain.FrigateBefore{ID: "1", Camera: "driveway", Label: "car"}}); err != nil {
+		t.Fatalf("failed to raise alert: %v", err)
+	}
+	stored, err := backend.repository.FindAlerts(domain.AlertFilter{})
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("stored %d alerts (err %v), want 1", len(stored), err)
+	}
+
+	steps := []struct {
+		name          string
+		body          string
+		wantStatus    int
+		wantOutcome   string
+		notifications int
+	}{
+		{"car with escalation", `{"event":{"type":"new","before":{"id":"2","camera":"driveway","label":"car"}},"at":"2030-01-01"}`, http.StatusOK, domain.OutcomeMatch, 2},
+		{"snoozed camera", `{"event":{"type":"new","before":{"id":"3","camera":"front_door","label":"car"}}}`, http.StatusOK, domain.OutcomeMute, 0},
+		{"small person", `{"event":{"type":"new","before":{"id":"4","camera":"driveway","label":"person","box":[0,0,10,10]}}}`, http.StatusOK, domain.OutcomeReject, 0},
+		{"end event", `{"event":{"type":"end","before":{"id":"1","camera":"driveway","label":"car"}}}`, http.StatusOK, domain.OutcomeReject, 0},
+		{"stored alert", `{"alert_id":"` + stored[0].ID + `"}`, http.StatusOK, domain.OutcomeMatch, 2},
+		{"time range", `{"from":"2000-01-01","limit":10}`, http.StatusOK, domain.OutcomeMatch, 2},
+		{"unknown alert", `{"alert_id":"missing"}`, http.StatusNotFound, "", 0},
+		{"event and alert", `{"alert_id":"missing","event":{"type":"new"}}`, http.StatusBadRequest, "", 0},
+		{"nothing", `{}`, http.StatusBadRequest, "", 0},
+		{"invalid time", `{"from":"yesterday"}`, http.StatusBadRequest, "", 0},
+	}
+	for _, step := range steps {
+		req := httptest.NewRequest(http.MethodPost, apiVersionPrefix+"/rules/test", strings.NewReader(step.body))
+		rec := httptest.NewRecorder()
+		server.routes().ServeHTTP(rec, req)
+
+		if rec.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d, body: %s", step.name, rec.Code, step.wantStatus, rec.Body.String())
+		}
+		if _, ok := doc.Paths["/rules/test"]["post"].Responses[strconv.Itoa(rec.Code)]; !ok {
+			t.Errorf("%s: status %d is not documented", step.name, rec.Code)
+		}
+		if rec.Code >= 400 {
+			assertErrorEnvelope(t, rec)
+			continue
+		}
+
+		var traces []domain.DecisionTrace
+		if err := json.Unmarshal(rec.Body.Bytes(), &traces); err != nil || len(traces) != 1 {
+			t.Fatalf("%s: got %d traces (err %v), want 1: %s", step.name, len(traces), err, rec.Body.String())
+		}
+		// The severity rule, the snooze or the rejection shows up as a step of its own
+		trace := traces[0]
+		explained := slices.ContainsFunc(trace.Steps, func(s domain.DecisionStep) bool { return s.Outcome == step.wantOutcome })
+		if !explained || len(trace.Notifications) != step.notifications {
+			t.Errorf("%s: got %d notifications and steps %+v, want %d and a %s step", step.name, len(trace.Notifications), trace.Steps, step.notifications, step.wantOutcome)
+		}
+	}
+
+	// Nothing was saved or sent by the dry runs
+	if alerts, err := backend.repository.FindAlerts(domain.AlertFilter{}); err != nil || len(alerts) != 1 {
+		t.Errorf("stored %d alerts after dry runs (err %v), want 1", len(alerts), err)
+	}
+	if len(notifier.sent) != 1 {
+		t.Errorf("sent %d notifications after dry runs, want 1", len(notifier.sent))
+	}
+}
+
+func TestAPIConditionExpressions(t *testing.T) {
+	backend := newTestBackend(t, &stubNotifier{})
+	backend.config.DefaultSeverity = domain.SeverityInfo
+	condition, err := config.CompileSeverityCondition(`label == "person" && score > 0.8 && "porch" in zones`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.config.SeverityRules = []config.SeverityRule{{Severity: domain.SeverityCritical, When: condition.String(), Condition: condition}}
+	server := backend.server(t, HTTPServerDeps{AlertService: backend.alertService(application.AlertServiceDeps{})})
+
+	// The dry run names the expression that matched
+	body := `{"event":{"type":"new","before":{"id":"1","camera":"front_door","label":"person","top_score":0.9,"current_zones":["porch"]}}}`
+	req := httptest.NewRequest(http.MethodPost, apiVersionPrefix+"/rules/test", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.routes().ServeHTTP(rec, req)
+	var traces []domain.DecisionTrace
+	if err := json.Unmarshal(rec.Body.Bytes(), &traces); err != nil || len(traces) != 1 {
+		t.Fatalf("got %d traces (err %v), want 1: %s", len(traces), err, rec.Body.String())
+	}
+	if !slices.ContainsFunc(traces[0].Steps, func(s domain.DecisionStep) bool {
+		return strings.HasPrefix(s.Detail, `severity rule 1, when label == "person"`)
+	}) {
+		t.Errorf("steps %+v do not name the matching expression", traces[0].Steps)
+	}
+}
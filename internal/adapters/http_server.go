@@ -1,15 +1,17 @@
 package adapters
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"html/template"
+	"io/fs"
 	"log/slog"
 	"net/http"
-	"path/filepath"
+	"sort"
 	"strconv"
 	"time"
 
+	"github.com/vibin/frigate_alerter/internal/application"
 	"github.com/vibin/frigate_alerter/internal/config"
 	"github.com/vibin/frigate_alerter/internal/domain"
 	"github.com/vibin/frigate_alerter/internal/ports"
@@ -17,82 +19,115 @@ import (
 
 // HTTPServer provides a web UI for the Frigate alerter
 type HTTPServer struct {
-	repository      ports.AlertRepository
-	notifier        ports.AlertNotifier
-	config          *config.Config
-	frigateService  *FrigateService
-	templatesDir    string
-	staticDir       string
-	server          *http.Server
+	repository       ports.AlertRepository
+	notifier         ports.AlertNotifier
+	config           *config.Config
+	frigateService   *FrigateService
+	healthService    *application.HealthService
+	exportService    *application.ExportService
+	reviewService    *application.ReviewService
+	snoozeService    *application.SnoozeService
+	modeService      *application.ModeService
+	incidentService  *application.IncidentService
+	watchlistService *application.WatchlistService
+	reportService    *application.ReportService
+	alertService     *application.AlertService
+	assets           fs.FS
+	templates        *templateRenderer
+	server           *http.Server
 }
 
-// CameraInfo represents information about a camera
-type CameraInfo struct {
-	Name  string `json:"name"`
-	Count int    `json:"alert_count"`
+// HTTPServerDeps holds the repository, notifier and services the web UI and the API are served from
+type HTTPServerDeps struct {
+	Repository       ports.AlertRepository
+	Notifier         ports.AlertNotifier
+	FrigateService   *FrigateService
+	HealthService    *application.HealthService
+	ExportService    *application.ExportService
+	ReviewService    *application.ReviewService
+	SnoozeService    *application.SnoozeService
+	ModeService      *application.ModeService
+	IncidentService  *application.IncidentService
+	WatchlistService *application.WatchlistService
+	ReportService    *application.ReportService
+	AlertService     *application.AlertService
 }
 
-// AlertResponse represents the result of an on-demand alert
-type AlertResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	AlertID string `json:"alert_id,omitempty"`
-}
+// NewHTTPServer creates a new HTTP server and parses the page templates
+func NewHTTPServer(deps HTTPServerDeps, config *config.Config) (*HTTPServer, error) {
+	assets := webAssets(config)
+	templates, err := newTemplateRenderer(assets, templateFuncs(config), config.WebDir != "")
+	if err != nil {
+		return nil, err
+	}
 
-// NewHTTPServer creates a new HTTP server
-func NewHTTPServer(
-	repository ports.AlertRepository,
-	notifier ports.AlertNotifier,
-	frigateService *FrigateService,
-	config *config.Config,
-) *HTTPServer {
 	return &HTTPServer{
-		repository:     repository,
-		notifier:       notifier,
-		config:         config,
-		frigateService: frigateService,
-		templatesDir:   "web/templates",
-		staticDir:      "web/static",
-	}
+		repository:       deps.Repository,
+		notifier:         deps.Notifier,
+		config:           config,
+		frigateService:   deps.FrigateService,
+		healthService:    deps.HealthService,
+		exportService:    deps.ExportService,
+		reviewService:    deps.ReviewService,
+		snoozeService:    deps.SnoozeService,
+		modeService:      deps.ModeService,
+		incidentService:  deps.IncidentService,
+		watchlistService: deps.WatchlistService,
+		reportService:    deps.ReportService,
+		alertService:     deps.AlertService,
+		assets:           assets,
+		templates:        templates,
+	}, nil
 }
 
 // Start starts the HTTP server
 func (s *HTTPServer) Start() error {
+	addr := fmt.Sprintf(":%s", s.config.ServerPort)
+	s.server = &http.Server{
+		Addr:         addr,
+		Handler:      s.routes(),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	slog.Info("Starting HTTP server", "address", addr)
+	return s.server.ListenAndServe()
+}
+
+// routes builds the router for the web UI, the API and the health endpoints
+func (s *HTTPServer) routes() http.Handler {
 	router := http.NewServeMux()
 
 	// Static files handler
-	fs := http.FileServer(http.Dir(s.staticDir))
-	router.Handle("/static/", http.StripPrefix("/static/", fs))
+	router.Handle("/static/", http.FileServer(http.FS(s.assets)))
 
 	// Web UI routes
 	router.HandleFunc("/", s.handleHome)
 	router.HandleFunc("/cameras", s.handleCameras)
 	router.HandleFunc("/alerts", s.handleAlerts)
+	router.HandleFunc("/alerts/{id}", s.handleAlertDetails)
+	router.HandleFunc("/incidents", s.handleIncidents)
+	router.HandleFunc("/incidents/{id}", s.handleIncidentDetails)
 	router.HandleFunc("/camera/", s.handleCameraDetails)
+	router.HandleFunc("/dashboard", s.handleDashboard)
+	router.HandleFunc("/reports/{period}", s.handleReport)
 
 	// API routes
-	router.HandleFunc("/api/cameras", s.handleAPIGetCameras)
-	router.HandleFunc("/api/alerts", s.handleAPIGetAlerts)
-	router.HandleFunc("/api/trigger", s.handleAPITriggerSnapshot)
+	s.registerAPIRoutes(router)
 
-	addr := fmt.Sprintf(":%s", s.config.ServerPort)
-	s.server = &http.Server{
-		Addr:         addr,
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
+	// Health routes
+	router.HandleFunc("/healthz", s.handleHealthz)
+	router.HandleFunc("/readyz", s.handleReadyz)
 
-	slog.Info("Starting HTTP server", "address", addr)
-	return s.server.ListenAndServe()
+	return router
 }
 
-// Stop stops the HTTP server
-func (s *HTTPServer) Stop() error {
+// Stop stops accepting new connections and waits for in-flight requests until the context expires
+func (s *HTTPServer) Stop(ctx context.Context) error {
 	if s.server != nil {
 		slog.Info("Stopping HTTP server")
-		return s.server.Close()
+		return s.server.Shutdown(ctx)
 	}
 	return nil
 }
@@ -104,23 +139,13 @@ func (s *HTTPServer) handleHome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tmpl, err := template.ParseFiles(
-		filepath.Join(s.templatesDir, "layout.html"),
-		filepath.Join(s.templatesDir, "home.html"),
-	)
-	if err != nil {
-		slog.Error("Failed to parse template", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-
 	data := struct {
 		Title string
 	}{
 		Title: "Frigate Alerter - Home",
 	}
 
-	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+	if err := s.templates.render(w, "home", data); err != nil {
 		slog.Error("Failed to render template", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
@@ -128,31 +153,45 @@ func (s *HTTPServer) handleHome(w http.ResponseWriter, r *http.Request) {
 
 // handleCameras handles the cameras page request
 func (s *HTTPServer) handleCameras(w http.ResponseWriter, r *http.Request) {
-	tmpl, err := template.ParseFiles(
-		filepath.Join(s.templatesDir, "layout.html"),
-		filepath.Join(s.templatesDir, "cameras.html"),
-	)
+	// Get camera info; render the page without cameras while Frigate is unavailable
+	cameras, err := s.frigateService.GetCameras()
+	errorMessage := ""
 	if err != nil {
-		slog.Error("Failed to parse template", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
+		slog.Error("Failed to get cameras", "error", err)
+		errorMessage = "Frigate is not reachable yet. Cameras will appear once it is available."
 	}
 
-	// Get camera info
-	cameras, err := s.frigateService.GetCameras()
+	// Active snoozes and the labels seen on each camera drive the snooze controls of the cards
+	snoozes, err := s.snoozeService.ActiveSnoozes("")
 	if err != nil {
-		slog.Error("Failed to get cameras", "error", err)
-		http.Error(w, "Failed to get cameras", http.StatusInternalServerError)
-		return
+		slog.Error("Failed to get snoozes", "error", err)
+	}
+	snoozesByCamera := make(map[string][]domain.Snooze)
+	for _, snooze := range snoozes {
+		snoozesByCamera[snooze.Camera] = append(snoozesByCamera[snooze.Camera], snooze)
+	}
+	stats, err := s.repository.GetCameraStats(domain.AlertFilter{})
+	if err != nil {
+		slog.Error("Failed to get alert statistics", "error", err)
+	}
+	labelsByCamera := make(map[string][]string)
+	for _, cameraStats := range stats {
+		for label := range cameraStats.ByLabel {
+			if label != "" {
+				labelsByCamera[cameraStats.Camera] = append(labelsByCamera[cameraStats.Camera], label)
+			}
+		}
+		sort.Strings(labelsByCamera[cameraStats.Camera])
 	}
 
-	// Create camera data structures with config info included
+	// Create camera data structures for the camera cards
+	sort.Strings(cameras)
 	cameraData := make([]map[string]interface{}, 0, len(cameras))
 	for _, camera := range cameras {
 		cameraData = append(cameraData, map[string]interface{}{
-			"Camera":        camera,
-			"FrigateServer": s.config.FrigateServer,
-			"FrigatePort":   s.config.FrigatePort,
+			"Camera":  camera,
+			"Snoozes": snoozesByCamera[camera],
+			"Labels":  labelsByCamera[camera],
 		})
 	}
 
@@ -160,13 +199,15 @@ func (s *HTTPServer) handleCameras(w http.ResponseWriter, r *http.Request) {
 		Title   string
 		Cameras []map[string]interface{}
 		Config  *config.Config
+		Error   string
 	}{
 		Title:   "Frigate Alerter - Cameras",
 		Cameras: cameraData,
 		Config:  s.config,
+		Error:   errorMessage,
 	}
 
-	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+	if err := s.templates.render(w, "cameras", data); err != nil {
 		slog.Error("Failed to render template", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
@@ -174,16 +215,6 @@ func (s *HTTPServer) handleCameras(w http.ResponseWriter, r *http.Request) {
 
 // handleAlerts handles the alerts page request
 func (s *HTTPServer) handleAlerts(w http.ResponseWriter, r *http.Request) {
-	tmpl, err := template.ParseFiles(
-		filepath.Join(s.templatesDir, "layout.html"),
-		filepath.Join(s.templatesDir, "alerts.html"),
-	)
-	if err != nil {
-		slog.Error("Failed to parse template", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-
 	// Parse query parameters
 	limit := 100
 	offset := 0
@@ -198,8 +229,20 @@ func (s *HTTPServer) handleAlerts(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Unknown severities are ignored like malformed paging parameters
+	severity := r.URL.Query().Get("severity")
+	if !domain.IsSeverity(severity) {
+		severity = ""
+	}
+
 	// Get alerts
-	alerts, err := s.repository.GetAlerts(limit, offset)
+	var alerts []*domain.Alert
+	var err error
+	if severity != "" {
+		alerts, err = s.repository.FindAlerts(domain.AlertFilter{Severity: severity, Limit: limit, Offset: offset})
+	} else {
+		alerts, err = s.repository.GetAlerts(limit, offset)
+	}
 	if err != nil {
 		slog.Error("Failed to get alerts", "error", err)
 		http.Error(w, "Failed to get alerts", http.StatusInternalServerError)
@@ -207,16 +250,18 @@ func (s *HTTPServer) handleAlerts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := struct {
-		Title  string
-		Alerts []*domain.Alert
-		Config *config.Config
+		Title    string
+		Alerts   []*domain.Alert
+		Severity string
+		Config   *config.Config
 	}{
-		Title:  "Frigate Alerter - Alerts",
-		Alerts: alerts,
-		Config: s.config,
+		Title:    "Frigate Alerter - Alerts",
+		Alerts:   alerts,
+		Severity: severity,
+		Config:   s.config,
 	}
 
-	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+	if err := s.templates.render(w, "alerts", data); err != nil {
 		slog.Error("Failed to render template", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
@@ -230,16 +275,6 @@ func (s *HTTPServer) handleCameraDetails(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	tmpl, err := template.ParseFiles(
-		filepath.Join(s.templatesDir, "layout.html"),
-		filepath.Join(s.templatesDir, "camera_details.html"),
-	)
-	if err != nil {
-		slog.Error("Failed to parse template", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-
 	// Parse query parameters
 	limit := 50
 	offset := 0
@@ -262,158 +297,54 @@ func (s *HTTPServer) handleCameraDetails(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Get the total alert count, which is not limited by the page size
+	stats, err := s.repository.GetCameraStats(domain.AlertFilter{Camera: cameraName})
+	if err != nil {
+		slog.Error("Failed to get alert statistics for camera", "error", err, "camera", cameraName)
+		http.Error(w, "Failed to get alerts", http.StatusInternalServerError)
+		return
+	}
+	alertCount := 0
+	if len(stats) > 0 {
+		alertCount = stats[0].Total
+	}
+
 	data := struct {
 		Title      string
 		CameraName string
 		Alerts     []*domain.Alert
+		AlertCount int
 		Config     *config.Config
 	}{
 		Title:      fmt.Sprintf("Frigate Alerter - %s Camera", cameraName),
 		CameraName: cameraName,
 		Alerts:     alerts,
+		AlertCount: alertCount,
 		Config:     s.config,
 	}
 
-	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+	if err := s.templates.render(w, "camera_details", data); err != nil {
 		slog.Error("Failed to render template", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
-// API Handlers
-
-// handleAPIGetCameras returns a list of cameras as JSON
-func (s *HTTPServer) handleAPIGetCameras(w http.ResponseWriter, r *http.Request) {
+// handleHealthz reports liveness; it only fails if the process cannot serve HTTP
+func (s *HTTPServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-
-	cameras, err := s.frigateService.GetCameras()
-	if err != nil {
-		slog.Error("Failed to get cameras", "error", err)
-		http.Error(w, `{"error":"Failed to get cameras"}`, http.StatusInternalServerError)
-		return
-	}
-
-	// Get alert counts for each camera
-	cameraInfos := make([]CameraInfo, 0, len(cameras))
-	for _, camera := range cameras {
-		alerts, err := s.repository.GetAlertsByCameraName(camera, 1, 0)
-		if err != nil {
-			slog.Error("Failed to get alert count for camera", "error", err, "camera", camera)
-			continue
-		}
-		cameraInfos = append(cameraInfos, CameraInfo{
-			Name:  camera,
-			Count: len(alerts),
-		})
-	}
-
-	if err := json.NewEncoder(w).Encode(cameraInfos); err != nil {
-		slog.Error("Failed to encode cameras", "error", err)
-		http.Error(w, `{"error":"Failed to encode cameras"}`, http.StatusInternalServerError)
-	}
+	w.Write([]byte(`{"status":"ok"}`))
 }
 
-// handleAPIGetAlerts returns alerts as JSON
-func (s *HTTPServer) handleAPIGetAlerts(w http.ResponseWriter, r *http.Request) {
+// handleReadyz reports readiness with the state of every dependency
+func (s *HTTPServer) handleReadyz(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Parse query parameters
-	limit := 100
-	offset := 0
-	camera := r.URL.Query().Get("camera")
-
-	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
-		if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
-		}
-	}
-	if offsetParam := r.URL.Query().Get("offset"); offsetParam != "" {
-		if parsedOffset, err := strconv.Atoi(offsetParam); err == nil && parsedOffset >= 0 {
-			offset = parsedOffset
-		}
-	}
-
-	var alerts []*domain.Alert
-	var err error
-
-	if camera != "" {
-		// Get alerts for a specific camera
-		alerts, err = s.repository.GetAlertsByCameraName(camera, limit, offset)
-	} else {
-		// Get all alerts
-		alerts, err = s.repository.GetAlerts(limit, offset)
+	report := s.healthService.Readiness(r.Context())
+	if !report.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
 	}
 
-	if err != nil {
-		slog.Error("Failed to get alerts", "error", err)
-		http.Error(w, `{"error":"Failed to get alerts"}`, http.StatusInternalServerError)
-		return
-	}
-
-	if err := json.NewEncoder(w).Encode(alerts); err != nil {
-		slog.Error("Failed to encode alerts", "error", err)
-		http.Error(w, `{"error":"Failed to encode alerts"}`, http.StatusInternalServerError)
-	}
-}
-
-// handleAPITriggerSnapshot handles requests to trigger a snapshot and send to Discord
-func (s *HTTPServer) handleAPITriggerSnapshot(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-	
-	w.Header().Set("Content-Type", "application/json")
-	
-	// Parse JSON request
-	var requestBody struct {
-		Camera string `json:"camera"`
-	}
-	
-	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		slog.Error("Failed to decode request body", "error", err)
-		http.Error(w, `{"success":false,"message":"Invalid request"}`, http.StatusBadRequest)
-		return
-	}
-	
-	if requestBody.Camera == "" {
-		http.Error(w, `{"success":false,"message":"Camera name is required"}`, http.StatusBadRequest)
-		return
-	}
-	
-	// Create manual alert
-	currentTime := time.Now().In(s.config.Location)
-	alertID := fmt.Sprintf("manual_%s_%d", requestBody.Camera, currentTime.UnixNano())
-	
-	alert := &domain.Alert{
-		ID:           alertID,
-		Type:         "manual",
-		CameraName:   requestBody.Camera,
-		TriggeredAt:  currentTime,
-		AlertMessage: fmt.Sprintf("Manual snapshot from %s camera", requestBody.Camera),
-	}
-	
-	// Save alert to database
-	if err := s.repository.SaveAlert(alert); err != nil {
-		slog.Error("Failed to save manual alert", "error", err, "camera", requestBody.Camera)
-		http.Error(w, `{"success":false,"message":"Failed to save alert"}`, http.StatusInternalServerError)
-		return
-	}
-	
-	// Send alert to Discord
-	if err := s.notifier.SendAlert(alert); err != nil {
-		slog.Error("Failed to send manual alert to Discord", "error", err, "camera", requestBody.Camera)
-		// Continue even if Discord notification fails
-	}
-	
-	response := AlertResponse{
-		Success: true,
-		Message: fmt.Sprintf("Manual snapshot from %s camera sent to Discord", requestBody.Camera),
-		AlertID: alertID,
-	}
-	
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		slog.Error("Failed to encode response", "error", err)
-		http.Error(w, `{"success":false,"message":"Failed to encode response"}`, http.StatusInternalServerError)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.Error("Failed to encode readiness report", "error", err)
 	}
 }
@@ -0,0 +1,175 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/api"
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// openAPIDocument is the part of the OpenAPI document the contract tests rely on
+type openAPIDocument struct {
+	Paths map[string]map[string]struct {
+		Responses map[string]json.RawMessage `json:"responses"`
+	} `json:"paths"`
+}
+
+// stubNotifier records alerts, incident messages, digests and reports and fails when err is set
+type stubNotifier struct {
+	err     error
+	sent    []*domain.Alert
+	updated []*domain.Alert
+	digests []*domain.Digest
+	reports []*domain.Report
+}
+
+func (n *stubNotifier) SendAlert(alert *domain.Alert) error {
+	n.sent = append(n.sent, alert)
+	return n.err
+}
+
+func (n *stubNotifier) SendIncident(incident *domain.Incident, alert *domain.Alert) (string, error) {
+	n.sent = append(n.sent, alert)
+	return "message-" + incident.ID, n.err
+}
+
+func (n *stubNotifier) UpdateIncident(incident *domain.Incident, alert *domain.Alert, messageID string) error {
+	n.updated = append(n.updated, alert)
+	return n.err
+}
+
+func (n *stubNotifier) SendDigest(digest *domain.Digest) error {
+	n.digests = append(n.digests, digest)
+	return n.err
+}
+
+func (n *stubNotifier) SendReport(report *domain.Report) error {
+	n.reports = append(n.reports, report)
+	return n.err
+}
+
+// testSnapshot is a small JPEG served by the fake Frigate API as the snapshot of event 1700000000.0-abc
+var testSnapshot = func() []byte {
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 64, 36)), nil)
+	return buf.Bytes()
+}()
+
+func loadOpenAPIDocument(t *testing.T) openAPIDocument {
+	t.Helper()
+	var doc openAPIDocument
+	if err := json.Unmarshal(api.OpenAPISpec, &doc); err != nil {
+		t.Fatalf("api/openapi.json is not valid JSON: %v", err)
+	}
+	return doc
+}
+
+// testBackend is what HTTP tests build servers on: a temporary SQLite database, a fake Frigate API and a notifier
+type testBackend struct {
+	config     *config.Config
+	repository *SQLiteAlertRepository
+	frigate    *FrigateService
+	notifier   *stubNotifier
+}
+
+// newTestBackend creates a test backend whose Frigate API knows the front_door and driveway cameras
+func newTestBackend(t *testing.T, notifier *stubNotifier) *testBackend {
+	t.Helper()
+
+	frigate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/config" {
+			w.Write([]byte(`{"cameras":{"front_door":{},"driveway":{}}}`))
+			return
+		}
+		if r.URL.Path == "/api/events/1700000000.0-abc/snapshot.jpg" {
+			w.Write(testSnapshot)
+			return
+		}
+		if r.URL.Path == "/api/events/1700000000.0-abc/false_positive" && r.Method == http.MethodPut {
+			w.Write([]byte(`{"success":true}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(frigate.Close)
+
+	frigateURL, _ := url.Parse(frigate.URL)
+	host, port, _ := net.SplitHostPort(frigateURL.Host)
+	cfg := &config.Config{
+		FrigateServer: host,
+		FrigatePort:   port,
+		Location:      time.UTC,
+
+		FrigateSyncFalsePositives: true,
+	}
+
+	repository, err := NewSQLiteAlertRepository(filepath.Join(t.TempDir(), "alerts.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	t.Cleanup(func() { repository.Close() })
+
+	return &testBackend{config: cfg, repository: repository, frigate: NewFrigateService(cfg), notifier: notifier}
+}
+
+// server creates an HTTP server with the services a test needs on the repository, Frigate and notifier of the backend
+func (b *testBackend) server(t *testing.T, deps HTTPServerDeps) *HTTPServer {
+	t.Helper()
+
+	deps.Repository = b.repository
+	deps.Notifier = b.notifier
+	deps.FrigateService = b.frigate
+	server, err := NewHTTPServer(deps, b.config)
+	if err != nil {
+		t.Fatalf("failed to create HTTP server: %v", err)
+	}
+	return server
+}
+
+// alertService creates an alert service for the current configuration of the backend, with fresh detectors.
+// Services a test shares with its HTTP server are passed in; the others are created.
+func (b *testBackend) alertService(deps application.AlertServiceDeps) *application.AlertService {
+	deps.Repository = b.repository
+	deps.Notifier = b.notifier
+	if deps.Snoozes == nil {
+		deps.Snoozes = application.NewSnoozeService(b.repository, b.config)
+	}
+	if deps.Modes == nil {
+		deps.Modes = application.NewModeService(b.repository, application.NewPresenceService(b.config), b.config)
+	}
+	if deps.Incidents == nil {
+		deps.Incidents = application.NewIncidentService(b.repository, b.repository, b.notifier, b.config)
+	}
+	if deps.Watchlist == nil {
+		deps.Watchlist = application.NewWatchlistService(b.repository, b.config)
+	}
+	if deps.Digests == nil {
+		deps.Digests = application.NewDigestService(b.repository, b.notifier, b.frigate, b.config)
+	}
+	deps.Loitering = application.NewLoiteringDetector(b.config)
+	deps.Sequences = application.NewZoneSequenceDetector(b.config)
+	return application.NewAlertService(deps, b.config)
+}
+
+func assertErrorEnvelope(t *testing.T, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	var envelope APIError
+	if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("error body is not JSON: %v: %s", err, rec.Body.String())
+	}
+	if envelope.Error.Code == "" || envelope.Error.Message == "" {
+		t.Errorf("error envelope is missing code or message: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,79 @@
+package adapters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// snoozeRequest is the body of a snooze request; either duration or until must be given
+type snoozeRequest struct {
+	Label    string    `json:"label"`
+	Duration string    `json:"duration"`
+	Until    time.Time `json:"until"`
+	User     string    `json:"user"`
+}
+
+// handleAPIGetSnoozes returns the active snoozes of a camera
+func (s *HTTPServer) handleAPIGetSnoozes(w http.ResponseWriter, r *http.Request) {
+	snoozes, err := s.snoozeService.ActiveSnoozes(r.PathValue("name"))
+	if err != nil {
+		slog.Error("Failed to get snoozes", "error", err, "camera", r.PathValue("name"))
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to get snoozes", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, snoozes)
+}
+
+// handleAPISnoozeCamera silences a camera, or one label of it, for a duration or until a point in time
+func (s *HTTPServer) handleAPISnoozeCamera(w http.ResponseWriter, r *http.Request) {
+	var request snoozeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", "Request body must be a JSON object", nil)
+		return
+	}
+
+	until := request.Until
+	switch {
+	case request.Duration != "" && !until.IsZero():
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", "Give either duration or until, not both", nil)
+		return
+	case request.Duration != "":
+		duration, err := time.ParseDuration(request.Duration)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("Invalid duration %q, use e.g. 30m or 1h", request.Duration), nil)
+			return
+		}
+		until = time.Now().Add(duration)
+	case until.IsZero():
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", "Either duration or until is required", nil)
+		return
+	}
+
+	snooze, err := s.snoozeService.Snooze(r.PathValue("name"), request.Label, until, request.User)
+	if errors.Is(err, domain.ErrInvalidSnooze) {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to snooze camera", "error", err, "camera", r.PathValue("name"))
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to snooze camera", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, snooze)
+}
+
+// handleAPIUnsnoozeCamera ends the snooze of a camera, or of the label given as query parameter
+func (s *HTTPServer) handleAPIUnsnoozeCamera(w http.ResponseWriter, r *http.Request) {
+	if err := s.snoozeService.Unsnooze(r.PathValue("name"), r.URL.Query().Get("label")); err != nil {
+		slog.Error("Failed to remove snooze", "error", err, "camera", r.PathValue("name"))
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to remove snooze", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
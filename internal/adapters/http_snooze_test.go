@@ -0,0 +1,83 @@
+package adapters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestAPISnoozeCamera(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+	notifier := &stubNotifier{}
+	backend := newTestBackend(t, notifier)
+	snoozes := application.NewSnoozeService(backend.repository, backend.config)
+	server := backend.server(t, HTTPServerDeps{SnoozeService: snoozes})
+
+	steps := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"snooze without expiry", http.MethodPost, "/cameras/front_door/snooze", `{}`, http.StatusBadRequest},
+		{"snooze with invalid duration", http.MethodPost, "/cameras/front_door/snooze", `{"duration":"soon"}`, http.StatusBadRequest},
+		{"snooze in the past", http.MethodPost, "/cameras/front_door/snooze", `{"duration":"-1h"}`, http.StatusBadRequest},
+		{"snooze label", http.MethodPost, "/cameras/front_door/snooze", `{"label":"person","duration":"1h","user":"alice"}`, http.StatusOK},
+		{"snooze camera", http.MethodPost, "/cameras/driveway/snooze", `{"duration":"1h"}`, http.StatusOK},
+		{"unsnooze camera", http.MethodDelete, "/cameras/driveway/snooze", "", http.StatusNoContent},
+		{"list snoozes", http.MethodGet, "/cameras/front_door/snooze", "", http.StatusOK},
+	}
+	for _, step := range steps {
+		req := httptest.NewRequest(step.method, apiVersionPrefix+step.path, strings.NewReader(step.body))
+		rec := httptest.NewRecorder()
+		server.routes().ServeHTTP(rec, req)
+
+		if rec.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d, body: %s", step.name, rec.Code, step.wantStatus, rec.Body.String())
+		}
+		if _, ok := doc.Paths["/cameras/{name}/snooze"][strings.ToLower(step.method)].Responses[strconv.Itoa(rec.Code)]; !ok {
+			t.Errorf("%s: status %d is not documented", step.name, rec.Code)
+		}
+		if rec.Code >= 400 {
+			assertErrorEnvelope(t, rec)
+		}
+	}
+
+	// Only person alerts of the front door are muted; they are still stored
+	alertService := backend.alertService(application.AlertServiceDeps{Snoozes: snoozes})
+	for i, event := range []domain.FrigateBefore{
+		{ID: "1", Camera: "front_door", Label: "person"},
+		{ID: "2", Camera: "front_door", Label: "car"},
+		{ID: "3", Camera: "driveway", Label: "person"},
+	} {
+		if err := alertService.ProcessEvent(&domain.FrigateEvent{Type: "new", Before: event}); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+	}
+
+	if len(notifier.sent) != 2 {
+		t.Errorf("sent %d notifications, want 2", len(notifier.sent))
+	}
+	alerts, err := backend.repository.FindAlerts(domain.AlertFilter{})
+	if err != nil {
+		t.Fatalf("failed to get alerts: %v", err)
+	}
+	muted := 0
+	for _, alert := range alerts {
+		if alert.SuppressedBy == domain.SuppressedBySnooze {
+			muted++
+			if alert.CameraName != "front_door" || alert.Label != "person" {
+				t.Errorf("alert of %s/%s was muted", alert.CameraName, alert.Label)
+			}
+		}
+	}
+	if len(alerts) != 3 || muted != 1 {
+		t.Errorf("stored %d alerts with %d muted, want 3 with 1 muted", len(alerts), muted)
+	}
+}
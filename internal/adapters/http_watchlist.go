@@ -0,0 +1,65 @@
+package adapters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// watchlistRequest is the body of a request putting a sub label or plate on a list
+type watchlistRequest struct {
+	List string `json:"list"`
+	Note string `json:"note"`
+	User string `json:"user"`
+}
+
+// handleAPIGetWatchlist returns every watchlist entry
+func (s *HTTPServer) handleAPIGetWatchlist(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.watchlistService.GetEntries()
+	if err != nil {
+		slog.Error("Failed to get watchlist", "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to get watchlist", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// handleAPISetWatchlistEntry puts a sub label or plate on the allowlist or denylist
+func (s *HTTPServer) handleAPISetWatchlistEntry(w http.ResponseWriter, r *http.Request) {
+	var request watchlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", "Request body must be a JSON object", nil)
+		return
+	}
+
+	entry, err := s.watchlistService.SetEntry(r.PathValue("kind"), r.PathValue("value"), request.List, request.Note, request.User)
+	if errors.Is(err, domain.ErrInvalidWatchlistEntry) {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to set watchlist entry", "error", err, "kind", r.PathValue("kind"), "value", r.PathValue("value"))
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to set watchlist entry", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// handleAPIRemoveWatchlistEntry takes a sub label or plate off its list
+func (s *HTTPServer) handleAPIRemoveWatchlistEntry(w http.ResponseWriter, r *http.Request) {
+	err := s.watchlistService.RemoveEntry(r.PathValue("kind"), r.PathValue("value"))
+	if errors.Is(err, domain.ErrWatchlistEntryNotFound) {
+		writeAPIError(w, http.StatusNotFound, "not_found", fmt.Sprintf("%s %s is on no watchlist", r.PathValue("kind"), r.PathValue("value")), nil)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to remove watchlist entry", "error", err, "kind", r.PathValue("kind"), "value", r.PathValue("value"))
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "Failed to remove watchlist entry", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
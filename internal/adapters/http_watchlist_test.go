@@ -0,0 +1,102 @@
+package adapters
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestAPIWatchlist(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+	notifier := &stubNotifier{}
+	backend := newTestBackend(t, notifier)
+	watchlist := application.NewWatchlistService(backend.repository, backend.config)
+	server := backend.server(t, HTTPServerDeps{WatchlistService: watchlist})
+
+	steps := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"allow face", http.MethodPut, "/watchlist/sub_label/Alice", `{"list":"allow","user":"bob"}`, http.StatusOK},
+		{"allow plate", http.MethodPut, "/watchlist/plate/ab-123-cd", `{"list":"allow","note":"family car"}`, http.StatusOK},
+		{"deny plate", http.MethodPut, "/watchlist/plate/XY987", `{"list":"deny","note":"reported by the neighbours"}`, http.StatusOK},
+		{"unknown list", http.MethodPut, "/watchlist/plate/XY987", `{"list":"maybe"}`, http.StatusBadRequest},
+		{"unknown kind", http.MethodPut, "/watchlist/face/Alice", `{"list":"allow"}`, http.StatusBadRequest},
+		{"remove plate", http.MethodDelete, "/watchlist/plate/AB%20123%20CD", "", http.StatusNoContent},
+		{"remove missing plate", http.MethodDelete, "/watchlist/plate/AB123CD", "", http.StatusNotFound},
+		{"list", http.MethodGet, "/watchlist", "", http.StatusOK},
+	}
+	for _, step := range steps {
+		req := httptest.NewRequest(step.method, apiVersionPrefix+step.path, strings.NewReader(step.body))
+		rec := httptest.NewRecorder()
+		server.routes().ServeHTTP(rec, req)
+
+		if rec.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d, body: %s", step.name, rec.Code, step.wantStatus, rec.Body.String())
+		}
+		path := "/watchlist/{kind}/{value}"
+		if step.method == http.MethodGet {
+			path = "/watchlist"
+		}
+		if _, ok := doc.Paths[path][strings.ToLower(step.method)].Responses[strconv.Itoa(rec.Code)]; !ok {
+			t.Errorf("%s: status %d is not documented", step.name, rec.Code)
+		}
+		if rec.Code >= 400 {
+			assertErrorEnvelope(t, rec)
+		}
+	}
+	entries, err := watchlist.GetEntries()
+	if err != nil {
+		t.Fatalf("failed to get watchlist: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Value != "XY987" || entries[1].Value != "alice" {
+		t.Fatalf("watchlist = %+v, want the denied plate and the allowed face", entries)
+	}
+
+	// Frigate sends the sub label of a recognized face as a [name, score] pair
+	var known domain.FrigateEvent
+	if err := json.Unmarshal([]byte(`{"type":"new","before":{"id":"1","camera":"front_door","label":"person","sub_label":["Alice",0.93]}}`), &known); err != nil {
+		t.Fatalf("invalid event: %v", err)
+	}
+	unread := domain.FrigateBefore{ID: "3", Camera: "driveway", Label: "car"}
+	read := domain.FrigateBefore{ID: "3", Camera: "driveway", Label: "car", RecognizedLicensePlate: "xy 987"}
+	alertService := backend.alertService(application.AlertServiceDeps{Watchlist: watchlist})
+	for i, event := range []*domain.FrigateEvent{
+		&known,
+		{Type: "new", Before: domain.FrigateBefore{ID: "2", Camera: "driveway", Label: "car", RecognizedLicensePlate: "XY-987"}},
+		{Type: "new", Before: unread},
+		{Type: "update", Before: unread, After: read},
+		{Type: "update", Before: read, After: read},
+	} {
+		if err := alertService.ProcessEvent(event); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+	}
+
+	// Alice is muted; the denied plate is critical, and raises a watchlist alert when it is read late
+	if len(notifier.sent) != 3 {
+		t.Fatalf("sent %d notifications, want 3", len(notifier.sent))
+	}
+	if notifier.sent[0].Severity != domain.SeverityCritical || notifier.sent[1].Severity == domain.SeverityCritical {
+		t.Errorf("severities = %s, %s, want the denied plate critical", notifier.sent[0].Severity, notifier.sent[1].Severity)
+	}
+	if late := notifier.sent[2]; late.Type != domain.AlertTypeWatchlist || late.EventID != "3" || late.Severity != domain.SeverityCritical {
+		t.Errorf("late plate raised a %s %s alert for event %s, want a critical watchlist alert for event 3", late.Severity, late.Type, late.EventID)
+	}
+	alerts, err := backend.repository.FindAlerts(domain.AlertFilter{Camera: "front_door"})
+	if err != nil {
+		t.Fatalf("failed to get alerts: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].SuppressedBy != domain.SuppressedByWatchlist {
+		t.Errorf("known face alerts = %+v, want one muted by the watchlist", alerts)
+	}
+}
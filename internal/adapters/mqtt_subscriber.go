@@ -1,9 +1,11 @@
 package adapters
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
@@ -12,50 +14,130 @@ import (
 
 // MQTTSubscriber implements the EventSubscriber interface
 type MQTTSubscriber struct {
-	client     mqtt.Client
-	topic      string
-	connected  bool
+	client mqtt.Client
+	topic  string
+	cancel context.CancelFunc
+
+	mu            sync.Mutex
+	state         string
+	handler       func(event *domain.FrigateEvent)
+	topics        map[string]func(topic string, payload []byte)
+	subscribedAt  time.Time
+	lastMessageAt time.Time
 }
 
-// NewMQTTSubscriber creates a new MQTT subscriber
-func NewMQTTSubscriber(brokerURL string) (*MQTTSubscriber, error) {
+// NewMQTTSubscriber creates a new MQTT subscriber.
+// The broker connection is established in the background and retried with backoff,
+// so the subscriber starts in the connecting state when the broker is unavailable.
+func NewMQTTSubscriber(brokerURL string) *MQTTSubscriber {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &MQTTSubscriber{
+		topic:  "frigate/events",
+		cancel: cancel,
+		state:  domain.StateConnecting,
+		topics: make(map[string]func(topic string, payload []byte)),
+	}
+
 	opts := mqtt.NewClientOptions().
 		AddBroker(brokerURL).
 		SetClientID(fmt.Sprintf("frigate-alerter-%d", time.Now().Unix())).
 		SetCleanSession(true).
 		SetAutoReconnect(true).
+		SetOnConnectHandler(m.onConnect).
 		SetConnectionLostHandler(func(client mqtt.Client, err error) {
 			slog.Error("MQTT connection lost", "error", err)
+			m.setState(domain.StateDisconnected)
 		}).
 		SetReconnectingHandler(func(client mqtt.Client, opts *mqtt.ClientOptions) {
 			slog.Info("MQTT attempting to reconnect")
+			m.setState(domain.StateConnecting)
 		})
 
-	client := mqtt.NewClient(opts)
-	if token := client.Connect(); token.Wait() && token.Error() != nil {
-		return nil, token.Error()
-	}
+	m.client = mqtt.NewClient(opts)
+
+	go func() {
+		err := retryWithBackoff(ctx, "mqtt", func() error {
+			token := m.client.Connect()
+			token.Wait()
+			return token.Error()
+		})
+		if err == nil {
+			slog.Info("Connected to MQTT broker", "broker", brokerURL)
+		}
+	}()
 
-	return &MQTTSubscriber{
-		client:    client,
-		topic:     "frigate/events",
-		connected: true,
-	}, nil
+	return m
 }
 
-// Subscribe starts listening for events on the MQTT topic
+// Subscribe registers the event handler; the topic is (re)subscribed every time the broker connection is established
 func (m *MQTTSubscriber) Subscribe(handler func(event *domain.FrigateEvent)) error {
-	if !m.connected {
-		return fmt.Errorf("MQTT client not connected")
+	m.mu.Lock()
+	m.handler = handler
+	connected := m.state == domain.StateConnected
+	m.mu.Unlock()
+
+	if connected {
+		return m.subscribe()
+	}
+
+	slog.Info("MQTT not connected yet, subscription deferred until connected", "topic", m.topic)
+	return nil
+}
+
+// SubscribeTopic registers a handler for the raw messages of another topic, such as a command topic.
+// Like the events topic, it is (re)subscribed every time the broker connection is established.
+func (m *MQTTSubscriber) SubscribeTopic(topic string, handler func(topic string, payload []byte)) error {
+	m.mu.Lock()
+	m.topics[topic] = handler
+	connected := m.state == domain.StateConnected
+	m.mu.Unlock()
+
+	if connected {
+		return m.subscribeTopic(topic, handler)
+	}
+
+	slog.Info("MQTT not connected yet, subscription deferred until connected", "topic", topic)
+	return nil
+}
+
+// onConnect marks the subscriber connected and restores the subscriptions
+func (m *MQTTSubscriber) onConnect(client mqtt.Client) {
+	m.setState(domain.StateConnected)
+
+	m.mu.Lock()
+	hasHandler := m.handler != nil
+	topics := make(map[string]func(topic string, payload []byte), len(m.topics))
+	for topic, handler := range m.topics {
+		topics[topic] = handler
+	}
+	m.mu.Unlock()
+
+	if hasHandler {
+		if err := m.subscribe(); err != nil {
+			slog.Error("Failed to subscribe to MQTT topic", "error", err, "topic", m.topic)
+		}
+	}
+	for topic, handler := range topics {
+		if err := m.subscribeTopic(topic, handler); err != nil {
+			slog.Error("Failed to subscribe to MQTT topic", "error", err, "topic", topic)
+		}
 	}
+}
 
+// subscribe subscribes to the events topic and forwards decoded events to the handler
+func (m *MQTTSubscriber) subscribe() error {
 	token := m.client.Subscribe(m.topic, 1, func(client mqtt.Client, msg mqtt.Message) {
+		m.mu.Lock()
+		m.lastMessageAt = time.Now()
+		handler := m.handler
+		m.mu.Unlock()
+
 		var event domain.FrigateEvent
 		if err := json.Unmarshal(msg.Payload(), &event); err != nil {
 			slog.Error("Error unmarshalling MQTT message", "error", err, "payload", string(msg.Payload()))
 			return
 		}
-		
+
 		handler(&event)
 	})
 
@@ -63,15 +145,76 @@ func (m *MQTTSubscriber) Subscribe(handler func(event *domain.FrigateEvent)) err
 		return token.Error()
 	}
 
+	m.mu.Lock()
+	m.subscribedAt = time.Now()
+	m.mu.Unlock()
+
 	slog.Info("Subscribed to MQTT topic", "topic", m.topic)
 	return nil
 }
 
-// Close disconnects from the MQTT broker
+// subscribeTopic subscribes to a topic and passes its messages to the handler as they are
+func (m *MQTTSubscriber) subscribeTopic(topic string, handler func(topic string, payload []byte)) error {
+	token := m.client.Subscribe(topic, 1, func(client mqtt.Client, msg mqtt.Message) {
+		handler(msg.Topic(), msg.Payload())
+	})
+	if token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+
+	slog.Info("Subscribed to MQTT topic", "topic", topic)
+	return nil
+}
+
+// setState records the connection state reported by health checks
+func (m *MQTTSubscriber) setState(state string) {
+	m.mu.Lock()
+	m.state = state
+	m.mu.Unlock()
+}
+
+// CheckHealth reports the broker connection state and when the last message arrived
+func (m *MQTTSubscriber) CheckHealth(ctx context.Context) domain.DependencyStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := domain.DependencyStatus{
+		Name:      "mqtt",
+		State:     m.state,
+		Healthy:   m.state == domain.StateConnected && m.client.IsConnectionOpen(),
+		CheckedAt: time.Now(),
+	}
+
+	switch {
+	case !m.lastMessageAt.IsZero():
+		lastMessageAt := m.lastMessageAt
+		status.LastMessageAt = &lastMessageAt
+	case !m.subscribedAt.IsZero():
+		// Measure silence from the moment we subscribed until the first message arrives
+		subscribedAt := m.subscribedAt
+		status.LastMessageAt = &subscribedAt
+		status.Detail = "no messages received since subscribing"
+	}
+
+	return status
+}
+
+// Close stops any pending connection attempts and disconnects from the MQTT broker
 func (m *MQTTSubscriber) Close() error {
-	if m.connected {
+	m.cancel()
+	if m.client.IsConnected() {
+		// Unsubscribe first so the broker stops delivering events while we disconnect
+		topics := []string{m.topic}
+		m.mu.Lock()
+		for topic := range m.topics {
+			topics = append(topics, topic)
+		}
+		m.mu.Unlock()
+		if token := m.client.Unsubscribe(topics...); !token.WaitTimeout(time.Second) || token.Error() != nil {
+			slog.Warn("Failed to unsubscribe from MQTT topics", "topics", topics, "error", token.Error())
+		}
 		m.client.Disconnect(250) // wait 250ms for the disconnect to complete
-		m.connected = false
 	}
+	m.setState(domain.StateDisconnected)
 	return nil
 }
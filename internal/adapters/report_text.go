@@ -0,0 +1,96 @@
+package adapters
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// reportTitle names a report and its period
+func reportTitle(report *domain.Report) string {
+	period := "Daily"
+	if report.Period == domain.ReportWeekly {
+		period = "Weekly"
+	}
+	return fmt.Sprintf("%s report %s – %s", period, report.From.Format("2006-01-02 15:04"), report.To.Format("2006-01-02 15:04"))
+}
+
+// reportText renders a report as plain text for the notifiers without rich formatting
+func reportText(report *domain.Report) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "Alerts: %d (%s, %d the period before)\n", report.Total, report.Change(), report.Previous)
+
+	if len(report.Cameras) > 0 {
+		text.WriteString("\nCameras:\n")
+		for _, camera := range report.Cameras {
+			fmt.Fprintf(&text, "  %s: %d (%s)", camera.Camera, camera.Total, camera.Change())
+			if labels := reportLabels(camera); labels != "" {
+				text.WriteString(" – " + labels)
+			}
+			text.WriteString("\n")
+		}
+	}
+
+	if hours := reportHours(report); hours != "" {
+		fmt.Fprintf(&text, "\nBusiest hours: %s\n", hours)
+	}
+
+	text.WriteString("\nHealth:")
+	if len(report.Outages) == 0 {
+		text.WriteString(" no outages\n")
+	} else {
+		text.WriteString("\n")
+		for _, outage := range report.Outages {
+			fmt.Fprintf(&text, "  %s\n", reportOutage(outage))
+		}
+	}
+	return text.String()
+}
+
+// reportLabels lists the alerts of a camera per label, most frequent first
+func reportLabels(camera domain.ReportCamera) string {
+	labels := make([]string, 0, len(camera.ByLabel))
+	for label := range camera.ByLabel {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if camera.ByLabel[labels[i]] != camera.ByLabel[labels[j]] {
+			return camera.ByLabel[labels[i]] > camera.ByLabel[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+
+	parts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		name := label
+		if name == "" {
+			name = "object"
+		}
+		parts = append(parts, fmt.Sprintf("%s %d", name, camera.ByLabel[label]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// reportHours lists the busiest hours of a report with their alert counts
+func reportHours(report *domain.Report) string {
+	parts := make([]string, 0, len(report.BusiestHours))
+	for _, hour := range report.BusiestHours {
+		parts = append(parts, fmt.Sprintf("%02d:00 (%d)", hour.Hour, hour.Count))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// reportOutage describes when a dependency was unhealthy
+func reportOutage(outage domain.HealthOutage) string {
+	until := "ongoing"
+	if outage.Ended != nil {
+		until = outage.Ended.Format("2006-01-02 15:04")
+	}
+	description := fmt.Sprintf("%s %s from %s to %s", outage.Dependency, outage.State, outage.Started.Format("2006-01-02 15:04"), until)
+	if outage.Detail != "" {
+		description += ": " + outage.Detail
+	}
+	return description
+}
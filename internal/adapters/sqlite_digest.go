@@ -0,0 +1,44 @@
+package adapters
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// GetDigestCursor retrieves when the latest alert of the last digest sent was raised, or the zero time if none was sent
+func (r *SQLiteAlertRepository) GetDigestCursor() (time.Time, error) {
+	var sentUntil string
+	err := r.db.QueryRow(`SELECT sent_until FROM digest_cursor WHERE id = 1`).Scan(&sentUntil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseTime(sentUntil)
+}
+
+// SaveDigestCursor records when the latest alert of a digest that was sent was raised
+func (r *SQLiteAlertRepository) SaveDigestCursor(sentUntil time.Time) error {
+	_, err := r.db.Exec(`INSERT OR REPLACE INTO digest_cursor (id, sent_until) VALUES (1, ?)`, sentUntil.In(r.location))
+	return err
+}
+
+// GetHeldAlerts retrieves the alerts held back for the digest that were raised after the given time, oldest first
+func (r *SQLiteAlertRepository) GetHeldAlerts(after time.Time) ([]*domain.Alert, error) {
+	rows, err := r.db.Query(
+		`SELECT `+alertColumns+` FROM alerts
+		 WHERE suppressed_by = ? AND julianday(triggered_at) > julianday(?)
+		 ORDER BY julianday(triggered_at)`,
+		domain.SuppressedByQuietHours, after.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanAlerts(rows)
+}
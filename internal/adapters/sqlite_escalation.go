@@ -0,0 +1,45 @@
+package adapters
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// GetUnacknowledgedAlerts retrieves the notified alerts since the given time that are neither acknowledged nor false positives, oldest first
+func (r *SQLiteAlertRepository) GetUnacknowledgedAlerts(since time.Time) ([]*domain.Alert, error) {
+	rows, err := r.db.Query(
+		`SELECT `+alertColumns+` FROM alerts
+		 WHERE julianday(triggered_at) >= julianday(?) AND acknowledged_at IS NULL AND false_positive = 0 AND suppressed_by = ''
+		 ORDER BY julianday(triggered_at)`,
+		since.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanAlerts(rows)
+}
+
+// SetEscalationLevel raises the escalation level of an unacknowledged alert and audits the step.
+// An alert that was acknowledged or already reached the level meanwhile is left alone.
+func (r *SQLiteAlertRepository) SetEscalationLevel(id string, level int, entry *domain.AuditEntry) error {
+	return r.withAudit(entry, func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			`UPDATE alerts SET escalation_level = ? WHERE id = ? AND escalation_level < ? AND acknowledged_at IS NULL`,
+			level, id, level,
+		)
+		if err != nil {
+			return err
+		}
+		if updated, err := result.RowsAffected(); err != nil || updated > 0 {
+			return err
+		}
+		if err := r.requireAlert(tx, id); err != nil {
+			return err
+		}
+		return errAlreadyRecorded
+	})
+}
@@ -0,0 +1,53 @@
+package adapters
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// SaveHealthOutage creates or updates an outage
+func (r *SQLiteAlertRepository) SaveHealthOutage(outage *domain.HealthOutage) error {
+	_, err := r.db.Exec(
+		`INSERT OR REPLACE INTO health_outages (id, dependency, state, detail, started_at, ended_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		outage.ID, outage.Dependency, outage.State, outage.Detail, outage.Started.In(r.location), r.nullableTime(outage.Ended),
+	)
+	return err
+}
+
+// GetHealthOutages retrieves the outages that overlap the time range, oldest first; ongoing outages overlap any later range.
+// Like snooze expiry, the range is compared in Go because stored timestamps carry the UTC offset they were written with.
+func (r *SQLiteAlertRepository) GetHealthOutages(from time.Time, to time.Time) ([]domain.HealthOutage, error) {
+	rows, err := r.db.Query(`SELECT id, dependency, state, detail, started_at, ended_at FROM health_outages ORDER BY started_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outages := []domain.HealthOutage{}
+	for rows.Next() {
+		var outage domain.HealthOutage
+		var started string
+		var ended sql.NullString
+		if err := rows.Scan(&outage.ID, &outage.Dependency, &outage.State, &outage.Detail, &started, &ended); err != nil {
+			return nil, err
+		}
+		if outage.Started, err = parseTime(started); err != nil {
+			return nil, err
+		}
+		if ended.Valid {
+			t, err := parseTime(ended.String)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse outage end %q: %w", ended.String, err)
+			}
+			outage.Ended = &t
+		}
+
+		if outage.Started.Before(to) && (outage.Ended == nil || !outage.Ended.Before(from)) {
+			outages = append(outages, outage)
+		}
+	}
+	return outages, rows.Err()
+}
@@ -0,0 +1,112 @@
+package adapters
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// incidentColumns lists the incident columns in the order scanIncident expects them
+const incidentColumns = `id, started_at, last_alert_at, cameras, labels, alert_count, message_id, severity`
+
+// SaveIncident creates or updates an incident; cameras and labels are stored as JSON arrays
+func (r *SQLiteAlertRepository) SaveIncident(incident *domain.Incident) error {
+	cameras, err := json.Marshal(incident.Cameras)
+	if err != nil {
+		return err
+	}
+	labels, err := json.Marshal(incident.Labels)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(
+		`INSERT OR REPLACE INTO incidents (`+incidentColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		incident.ID,
+		incident.StartedAt.In(r.location),
+		incident.LastAlertAt.In(r.location),
+		string(cameras),
+		string(labels),
+		incident.AlertCount,
+		incident.MessageID,
+		incident.Severity,
+	)
+	return err
+}
+
+// GetIncident retrieves a single incident by its ID
+func (r *SQLiteAlertRepository) GetIncident(id string) (*domain.Incident, error) {
+	row := r.db.QueryRow(`SELECT `+incidentColumns+` FROM incidents WHERE id = ?`, id)
+	incident, err := scanIncident(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrIncidentNotFound
+	}
+	return incident, err
+}
+
+// GetIncidents retrieves incidents, most recently active first
+func (r *SQLiteAlertRepository) GetIncidents(limit int, offset int) ([]*domain.Incident, error) {
+	return r.queryIncidents(
+		`SELECT `+incidentColumns+` FROM incidents ORDER BY julianday(last_alert_at) DESC LIMIT ? OFFSET ?`,
+		limit, offset,
+	)
+}
+
+// GetRecentIncidents retrieves the incidents with an alert at or after the given time, most recently active first
+func (r *SQLiteAlertRepository) GetRecentIncidents(since time.Time) ([]*domain.Incident, error) {
+	return r.queryIncidents(
+		`SELECT `+incidentColumns+` FROM incidents WHERE julianday(last_alert_at) >= julianday(?) ORDER BY julianday(last_alert_at) DESC`,
+		since.UTC(),
+	)
+}
+
+// queryIncidents runs a query selecting incidentColumns and scans every row
+func (r *SQLiteAlertRepository) queryIncidents(query string, args ...interface{}) ([]*domain.Incident, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	incidents := []*domain.Incident{}
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+		incidents = append(incidents, incident)
+	}
+	return incidents, rows.Err()
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanIncident scans the incident columns of a row
+func scanIncident(row rowScanner) (*domain.Incident, error) {
+	var incident domain.Incident
+	var startedAt, lastAlertAt, cameras, labels string
+	if err := row.Scan(&incident.ID, &startedAt, &lastAlertAt, &cameras, &labels, &incident.AlertCount, &incident.MessageID, &incident.Severity); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if incident.StartedAt, err = parseTime(startedAt); err != nil {
+		return nil, err
+	}
+	if incident.LastAlertAt, err = parseTime(lastAlertAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(cameras), &incident.Cameras); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(labels), &incident.Labels); err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
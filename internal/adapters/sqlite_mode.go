@@ -0,0 +1,51 @@
+package adapters
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// GetModeOverride retrieves the manually set mode, or nil if none is set
+func (r *SQLiteAlertRepository) GetModeOverride() (*domain.ModeOverride, error) {
+	var override domain.ModeOverride
+	var until sql.NullString
+	var setAt string
+	err := r.db.QueryRow(`SELECT mode, until, set_by, set_at FROM mode_override WHERE id = 1`).
+		Scan(&override.Mode, &until, &override.SetBy, &setAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if override.SetAt, err = parseTime(setAt); err != nil {
+		return nil, err
+	}
+	if until.Valid {
+		t, err := parseTime(until.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse mode expiry %q: %w", until.String, err)
+		}
+		override.Until = &t
+	}
+	return &override, nil
+}
+
+// SaveModeOverride sets the manual mode, replacing any previous one
+func (r *SQLiteAlertRepository) SaveModeOverride(override *domain.ModeOverride) error {
+	_, err := r.db.Exec(
+		`INSERT OR REPLACE INTO mode_override (id, mode, until, set_by, set_at) VALUES (1, ?, ?, ?, ?)`,
+		override.Mode, r.nullableTime(override.Until), override.SetBy, override.SetAt.In(r.location),
+	)
+	return err
+}
+
+// DeleteModeOverride clears the manual mode
+func (r *SQLiteAlertRepository) DeleteModeOverride() error {
+	_, err := r.db.Exec(`DELETE FROM mode_override`)
+	return err
+}
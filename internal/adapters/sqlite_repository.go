@@ -1,7 +1,9 @@
 package adapters
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log/slog"
 	"time"
 
@@ -9,6 +11,9 @@ import (
 	"github.com/vibin/frigate_alerter/internal/domain"
 )
 
+// alertColumns lists the alert columns in the order scanAlerts expects them
+const alertColumns = `id, type, camera_name, label, event_id, triggered_at, alert_message, acknowledged_by, acknowledged_at, false_positive, suppressed_by, mode, escalation_level, incident_id, severity`
+
 // SQLiteAlertRepository implements the AlertRepository interface using SQLite
 type SQLiteAlertRepository struct {
 	db       *sql.DB
@@ -49,8 +54,157 @@ func (r *SQLiteAlertRepository) initDB() error {
 			camera_name TEXT NOT NULL,
 			triggered_at TIMESTAMP NOT NULL,
 			alert_message TEXT NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS health_check (
+			id INTEGER PRIMARY KEY,
+			checked_at TIMESTAMP NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS snoozes (
+			camera_name TEXT NOT NULL,
+			label TEXT NOT NULL,
+			until TIMESTAMP NOT NULL,
+			created_by TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (camera_name, label)
+		);
+		CREATE TABLE IF NOT EXISTS mode_override (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			mode TEXT NOT NULL,
+			until TIMESTAMP,
+			set_by TEXT NOT NULL,
+			set_at TIMESTAMP NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS alert_notes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			alert_id TEXT NOT NULL,
+			author TEXT NOT NULL,
+			text TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS incidents (
+			id TEXT PRIMARY KEY,
+			started_at TIMESTAMP NOT NULL,
+			last_alert_at TIMESTAMP NOT NULL,
+			cameras TEXT NOT NULL,
+			labels TEXT NOT NULL,
+			alert_count INTEGER NOT NULL,
+			message_id TEXT NOT NULL DEFAULT ''
+		);
+		CREATE TABLE IF NOT EXISTS alert_audit (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			alert_id TEXT NOT NULL,
+			action TEXT NOT NULL,
+			actor TEXT NOT NULL,
+			detail TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS health_outages (
+			id TEXT PRIMARY KEY,
+			dependency TEXT NOT NULL,
+			state TEXT NOT NULL,
+			detail TEXT NOT NULL DEFAULT '',
+			started_at TIMESTAMP NOT NULL,
+			ended_at TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS watchlist (
+			kind TEXT NOT NULL,
+			value TEXT NOT NULL,
+			list TEXT NOT NULL,
+			note TEXT NOT NULL DEFAULT '',
+			created_by TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (kind, value)
+		);
+		CREATE TABLE IF NOT EXISTS digest_cursor (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			sent_until TIMESTAMP NOT NULL
 		)
 	`)
+	if err != nil {
+		return err
+	}
+
+	// Columns added after the first release; existing databases are upgraded in place
+	if err := r.addColumnIfMissing("alerts", "label", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := r.addColumnIfMissing("alerts", "event_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := r.addColumnIfMissing("alerts", "acknowledged_by", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := r.addColumnIfMissing("alerts", "acknowledged_at", "TIMESTAMP"); err != nil {
+		return err
+	}
+	if err := r.addColumnIfMissing("alerts", "false_positive", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := r.addColumnIfMissing("alerts", "suppressed_by", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := r.addColumnIfMissing("alerts", "mode", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := r.addColumnIfMissing("alerts", "escalation_level", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := r.addColumnIfMissing("alerts", "incident_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := r.addColumnIfMissing("alerts", "severity", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := r.addColumnIfMissing("incidents", "severity", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	// Timestamps keep the offset of the time zone they were raised in, so they are compared and sorted
+	// as julian days, which the time indexes are built on
+	_, err = r.db.Exec(`
+		DROP INDEX IF EXISTS idx_alerts_triggered_at;
+		DROP INDEX IF EXISTS idx_alerts_camera_triggered_at;
+		DROP INDEX IF EXISTS idx_incidents_last_alert_at;
+		CREATE INDEX IF NOT EXISTS idx_alerts_triggered_day ON alerts (julianday(triggered_at));
+		CREATE INDEX IF NOT EXISTS idx_alerts_camera_triggered_day ON alerts (camera_name, julianday(triggered_at));
+		CREATE INDEX IF NOT EXISTS idx_alerts_incident_id ON alerts (incident_id);
+		CREATE INDEX IF NOT EXISTS idx_incidents_last_alert_day ON incidents (julianday(last_alert_at));
+		CREATE INDEX IF NOT EXISTS idx_alert_notes_alert_id ON alert_notes (alert_id);
+		CREATE INDEX IF NOT EXISTS idx_alert_audit_alert_id ON alert_audit (alert_id)
+	`)
+	return err
+}
+
+// addColumnIfMissing adds a column to a table unless it already exists
+func (r *SQLiteAlertRepository) addColumnIfMissing(table, column, definition string) error {
+	rows, err := r.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name         string
+			columnType   string
+			notNull      int
+			defaultValue sql.NullString
+			primaryKey   int
+		)
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	slog.Info("Migrating database schema", "table", table, "column", column)
+	_, err = r.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
 	return err
 }
 
@@ -59,13 +213,23 @@ func (r *SQLiteAlertRepository) SaveAlert(alert *domain.Alert) error {
 	slog.Debug("Saving alert to database", "alert_id", alert.ID, "camera", alert.CameraName)
 	
 	_, err := r.db.Exec(
-		`INSERT INTO alerts (id, type, camera_name, triggered_at, alert_message) 
-		 VALUES (?, ?, ?, ?, ?)`,
+		`INSERT INTO alerts (`+alertColumns+`) 
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		alert.ID,
 		alert.Type,
 		alert.CameraName,
+		alert.Label,
+		alert.EventID,
 		alert.TriggeredAt.In(r.location),
 		alert.AlertMessage,
+		alert.AcknowledgedBy,
+		r.nullableTime(alert.AcknowledgedAt),
+		alert.FalsePositive,
+		alert.SuppressedBy,
+		alert.Mode,
+		alert.EscalationLevel,
+		alert.IncidentID,
+		alert.Severity,
 	)
 	
 	if err != nil {
@@ -77,12 +241,20 @@ func (r *SQLiteAlertRepository) SaveAlert(alert *domain.Alert) error {
 	return nil
 }
 
+// nullableTime converts an optional timestamp to the repository location, or to NULL when it is unset
+func (r *SQLiteAlertRepository) nullableTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.In(r.location)
+}
+
 // GetAlerts retrieves alerts based on optional filters
 func (r *SQLiteAlertRepository) GetAlerts(limit int, offset int) ([]*domain.Alert, error) {
 	rows, err := r.db.Query(
-		`SELECT id, type, camera_name, triggered_at, alert_message 
+		`SELECT `+alertColumns+` 
 		 FROM alerts 
-		 ORDER BY triggered_at DESC 
+		 ORDER BY julianday(triggered_at) DESC 
 		 LIMIT ? OFFSET ?`,
 		limit, offset,
 	)
@@ -97,10 +269,10 @@ func (r *SQLiteAlertRepository) GetAlerts(limit int, offset int) ([]*domain.Aler
 // GetAlertsByCameraName retrieves alerts for a specific camera
 func (r *SQLiteAlertRepository) GetAlertsByCameraName(cameraName string, limit int, offset int) ([]*domain.Alert, error) {
 	rows, err := r.db.Query(
-		`SELECT id, type, camera_name, triggered_at, alert_message 
+		`SELECT `+alertColumns+` 
 		 FROM alerts 
 		 WHERE camera_name = ? 
-		 ORDER BY triggered_at DESC 
+		 ORDER BY julianday(triggered_at) DESC 
 		 LIMIT ? OFFSET ?`,
 		cameraName, limit, offset,
 	)
@@ -112,33 +284,100 @@ func (r *SQLiteAlertRepository) GetAlertsByCameraName(cameraName string, limit i
 	return r.scanAlerts(rows)
 }
 
-// scanAlerts scans rows into alert objects
-func (r *SQLiteAlertRepository) scanAlerts(rows *sql.Rows) ([]*domain.Alert, error) {
+// FindAlerts retrieves alerts matching the filter, newest first
+func (r *SQLiteAlertRepository) FindAlerts(filter domain.AlertFilter) ([]*domain.Alert, error) {
+	where, args := r.filterClause(filter)
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1 // SQLite treats a negative limit as no limit
+	}
+	args = append(args, limit, filter.Offset)
+
+	rows, err := r.db.Query(
+		`SELECT `+alertColumns+` 
+		 FROM alerts`+where+` 
+		 ORDER BY julianday(triggered_at) DESC 
+		 LIMIT ? OFFSET ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanAlerts(rows)
+}
+
+// StreamAlerts calls fn for every alert matching the filter, oldest first.
+// Alerts are read in pages so the database is not kept locked while fn does slow work such as network I/O.
+func (r *SQLiteAlertRepository) StreamAlerts(ctx context.Context, filter domain.AlertFilter, fn func(alert *domain.Alert) error) error {
+	where, args := r.filterClause(filter)
+	if where == "" {
+		where = " WHERE rowid > ?"
+	} else {
+		where += " AND rowid > ?"
+	}
+
+	// Alerts are inserted as they trigger, so rowid order is chronological and a stable cursor
+	var lastRowID int64
+	for {
+		page, rowIDs, err := r.streamPage(ctx, where, append(args, lastRowID))
+		if err != nil {
+			return err
+		}
+		for _, alert := range page {
+			if err := fn(alert); err != nil {
+				return err
+			}
+		}
+		if len(page) < streamPageSize {
+			return nil
+		}
+		lastRowID = rowIDs[len(rowIDs)-1]
+	}
+}
+
+// streamPageSize is the number of alerts StreamAlerts reads per query
+const streamPageSize = 500
+
+// streamPage reads one page of StreamAlerts along with the rowids of the alerts
+func (r *SQLiteAlertRepository) streamPage(ctx context.Context, where string, args []interface{}) ([]*domain.Alert, []int64, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+alertColumns+`, rowid 
+		 FROM alerts`+where+` 
+		 ORDER BY rowid 
+		 LIMIT `+fmt.Sprint(streamPageSize),
+		args...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
 	var alerts []*domain.Alert
+	var rowIDs []int64
 	for rows.Next() {
-		var alert domain.Alert
-		var triggeredAt string
-		
-		err := rows.Scan(
-			&alert.ID,
-			&alert.Type,
-			&alert.CameraName,
-			&triggeredAt,
-			&alert.AlertMessage,
-		)
+		var rowID int64
+		alert, err := scanAlert(rows, &rowID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		
-		// Parse the timestamp - try multiple formats to handle different database outputs
-		t, err := parseTime(triggeredAt)
+		alerts = append(alerts, alert)
+		rowIDs = append(rowIDs, rowID)
+	}
+
+	return alerts, rowIDs, rows.Err()
+}
+
+// scanAlerts scans rows into alert objects
+func (r *SQLiteAlertRepository) scanAlerts(rows *sql.Rows) ([]*domain.Alert, error) {
+	var alerts []*domain.Alert
+	for rows.Next() {
+		alert, err := scanAlert(rows)
 		if err != nil {
-			slog.Error("Failed to parse timestamp", "timestamp", triggeredAt, "error", err)
 			return nil, err
 		}
-		alert.TriggeredAt = t
-		
-		alerts = append(alerts, &alert)
+		alerts = append(alerts, alert)
 	}
 	
 	if err := rows.Err(); err != nil {
@@ -148,6 +387,52 @@ func (r *SQLiteAlertRepository) scanAlerts(rows *sql.Rows) ([]*domain.Alert, err
 	return alerts, nil
 }
 
+// scanAlert scans the alert columns of the current row, followed by any extra columns into extra
+func scanAlert(rows *sql.Rows, extra ...interface{}) (*domain.Alert, error) {
+	var alert domain.Alert
+	var triggeredAt string
+	var acknowledgedAt sql.NullString
+
+	dest := []interface{}{
+		&alert.ID,
+		&alert.Type,
+		&alert.CameraName,
+		&alert.Label,
+		&alert.EventID,
+		&triggeredAt,
+		&alert.AlertMessage,
+		&alert.AcknowledgedBy,
+		&acknowledgedAt,
+		&alert.FalsePositive,
+		&alert.SuppressedBy,
+		&alert.Mode,
+		&alert.EscalationLevel,
+		&alert.IncidentID,
+		&alert.Severity,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	// Parse the timestamp - try multiple formats to handle different database outputs
+	t, err := parseTime(triggeredAt)
+	if err != nil {
+		slog.Error("Failed to parse timestamp", "timestamp", triggeredAt, "error", err)
+		return nil, err
+	}
+	alert.TriggeredAt = t
+
+	if acknowledgedAt.Valid {
+		t, err := parseTime(acknowledgedAt.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse acknowledgement time %q: %w", acknowledgedAt.String, err)
+		}
+		alert.AcknowledgedAt = &t
+	}
+
+	return &alert, nil
+}
+
 // parseTime attempts to parse a timestamp string in multiple formats
 func parseTime(timeStr string) (time.Time, error) {
 	// Try different time formats
@@ -174,6 +459,29 @@ func parseTime(timeStr string) (time.Time, error) {
 	return time.Time{}, firstErr
 }
 
+// CheckHealth reports whether the database accepts writes
+func (r *SQLiteAlertRepository) CheckHealth(ctx context.Context) domain.DependencyStatus {
+	now := time.Now()
+	status := domain.DependencyStatus{
+		Name:      "sqlite",
+		State:     domain.StateReadOnly,
+		CheckedAt: now,
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO health_check (id, checked_at) VALUES (1, ?)`,
+		now.In(r.location),
+	)
+	if err != nil {
+		status.Detail = err.Error()
+		return status
+	}
+
+	status.Healthy = true
+	status.State = domain.StateConnected
+	return status
+}
+
 // Close closes the database connection
 func (r *SQLiteAlertRepository) Close() error {
 	slog.Info("Closing SQLite database connection")
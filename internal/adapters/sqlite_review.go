@@ -0,0 +1,198 @@
+package adapters
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// GetAlert retrieves a single alert by its ID
+func (r *SQLiteAlertRepository) GetAlert(id string) (*domain.Alert, error) {
+	rows, err := r.db.Query(`SELECT `+alertColumns+` FROM alerts WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts, err := r.scanAlerts(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, domain.ErrAlertNotFound
+	}
+	return alerts[0], nil
+}
+
+// AcknowledgeAlert records who acknowledged an alert; an alert that is already acknowledged keeps its first acknowledgement
+func (r *SQLiteAlertRepository) AcknowledgeAlert(id string, actor string, at time.Time) error {
+	return r.withAudit(&domain.AuditEntry{AlertID: id, Action: domain.AuditAcknowledged, Actor: actor, CreatedAt: at}, func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			`UPDATE alerts SET acknowledged_by = ?, acknowledged_at = ? WHERE id = ? AND acknowledged_at IS NULL`,
+			actor, at.In(r.location), id,
+		)
+		if err != nil {
+			return err
+		}
+		if updated, err := result.RowsAffected(); err != nil || updated > 0 {
+			return err
+		}
+		if err := r.requireAlert(tx, id); err != nil {
+			return err
+		}
+		return errAlreadyRecorded
+	})
+}
+
+// AddAlertNote attaches a note to an alert and sets the note's ID
+func (r *SQLiteAlertRepository) AddAlertNote(note *domain.AlertNote) error {
+	entry := &domain.AuditEntry{
+		AlertID:   note.AlertID,
+		Action:    domain.AuditNoteAdded,
+		Actor:     note.Author,
+		Detail:    note.Text,
+		CreatedAt: note.CreatedAt,
+	}
+	return r.withAudit(entry, func(tx *sql.Tx) error {
+		if err := r.requireAlert(tx, note.AlertID); err != nil {
+			return err
+		}
+		result, err := tx.Exec(
+			`INSERT INTO alert_notes (alert_id, author, text, created_at) VALUES (?, ?, ?, ?)`,
+			note.AlertID, note.Author, note.Text, note.CreatedAt.In(r.location),
+		)
+		if err != nil {
+			return err
+		}
+		note.ID, err = result.LastInsertId()
+		return err
+	})
+}
+
+// SetFalsePositive flags or unflags an alert as a false positive; setting the current value again is not audited
+func (r *SQLiteAlertRepository) SetFalsePositive(id string, actor string, falsePositive bool, at time.Time) error {
+	action := domain.AuditMarkedFalsePositive
+	if !falsePositive {
+		action = domain.AuditClearedFalsePositive
+	}
+	return r.withAudit(&domain.AuditEntry{AlertID: id, Action: action, Actor: actor, CreatedAt: at}, func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			`UPDATE alerts SET false_positive = ? WHERE id = ? AND false_positive != ?`,
+			falsePositive, id, falsePositive,
+		)
+		if err != nil {
+			return err
+		}
+		if updated, err := result.RowsAffected(); err != nil || updated > 0 {
+			return err
+		}
+		if err := r.requireAlert(tx, id); err != nil {
+			return err
+		}
+		return errAlreadyRecorded
+	})
+}
+
+// AddAuditEntry records an action on an alert that did not change the alert itself
+func (r *SQLiteAlertRepository) AddAuditEntry(entry *domain.AuditEntry) error {
+	return r.withAudit(entry, func(tx *sql.Tx) error {
+		return r.requireAlert(tx, entry.AlertID)
+	})
+}
+
+// GetAlertNotes retrieves the notes of an alert, oldest first
+func (r *SQLiteAlertRepository) GetAlertNotes(id string) ([]domain.AlertNote, error) {
+	rows, err := r.db.Query(
+		`SELECT id, alert_id, author, text, created_at FROM alert_notes WHERE alert_id = ? ORDER BY id`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []domain.AlertNote{}
+	for rows.Next() {
+		var note domain.AlertNote
+		var createdAt string
+		if err := rows.Scan(&note.ID, &note.AlertID, &note.Author, &note.Text, &createdAt); err != nil {
+			return nil, err
+		}
+		if note.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+	return notes, rows.Err()
+}
+
+// GetAlertAudit retrieves the audit trail of an alert, oldest first
+func (r *SQLiteAlertRepository) GetAlertAudit(id string) ([]domain.AuditEntry, error) {
+	rows, err := r.db.Query(
+		`SELECT id, alert_id, action, actor, detail, created_at FROM alert_audit WHERE alert_id = ? ORDER BY id`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.AuditEntry{}
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var createdAt string
+		if err := rows.Scan(&entry.ID, &entry.AlertID, &entry.Action, &entry.Actor, &entry.Detail, &createdAt); err != nil {
+			return nil, err
+		}
+		if entry.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// errAlreadyRecorded tells withAudit that a change was a no-op and must not be audited
+var errAlreadyRecorded = errors.New("already recorded")
+
+// withAudit runs change in a transaction and records the audit entry in the same transaction,
+// so an alert is never changed without a trace
+func (r *SQLiteAlertRepository) withAudit(entry *domain.AuditEntry, change func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := change(tx); err != nil {
+		if errors.Is(err, errAlreadyRecorded) {
+			return nil
+		}
+		return err
+	}
+
+	result, err := tx.Exec(
+		`INSERT INTO alert_audit (alert_id, action, actor, detail, created_at) VALUES (?, ?, ?, ?, ?)`,
+		entry.AlertID, entry.Action, entry.Actor, entry.Detail, entry.CreatedAt.In(r.location),
+	)
+	if err != nil {
+		return err
+	}
+	if entry.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// requireAlert returns domain.ErrAlertNotFound unless the alert exists
+func (r *SQLiteAlertRepository) requireAlert(tx *sql.Tx, id string) error {
+	var exists int
+	err := tx.QueryRow(`SELECT 1 FROM alerts WHERE id = ?`, id).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrAlertNotFound
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package adapters
+
+import (
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// SaveSnooze creates or replaces the snooze of a camera and label, and prunes expired snoozes
+func (r *SQLiteAlertRepository) SaveSnooze(snooze *domain.Snooze) error {
+	_, err := r.db.Exec(
+		`INSERT OR REPLACE INTO snoozes (camera_name, label, until, created_by, created_at) VALUES (?, ?, ?, ?, ?)`,
+		snooze.Camera, snooze.Label, snooze.Until.In(r.location), snooze.CreatedBy, snooze.CreatedAt.In(r.location),
+	)
+	if err != nil {
+		return err
+	}
+
+	snoozes, err := r.loadSnoozes()
+	if err != nil {
+		return err
+	}
+	for _, s := range snoozes {
+		if !snooze.CreatedAt.Before(s.Until) {
+			if err := r.DeleteSnooze(s.Camera, s.Label); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteSnooze removes the snooze of a camera and label, if any
+func (r *SQLiteAlertRepository) DeleteSnooze(camera string, label string) error {
+	_, err := r.db.Exec(`DELETE FROM snoozes WHERE camera_name = ? AND label = ?`, camera, label)
+	return err
+}
+
+// GetActiveSnoozes retrieves the snoozes that have not expired at the given time.
+// Expiry is compared in Go because stored timestamps carry the UTC offset they were written with,
+// which changes with daylight saving time and breaks string comparison in SQL.
+func (r *SQLiteAlertRepository) GetActiveSnoozes(now time.Time) ([]domain.Snooze, error) {
+	snoozes, err := r.loadSnoozes()
+	if err != nil {
+		return nil, err
+	}
+
+	active := []domain.Snooze{}
+	for _, snooze := range snoozes {
+		if now.Before(snooze.Until) {
+			active = append(active, snooze)
+		}
+	}
+	return active, nil
+}
+
+// loadSnoozes reads every stored snooze, including expired ones
+func (r *SQLiteAlertRepository) loadSnoozes() ([]domain.Snooze, error) {
+	rows, err := r.db.Query(`SELECT camera_name, label, until, created_by, created_at FROM snoozes ORDER BY camera_name, label`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snoozes []domain.Snooze
+	for rows.Next() {
+		var snooze domain.Snooze
+		var until, createdAt string
+		if err := rows.Scan(&snooze.Camera, &snooze.Label, &until, &snooze.CreatedBy, &createdAt); err != nil {
+			return nil, err
+		}
+		if snooze.Until, err = parseTime(until); err != nil {
+			return nil, err
+		}
+		if snooze.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		snoozes = append(snoozes, snooze)
+	}
+	return snoozes, rows.Err()
+}
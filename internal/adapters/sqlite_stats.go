@@ -0,0 +1,235 @@
+package adapters
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// filterClause builds the WHERE clause and arguments for an alert filter.
+// Stored timestamps carry the UTC offset they were written with, which changes with daylight saving time,
+// so range bounds are compared as julian days rather than as strings.
+func (r *SQLiteAlertRepository) filterClause(filter domain.AlertFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Camera != "" {
+		conditions = append(conditions, "camera_name = ?")
+		args = append(args, filter.Camera)
+	}
+	if filter.Label != "" {
+		conditions = append(conditions, "label = ?")
+		args = append(args, filter.Label)
+	}
+	if filter.Type != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, filter.Type)
+	}
+	if filter.Severity != "" {
+		conditions = append(conditions, "severity = ?")
+		args = append(args, filter.Severity)
+	}
+	if filter.Incident != "" {
+		conditions = append(conditions, "incident_id = ?")
+		args = append(args, filter.Incident)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "julianday(triggered_at) >= julianday(?)")
+		args = append(args, filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "julianday(triggered_at) < julianday(?)")
+		args = append(args, filter.To.UTC())
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// GetAlertStats aggregates alert counts per camera, label and type, bucketed by hour and by day
+func (r *SQLiteAlertRepository) GetAlertStats(filter domain.AlertFilter) (*domain.AlertStats, error) {
+	stats := &domain.AlertStats{}
+	if !filter.From.IsZero() {
+		stats.From = &filter.From
+	}
+	if !filter.To.IsZero() {
+		stats.To = &filter.To
+	}
+
+	var err error
+	if stats.Cameras, err = r.GetCameraStats(filter); err != nil {
+		return nil, err
+	}
+	for _, cameraStats := range stats.Cameras {
+		stats.Total += cameraStats.Total
+	}
+
+	// The stored timestamps start with the local date and hour, so prefixes identify the buckets
+	if stats.Hourly, err = r.loadBuckets(filter, 13, "2006-01-02 15"); err != nil {
+		return nil, err
+	}
+	if stats.Daily, err = r.loadBuckets(filter, 10, "2006-01-02"); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetCameraStats returns the per-camera totals, breakdowns and last alert times
+func (r *SQLiteAlertRepository) GetCameraStats(filter domain.AlertFilter) ([]domain.CameraStats, error) {
+	where, args := r.filterClause(filter)
+	rows, err := r.db.Query(
+		`SELECT camera_name, label, type, COUNT(*), MAX(triggered_at)
+		 FROM alerts`+where+`
+		 GROUP BY camera_name, label, type`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byCamera := make(map[string]*domain.CameraStats)
+	for rows.Next() {
+		var camera, label, alertType, lastAlert string
+		var count int
+		if err := rows.Scan(&camera, &label, &alertType, &count, &lastAlert); err != nil {
+			return nil, err
+		}
+
+		lastAlertAt, err := parseTime(lastAlert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse last alert time %q: %w", lastAlert, err)
+		}
+
+		cameraStats, ok := byCamera[camera]
+		if !ok {
+			cameraStats = &domain.CameraStats{
+				Camera:  camera,
+				ByLabel: make(map[string]int),
+				ByType:  make(map[string]int),
+			}
+			byCamera[camera] = cameraStats
+		}
+
+		cameraStats.Total += count
+		cameraStats.ByLabel[label] += count
+		cameraStats.ByType[alertType] += count
+		if cameraStats.LastAlertAt == nil || lastAlertAt.After(*cameraStats.LastAlertAt) {
+			cameraStats.LastAlertAt = &lastAlertAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	cameras := make([]domain.CameraStats, 0, len(byCamera))
+	for _, cameraStats := range byCamera {
+		cameras = append(cameras, *cameraStats)
+	}
+	sort.Slice(cameras, func(i, j int) bool {
+		return cameras[i].Camera < cameras[j].Camera
+	})
+	return cameras, nil
+}
+
+// GetFirstAlertTimes returns the time of the earliest alert of each camera
+func (r *SQLiteAlertRepository) GetFirstAlertTimes(filter domain.AlertFilter) (map[string]time.Time, error) {
+	where, args := r.filterClause(filter)
+	// With MIN, SQLite takes the bare triggered_at from the earliest row of each camera
+	rows, err := r.db.Query(
+		`SELECT camera_name, triggered_at, MIN(julianday(triggered_at))
+		 FROM alerts`+where+`
+		 GROUP BY camera_name`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	firstAlerts := make(map[string]time.Time)
+	for rows.Next() {
+		var camera, firstAlert string
+		var day float64
+		if err := rows.Scan(&camera, &firstAlert, &day); err != nil {
+			return nil, err
+		}
+		if firstAlerts[camera], err = parseTime(firstAlert); err != nil {
+			return nil, fmt.Errorf("failed to parse first alert time %q: %w", firstAlert, err)
+		}
+	}
+
+	return firstAlerts, rows.Err()
+}
+
+// loadBuckets counts alerts grouped by a prefix of the stored local timestamp
+func (r *SQLiteAlertRepository) loadBuckets(filter domain.AlertFilter, prefixLength int, layout string) ([]domain.StatsBucket, error) {
+	where, args := r.filterClause(filter)
+	rows, err := r.db.Query(
+		fmt.Sprintf(`SELECT substr(triggered_at, 1, %d) AS bucket, camera_name, label, type, COUNT(*)
+		 FROM alerts`+where+`
+		 GROUP BY bucket, camera_name, label, type
+		 ORDER BY bucket, camera_name, label, type`, prefixLength),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []domain.StatsBucket{}
+	for rows.Next() {
+		var bucket domain.StatsBucket
+		var start string
+		if err := rows.Scan(&start, &bucket.Camera, &bucket.Label, &bucket.Type, &bucket.Count); err != nil {
+			return nil, err
+		}
+
+		// Older rows may have been written in ISO format with a "T" separator
+		bucket.Start, err = time.ParseInLocation(layout, strings.Replace(start, "T", " ", 1), r.location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse bucket %q: %w", start, err)
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// GetActivityHeatmap counts alerts per camera by local weekday and hour of day
+func (r *SQLiteAlertRepository) GetActivityHeatmap(filter domain.AlertFilter) ([]domain.HeatmapCell, error) {
+	where, args := r.filterClause(filter)
+	// The first 19 characters hold the local date and time without the offset, so SQLite
+	// derives the weekday in the configured time zone rather than in UTC
+	rows, err := r.db.Query(
+		`SELECT camera_name,
+		        CAST(strftime('%w', substr(triggered_at, 1, 19)) AS INTEGER) AS weekday,
+		        CAST(substr(triggered_at, 12, 2) AS INTEGER) AS hour,
+		        COUNT(*)
+		 FROM alerts`+where+`
+		 GROUP BY camera_name, weekday, hour
+		 ORDER BY camera_name, weekday, hour`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cells := []domain.HeatmapCell{}
+	for rows.Next() {
+		var cell domain.HeatmapCell
+		if err := rows.Scan(&cell.Camera, &cell.Weekday, &cell.Hour, &cell.Count); err != nil {
+			return nil, err
+		}
+		cells = append(cells, cell)
+	}
+
+	return cells, rows.Err()
+}
@@ -0,0 +1,126 @@
+package adapters
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestFindAlertsAcrossDaylightSavingTime(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database not available:", err)
+	}
+	repository, err := NewSQLiteAlertRepository(filepath.Join(t.TempDir(), "alerts.db"), location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repository.Close()
+
+	// Daylight saving time ends at 2:00 EDT on 1 November 2026, so 1:00 to 2:00 local time happens twice:
+	// 01:30-04:00 is stored before 01:10-05:00 but sorts after it as a string
+	for id, at := range map[string]string{
+		"edt": "2026-11-01T01:30:00-04:00",
+		"est": "2026-11-01T01:10:00-05:00",
+	} {
+		triggeredAt, _ := time.Parse(time.RFC3339, at)
+		if err := repository.SaveAlert(&domain.Alert{ID: id, Type: "object", CameraName: "driveway", Label: "person", TriggeredAt: triggeredAt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     []string
+	}{
+		{"from", "2026-11-01T06:00:00Z", "", []string{"est"}},
+		{"to", "", "2026-11-01T06:00:00Z", []string{"edt"}},
+		{"both", "2026-11-01T05:00:00Z", "2026-11-01T07:00:00Z", []string{"est", "edt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filter domain.AlertFilter
+			if tt.from != "" {
+				filter.From, _ = time.Parse(time.RFC3339, tt.from)
+			}
+			if tt.to != "" {
+				filter.To, _ = time.Parse(time.RFC3339, tt.to)
+			}
+			alerts, err := repository.FindAlerts(filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, alert := range alerts {
+				got = append(got, alert.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("FindAlerts() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("FindAlerts() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	since, _ := time.Parse(time.RFC3339, "2026-11-01T06:00:00Z")
+	alerts, err := repository.GetUnacknowledgedAlerts(since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].ID != "est" {
+		t.Errorf("GetUnacknowledgedAlerts() returned %d alerts, want only est", len(alerts))
+	}
+}
+
+func TestTimeQueriesUseIndexes(t *testing.T) {
+	repository, err := NewSQLiteAlertRepository(filepath.Join(t.TempDir(), "alerts.db"), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repository.Close()
+
+	now := time.Now().UTC()
+	tests := []struct {
+		name  string
+		query string
+		args  []interface{}
+	}{
+		{"alerts page", `SELECT id FROM alerts ORDER BY julianday(triggered_at) DESC LIMIT 50`, nil},
+		{"camera alerts", `SELECT id FROM alerts WHERE camera_name = ? ORDER BY julianday(triggered_at) DESC LIMIT 50`, []interface{}{"driveway"}},
+		{"time range", `SELECT id FROM alerts WHERE julianday(triggered_at) >= julianday(?) AND julianday(triggered_at) < julianday(?) ORDER BY julianday(triggered_at) DESC`, []interface{}{now.Add(-time.Hour), now}},
+		{"camera time range", `SELECT count(*) FROM alerts WHERE camera_name = ? AND julianday(triggered_at) >= julianday(?)`, []interface{}{"driveway", now.Add(-time.Hour)}},
+		{"unacknowledged", `SELECT id FROM alerts WHERE julianday(triggered_at) >= julianday(?) AND acknowledged_at IS NULL ORDER BY julianday(triggered_at)`, []interface{}{now.Add(-time.Hour)}},
+		{"incidents page", `SELECT id FROM incidents ORDER BY julianday(last_alert_at) DESC LIMIT 50`, nil},
+		{"recent incidents", `SELECT id FROM incidents WHERE julianday(last_alert_at) >= julianday(?) ORDER BY julianday(last_alert_at) DESC`, []interface{}{now.Add(-time.Hour)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := repository.db.Query("EXPLAIN QUERY PLAN "+test.query, test.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			var plan []string
+			for rows.Next() {
+				var id, parent, unused int
+				var detail string
+				if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+					t.Fatal(err)
+				}
+				plan = append(plan, detail)
+			}
+			for _, step := range plan {
+				if !strings.Contains(step, "USING INDEX") && !strings.Contains(step, "USING COVERING INDEX") || strings.Contains(step, "TEMP B-TREE") {
+					t.Errorf("plan %q does not use a time index", plan)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package adapters
+
+import (
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// GetWatchlist retrieves every watchlist entry, ordered by kind and value
+func (r *SQLiteAlertRepository) GetWatchlist() ([]domain.WatchlistEntry, error) {
+	rows, err := r.db.Query(`SELECT kind, value, list, note, created_by, created_at FROM watchlist ORDER BY kind, value`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.WatchlistEntry{}
+	for rows.Next() {
+		var entry domain.WatchlistEntry
+		var createdAt string
+		if err := rows.Scan(&entry.Kind, &entry.Value, &entry.List, &entry.Note, &entry.CreatedBy, &createdAt); err != nil {
+			return nil, err
+		}
+		if entry.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// SaveWatchlistEntry creates or replaces the entry of a kind and value
+func (r *SQLiteAlertRepository) SaveWatchlistEntry(entry *domain.WatchlistEntry) error {
+	_, err := r.db.Exec(
+		`INSERT OR REPLACE INTO watchlist (kind, value, list, note, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		entry.Kind, entry.Value, entry.List, entry.Note, entry.CreatedBy, entry.CreatedAt.In(r.location),
+	)
+	return err
+}
+
+// DeleteWatchlistEntry removes the entry of a kind and value, returning domain.ErrWatchlistEntryNotFound if there is none
+func (r *SQLiteAlertRepository) DeleteWatchlistEntry(kind string, value string) error {
+	result, err := r.db.Exec(`DELETE FROM watchlist WHERE kind = ? AND value = ?`, kind, value)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return domain.ErrWatchlistEntryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// telegramAPIURL is the base URL of the Telegram Bot API
+const telegramAPIURL = "https://api.telegram.org"
+
+// TelegramNotifier implements the AlertNotifier interface by sending messages through a Telegram bot
+type TelegramNotifier struct {
+	token  string
+	chatID string
+	client *http.Client
+}
+
+// NewTelegramNotifier creates a new Telegram notifier for a bot token and the chat it posts to
+func NewTelegramNotifier(token string, chatID string) *TelegramNotifier {
+	return &TelegramNotifier{
+		token:  token,
+		chatID: chatID,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SendAlert sends an alert notification to the Telegram chat
+func (t *TelegramNotifier) SendAlert(alert *domain.Alert) error {
+	slog.Info("Sending alert to Telegram", "camera", alert.CameraName, "alert_id", alert.ID)
+
+	text := fmt.Sprintf("Alert from %s camera\n%s\nTime: %s\nAlert ID: %s",
+		alert.CameraName, alert.AlertMessage, alert.TriggeredAt.Format("2006-01-02 15:04:05"), alert.ID)
+	if alert.Severity != "" {
+		text += "\nSeverity: " + alert.Severity
+	}
+	// Info alerts arrive silently, without a sound on the recipients' devices
+	if err := t.sendMessage(text, alert.Severity == domain.SeverityInfo); err != nil {
+		return err
+	}
+
+	slog.Info("Successfully sent alert to Telegram", "camera", alert.CameraName, "alert_id", alert.ID)
+	return nil
+}
+
+// SendReport sends a daily or weekly report to the Telegram chat, silently
+func (t *TelegramNotifier) SendReport(report *domain.Report) error {
+	slog.Info("Sending report to Telegram", "period", report.Period)
+	return t.sendMessage(reportTitle(report)+"\n\n"+reportText(report), true)
+}
+
+// sendMessage posts a text message to the chat; silent messages make no sound on the recipients' devices
+func (t *TelegramNotifier) sendMessage(text string, silent bool) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id":              t.chatID,
+		"text":                 text,
+		"disable_notification": silent,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Post(telegramAPIURL+"/bot"+t.token+"/sendMessage", "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The token is part of the URL, so only the underlying error may end up in logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		slog.Error("Failed to send Telegram message", "error", err)
+		return fmt.Errorf("telegram request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		slog.Error("Failed to send Telegram message", "status", resp.StatusCode, "response", string(detail))
+		return fmt.Errorf("telegram returned status %d: %s", resp.StatusCode, detail)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/web"
+)
+
+// layoutTemplate is the template shared by every page
+const layoutTemplate = "templates/layout.html"
+
+// overlayFS serves files from an override directory and falls back to the embedded assets
+type overlayFS struct {
+	override fs.FS
+	base     fs.FS
+}
+
+// Open opens the named file from the override directory if it exists there, otherwise from the base
+func (o overlayFS) Open(name string) (fs.File, error) {
+	file, err := o.override.Open(name)
+	if err == nil {
+		return file, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return o.base.Open(name)
+}
+
+// ReadDir lists a directory of both file systems, with entries of the override directory taking precedence
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(o.base, name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	overrides, overrideErr := fs.ReadDir(o.override, name)
+	if overrideErr != nil {
+		if errors.Is(overrideErr, fs.ErrNotExist) && err == nil {
+			return entries, nil
+		}
+		return nil, overrideErr
+	}
+
+	merged := make(map[string]fs.DirEntry, len(entries)+len(overrides))
+	for _, entry := range entries {
+		merged[entry.Name()] = entry
+	}
+	for _, entry := range overrides {
+		merged[entry.Name()] = entry
+	}
+	list := make([]fs.DirEntry, 0, len(merged))
+	for _, entry := range merged {
+		list = append(list, entry)
+	}
+	slices.SortFunc(list, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return list, nil
+}
+
+// webAssets returns the file system used for templates and static files.
+// When an override directory is configured, its files take precedence over the embedded ones.
+func webAssets(cfg *config.Config) fs.FS {
+	if cfg.WebDir == "" {
+		return web.Assets
+	}
+	slog.Info("Serving web assets with override directory", "web_dir", cfg.WebDir)
+	return overlayFS{override: os.DirFS(cfg.WebDir), base: web.Assets}
+}
+
+// templateRenderer parses page templates once and renders them with the shared layout
+type templateRenderer struct {
+	assets fs.FS
+	funcs  template.FuncMap
+	reload bool
+	pages  map[string]*template.Template
+}
+
+// newTemplateRenderer parses every page template found in the assets, including pages only in the override directory.
+// With reload enabled the templates are parsed again on every render, which allows editing
+// files in the override directory without restarting.
+func newTemplateRenderer(assets fs.FS, funcs template.FuncMap, reload bool) (*templateRenderer, error) {
+	r := &templateRenderer{
+		assets: assets,
+		funcs:  funcs,
+		reload: reload,
+		pages:  make(map[string]*template.Template),
+	}
+
+	names, err := fs.Glob(r.assets, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		if name == layoutTemplate {
+			continue
+		}
+		page := strings.TrimSuffix(path.Base(name), ".html")
+		tmpl, err := r.parse(page)
+		if err != nil {
+			return nil, err
+		}
+		r.pages[page] = tmpl
+	}
+
+	return r, nil
+}
+
+// parse parses a page template together with the layout
+func (r *templateRenderer) parse(page string) (*template.Template, error) {
+	tmpl, err := template.New(page).Funcs(r.funcs).ParseFS(r.assets, layoutTemplate, "templates/"+page+".html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", page, err)
+	}
+	return tmpl, nil
+}
+
+// render executes the layout of the named page
+func (r *templateRenderer) render(w io.Writer, page string, data interface{}) error {
+	var tmpl *template.Template
+	if r.reload {
+		parsed, err := r.parse(page)
+		if err != nil {
+			return err
+		}
+		tmpl = parsed
+	} else {
+		tmpl = r.pages[page]
+	}
+
+	if tmpl == nil {
+		return fmt.Errorf("unknown template %s", page)
+	}
+	return tmpl.ExecuteTemplate(w, "layout", data)
+}
+
+// templateFuncs returns the helper functions available to every template
+func templateFuncs(cfg *config.Config) template.FuncMap {
+	frigateURL := fmt.Sprintf("http://%s:%s", cfg.FrigateServer, cfg.FrigatePort)
+	return template.FuncMap{
+		// formatTime formats a timestamp in the configured time zone
+		"formatTime": func(t time.Time) string {
+			return t.In(cfg.Location).Format("2006-01-02 15:04:05")
+		},
+		// frigateURL returns the base URL of the Frigate server
+		"frigateURL": func() string {
+			return frigateURL
+		},
+		// alertThumbnailURL returns the Frigate thumbnail of the event behind an alert,
+		// falling back to the latest camera image for alerts without an event
+		"alertThumbnailURL": func(alert *domain.Alert) string {
+			if alert.EventID == "" {
+				return fmt.Sprintf("%s/api/%s/latest.jpg?h=120", frigateURL, alert.CameraName)
+			}
+			return fmt.Sprintf("%s/api/events/%s/thumbnail.jpg", frigateURL, alert.EventID)
+		},
+		// alertSnapshotURL returns the full snapshot Frigate archived for the event behind an alert,
+		// falling back to the latest camera image for alerts without an event
+		"alertSnapshotURL": func(alert *domain.Alert) string {
+			if alert.EventID == "" {
+				return fmt.Sprintf("%s/api/%s/latest.jpg?h=600", frigateURL, alert.CameraName)
+			}
+			return fmt.Sprintf("%s/api/events/%s/snapshot.jpg", frigateURL, alert.EventID)
+		},
+		// latestSnapshotURL returns the URL of the latest camera image scaled to the given height
+		"latestSnapshotURL": func(camera string, height int) string {
+			return fmt.Sprintf("%s/api/%s/latest.jpg?h=%d", frigateURL, camera, height)
+		},
+	}
+}
@@ -0,0 +1,49 @@
+package adapters
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+)
+
+func TestTemplateRendererWebDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "templates"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	pages := map[string]string{
+		"extra.html": `{{define "content"}}only in the override directory{{end}}`,
+		"home.html":  `{{define "content"}}overridden home{{end}}`,
+	}
+	for name, content := range pages {
+		if err := os.WriteFile(filepath.Join(dir, "templates", name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{WebDir: dir, Location: time.UTC}
+	renderer, err := newTemplateRenderer(webAssets(cfg), templateFuncs(cfg), false)
+	if err != nil {
+		t.Fatalf("newTemplateRenderer() error = %v", err)
+	}
+
+	for page, want := range map[string]string{
+		"extra":     "only in the override directory",
+		"home":      "overridden home",
+		"incidents": "Incidents",
+	} {
+		var out bytes.Buffer
+		if err := renderer.render(&out, page, map[string]any{"Title": page}); err != nil {
+			t.Errorf("render(%s) error = %v", page, err)
+			continue
+		}
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("render(%s) does not contain %q", page, want)
+		}
+	}
+}
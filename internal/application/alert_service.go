@@ -3,6 +3,8 @@ package application
 import (
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/vibin/frigate_alerter/internal/config"
@@ -14,46 +16,290 @@ import (
 type AlertService struct {
 	repository ports.AlertRepository
 	notifier   ports.AlertNotifier
+	snoozes    *SnoozeService
+	modes      *ModeService
+	incidents  *IncidentService
+	loitering  *LoiteringDetector
+	sequences  *ZoneSequenceDetector
+	watchlist  *WatchlistService
+	digests    *DigestService
 	config     *config.Config
+	// raising serializes alerts from the event queue and the loitering detector, so incidents are correlated one alert at a time
+	raising sync.Mutex
+
+	// heldBack holds, by event ID, when new events whose box was filtered out appeared; they alert once the box passes
+	heldMu   sync.Mutex
+	heldBack map[string]time.Time
+}
+
+// AlertServiceDeps holds the repository, notifier and services an alert service decides and raises alerts with
+type AlertServiceDeps struct {
+	Repository ports.AlertRepository
+	// Notifier may be nil when alerts are only evaluated, as in a dry run
+	Notifier  ports.AlertNotifier
+	Snoozes   *SnoozeService
+	Modes     *ModeService
+	Incidents *IncidentService
+	Loitering *LoiteringDetector
+	Sequences *ZoneSequenceDetector
+	Watchlist *WatchlistService
+	Digests   *DigestService
 }
 
 // NewAlertService creates a new alert service
-func NewAlertService(
-	repository ports.AlertRepository,
-	notifier ports.AlertNotifier,
-	config *config.Config,
-) *AlertService {
+func NewAlertService(deps AlertServiceDeps, config *config.Config) *AlertService {
 	return &AlertService{
-		repository: repository,
-		notifier:   notifier,
+		repository: deps.Repository,
+		notifier:   deps.Notifier,
+		snoozes:    deps.Snoozes,
+		modes:      deps.Modes,
+		incidents:  deps.Incidents,
+		loitering:  deps.Loitering,
+		sequences:  deps.Sequences,
+		watchlist:  deps.Watchlist,
+		digests:    deps.Digests,
 		config:     config,
+		heldBack:   make(map[string]time.Time),
 	}
 }
 
-// ProcessEvent processes a Frigate event and triggers alerts if needed
+// ProcessEvent processes a Frigate event and triggers alerts if needed.
+// New events raise an alert, and the loitering and zone sequence detectors follow every event except
+// detections failing the geometric filters. Those are ignored altogether, and a new object held back
+// that way alerts with the first update that passes them; end events always reach the detectors so
+// they forget the object. Faces and plates Frigate recognizes in later updates alert
+// again when they are denylisted or unknown at night.
 func (s *AlertService) ProcessEvent(event *domain.FrigateEvent) error {
-	// Check if this is a "new" alert
-	if event.Type != "new" {
-		slog.Debug("Ignoring non-new event", "type", event.Type)
+	object := event.Object()
+	rejection := s.geometryRejection(object)
+	// The watchlist tracks identities of objects that pass the filters, and forgets them when they end
+	recognized := false
+	if event.Type == "end" || rejection == "" {
+		recognized = s.watchlist.Recognized(event)
+	}
+
+	var err error
+	switch {
+	case event.Type == "end":
+		s.releaseHeldBack(object.ID)
+	case rejection != "":
+		if event.Type == "new" {
+			s.holdBack(object.ID)
+		}
+		slog.Debug("Ignoring detection by its geometry", "type", event.Type, "camera", object.Camera, "event_id", object.ID, "reason", rejection)
+		// A rejected box does not move the object through loitering or zone sequence rules either
+		return nil
+	case event.Type == "new":
+		err = s.raise(s.newAlert(&event.Before), &event.Before)
+	case s.releaseHeldBack(object.ID):
+		err = s.raise(s.newAlert(object), object)
+	case recognized:
+		err = s.raiseRecognized(object)
+	default:
+		slog.Debug("No alert for non-new event", "type", event.Type)
+	}
+
+	now := time.Now()
+	for _, loitering := range s.loitering.Observe(event, now) {
+		s.RaiseLoitering(loitering)
+	}
+	for _, sequence := range s.sequences.Observe(event, now) {
+		s.raiseZoneSequence(sequence)
+	}
+	return err
+}
+
+// RaiseCount raises an alert about a count rule that reached its threshold
+func (s *AlertService) RaiseCount(rule *config.CountRule, count int) {
+	currentTime := time.Now().In(s.config.Location)
+	object := &domain.FrigateBefore{Camera: rule.Camera, Label: rule.Label}
+	place := fmt.Sprintf("the %s camera", rule.Camera)
+	if rule.Zone != "" {
+		object.CurrentZones = []string{rule.Zone}
+		place = fmt.Sprintf("the %s zone of the %s camera", rule.Zone, rule.Camera)
+	}
+
+	alert := &domain.Alert{
+		ID:           fmt.Sprintf("%s_%s_%s_%d", domain.AlertTypeCount, rule.CountedIn(), rule.Label, currentTime.UnixNano()),
+		Type:         domain.AlertTypeCount,
+		CameraName:   rule.Camera,
+		Label:        rule.Label,
+		TriggeredAt:  currentTime,
+		AlertMessage: fmt.Sprintf("%d × %s in %s (%s)", count, rule.Label, place, rule.Name),
+	}
+	if err := s.raise(alert, object); err != nil {
+		slog.Error("Failed to raise count alert", "error", err, "camera", rule.Camera, "rule", rule.Name)
+	}
+}
+
+// RaiseAnomaly raises an alert about unusual alert volume on a camera; failures are logged as the detector has no one to report them to
+func (s *AlertService) RaiseAnomaly(anomaly Anomaly) {
+	currentTime := time.Now().In(s.config.Location)
+	slot := fmt.Sprintf("%ss at %s", anomaly.Hour.Weekday(), anomaly.Hour.Format("15:04"))
+	message := fmt.Sprintf("%d alerts on the %s camera this hour, which is normally silent on %s", anomaly.Count, anomaly.Camera, slot)
+	if anomaly.Baseline > 0 {
+		message = fmt.Sprintf("%d alerts on the %s camera this hour, %.0f× the usual %.1f on %s",
+			anomaly.Count, anomaly.Camera, float64(anomaly.Count)/anomaly.Baseline, anomaly.Baseline, slot)
+	}
+
+	alert := &domain.Alert{
+		ID:           fmt.Sprintf("%s_%s_%d", domain.AlertTypeAnomaly, anomaly.Camera, currentTime.UnixNano()),
+		Type:         domain.AlertTypeAnomaly,
+		CameraName:   anomaly.Camera,
+		TriggeredAt:  currentTime,
+		AlertMessage: message,
+	}
+	if err := s.raise(alert, &domain.FrigateBefore{Camera: anomaly.Camera}); err != nil {
+		slog.Error("Failed to raise anomaly alert", "error", err, "camera", anomaly.Camera)
+	}
+}
+
+// raiseZoneSequence raises an alert about an object that passed through zones in order
+func (s *AlertService) raiseZoneSequence(sequence ZoneSequence) {
+	object := sequence.Object
+	currentTime := time.Now().In(s.config.Location)
+	label := object.Label
+	if label == "" {
+		label = "object"
+	}
+
+	alert := &domain.Alert{
+		ID:          fmt.Sprintf("%s_%s_%s_%d", object.ID, object.Camera, domain.AlertTypeZoneSequence, currentTime.UnixNano()),
+		Type:        domain.AlertTypeZoneSequence,
+		CameraName:  object.Camera,
+		Label:       object.Label,
+		EventID:     object.ID,
+		TriggeredAt: currentTime,
+		AlertMessage: fmt.Sprintf("A %s moved %s on the %s camera (%s)",
+			label, strings.Join(sequence.Rule.Zones, " → "), object.Camera, sequence.Rule.Name),
+	}
+	if err := s.raise(alert, &object); err != nil {
+		slog.Error("Failed to raise zone sequence alert", "error", err, "camera", object.Camera, "event_id", object.ID, "rule", sequence.Rule.Name)
+	}
+}
+
+// RaiseLoitering raises a loitering alert; failures are logged as the detector has no one to report them to
+func (s *AlertService) RaiseLoitering(loitering Loitering) {
+	object := loitering.Object
+	currentTime := time.Now().In(s.config.Location)
+	label := object.Label
+	if label == "" {
+		label = "object"
+	}
+
+	alert := &domain.Alert{
+		ID:           fmt.Sprintf("%s_%s_%s_%d", object.ID, object.Camera, domain.AlertTypeLoitering, currentTime.UnixNano()),
+		Type:         domain.AlertTypeLoitering,
+		CameraName:   object.Camera,
+		Label:        object.Label,
+		EventID:      object.ID,
+		TriggeredAt:  currentTime,
+		AlertMessage: fmt.Sprintf("A %s has been in the %s zone of the %s camera for over %s", label, loitering.Zone, object.Camera, loitering.Rule.After),
+	}
+	if err := s.raise(alert, &object); err != nil {
+		slog.Error("Failed to raise loitering alert", "error", err, "camera", object.Camera, "event_id", object.ID)
+	}
+}
+
+// raiseRecognized raises a watchlist alert about a face or plate recognized after the object appeared,
+// when it is denylisted or an unknown plate at night. Other identities raise nothing; in particular an allowlisted
+// identity does not withdraw the alerts already sent about the object.
+func (s *AlertService) raiseRecognized(object *domain.FrigateBefore) error {
+	match, err := s.watchlist.Match(object, time.Now())
+	if err != nil {
+		slog.Error("Failed to check the watchlist", "error", err, "camera", object.Camera, "event_id", object.ID)
+		return err
+	}
+	if !match.Raises() {
 		return nil
 	}
+	return s.raise(s.recognizedAlert(object), object)
+}
 
+// recognizedAlert creates the watchlist alert of an object whose face or plate was recognized after it appeared
+func (s *AlertService) recognizedAlert(object *domain.FrigateBefore) *domain.Alert {
+	currentTime := time.Now().In(s.config.Location)
+	label := object.Label
+	if label == "" {
+		label = "object"
+	}
+
+	return &domain.Alert{
+		ID:           fmt.Sprintf("%s_%s_%s_%d", object.ID, object.Camera, domain.AlertTypeWatchlist, currentTime.UnixNano()),
+		Type:         domain.AlertTypeWatchlist,
+		CameraName:   object.Camera,
+		Label:        object.Label,
+		EventID:      object.ID,
+		TriggeredAt:  currentTime,
+		AlertMessage: fmt.Sprintf("A %s recognized on the %s camera", label, object.Camera),
+	}
+}
+
+// holdBack remembers a new event whose box was filtered out, forgetting those whose end was missed
+func (s *AlertService) holdBack(eventID string) {
+	s.heldMu.Lock()
+	defer s.heldMu.Unlock()
+
+	now := time.Now()
+	for id, since := range s.heldBack {
+		if now.Sub(since) > staleTrackAge {
+			delete(s.heldBack, id)
+		}
+	}
+	s.heldBack[eventID] = now
+}
+
+// isHeldBack reports whether a new event was held back by its box, without forgetting it
+func (s *AlertService) isHeldBack(eventID string) bool {
+	s.heldMu.Lock()
+	defer s.heldMu.Unlock()
+
+	_, ok := s.heldBack[eventID]
+	return ok
+}
+
+// releaseHeldBack forgets a held back event and reports whether it was held back
+func (s *AlertService) releaseHeldBack(eventID string) bool {
+	s.heldMu.Lock()
+	defer s.heldMu.Unlock()
+
+	_, ok := s.heldBack[eventID]
+	delete(s.heldBack, eventID)
+	return ok
+}
+
+// newAlert creates the alert of a new Frigate object
+func (s *AlertService) newAlert(object *domain.FrigateBefore) *domain.Alert {
 	// Create the alert message
-	alertMessage := fmt.Sprintf("An object detected in the %s camera", event.Before.Camera)
+	alertMessage := fmt.Sprintf("An object detected in the %s camera", object.Camera)
+	if object.Label != "" {
+		alertMessage = fmt.Sprintf("A %s detected in the %s camera", object.Label, object.Camera)
+	}
 
 	// Create a unique ID for this alert by combining the event ID with the camera name and current timestamp
 	// This ensures we don't get primary key conflicts when duplicate MQTT messages are received
 	currentTime := time.Now().In(s.config.Location)
-	uniqueID := fmt.Sprintf("%s_%s_%d", event.Before.ID, event.Before.Camera, currentTime.UnixNano())
+	uniqueID := fmt.Sprintf("%s_%s_%d", object.ID, object.Camera, currentTime.UnixNano())
 	
 	// Create the alert object
 	alert := &domain.Alert{
 		ID:           uniqueID,
-		Type:         event.Type,
-		CameraName:   event.Before.Camera,
+		Type:         "new",
+		CameraName:   object.Camera,
+		Label:        object.Label,
+		EventID:      object.ID,
 		TriggeredAt:  time.Now().In(s.config.Location),
 		AlertMessage: alertMessage,
 	}
+	return alert
+}
+
+// raise records an alert about a detected object and notifies about it unless it is muted
+func (s *AlertService) raise(alert *domain.Alert, object *domain.FrigateBefore) error {
+	s.raising.Lock()
+	defer s.raising.Unlock()
+
+	incident, isNewIncident := s.decide(alert, object, nil)
 
 	// Save alert to the database
 	if err := s.repository.SaveAlert(alert); err != nil {
@@ -61,12 +307,136 @@ func (s *AlertService) ProcessEvent(event *domain.FrigateEvent) error {
 		return err
 	}
 
+	if alert.SuppressedBy == domain.SuppressedByQuietHours {
+		s.digests.Hold(alert)
+	}
+	if alert.SuppressedBy != "" {
+		slog.Info("Alert muted", "type", alert.Type, "camera", alert.CameraName, "label", alert.Label, "alert_id", alert.ID, "suppressed_by", alert.SuppressedBy, "mode", alert.Mode, "severity", alert.Severity)
+		return nil
+	}
+
+	if incident != nil {
+		if err := s.incidents.Notify(incident, alert, isNewIncident); err != nil {
+			slog.Error("Failed to send incident notification", "error", err, "camera", alert.CameraName, "alert_id", alert.ID, "incident_id", incident.ID)
+			return err
+		}
+		slog.Info("Successfully processed alert", "camera", alert.CameraName, "alert_id", alert.ID, "incident_id", incident.ID, "time", alert.TriggeredAt)
+		return nil
+	}
+
 	// Send alert notification
 	if err := s.notifier.SendAlert(alert); err != nil {
 		slog.Error("Failed to send alert notification", "error", err, "camera", alert.CameraName, "alert_id", alert.ID)
 		return err
 	}
 
-	slog.Info("Successfully processed alert", "camera", alert.CameraName, "alert_id", alert.ID, "time", alert.TriggeredAt)
+	slog.Info("Successfully processed alert", "camera", alert.CameraName, "alert_id", alert.ID, "severity", alert.Severity, "time", alert.TriggeredAt)
 	return nil
 }
+
+// decide works out the mode, severity and mute state of an alert and the incident it joins, without saving
+// or sending anything. Each decision is recorded on the trace, which is nil for live alerts.
+func (s *AlertService) decide(alert *domain.Alert, object *domain.FrigateBefore, trace *domain.DecisionTrace) (*domain.Incident, bool) {
+	// Disarmed and snoozed alerts are still recorded, marked as muted, but send no notification
+	alert.SuppressedBy = s.suppressionReason(alert, trace)
+	// Severity rules may depend on the mode, which is known now
+	alert.Severity = s.severity(alert, object, trace)
+	// The alert condition may depend on the severity, so it is checked after the severity rules
+	if alert.SuppressedBy == "" && s.config.AlertCondition != nil {
+		if s.config.AlertCondition.Eval(conditionEnv(alert, object, s.config.Location)) {
+			trace.Add(domain.CheckCondition, domain.OutcomePass, fmt.Sprintf("meets the alert condition %s", s.config.AlertCondition))
+		} else {
+			alert.SuppressedBy = domain.SuppressedByCondition
+			trace.Add(domain.CheckCondition, domain.OutcomeMute, fmt.Sprintf("does not meet the alert condition %s", s.config.AlertCondition))
+		}
+	}
+
+	// Allowlisted faces and plates mute the alert; denylisted ones and unknown plates at night make it critical
+	match, err := s.watchlist.Match(object, alert.TriggeredAt)
+	if err != nil {
+		slog.Error("Failed to check the watchlist", "error", err, "camera", alert.CameraName, "alert_id", alert.ID)
+		trace.Add(domain.CheckWatchlist, domain.OutcomeSkip, "watchlist not available: "+err.Error())
+	}
+	switch {
+	case match == nil:
+		if err == nil {
+			trace.Add(domain.CheckWatchlist, domain.OutcomePass, "no known face or plate")
+		}
+	case match.Raises():
+		alert.Severity = domain.SeverityCritical
+		trace.Add(domain.CheckWatchlist, domain.OutcomeMatch, match.Describe()+", severity critical")
+	case alert.SuppressedBy == "":
+		alert.SuppressedBy = domain.SuppressedByWatchlist
+		trace.Add(domain.CheckWatchlist, domain.OutcomeMute, match.Describe())
+	default:
+		trace.Add(domain.CheckWatchlist, domain.OutcomeMatch, match.Describe()+", already muted")
+	}
+	if match != nil {
+		alert.AlertMessage += ": " + match.Describe()
+	}
+
+	// During quiet hours the notification waits for the digest, unless the alert is critical and may bypass them
+	if alert.SuppressedBy == "" && s.digests.Holds(alert) {
+		alert.SuppressedBy = domain.SuppressedByQuietHours
+		trace.Add(domain.CheckQuietHours, domain.OutcomeMute, "held back for the digest sent when quiet hours end")
+	} else if alert.SuppressedBy == "" && s.digests.Quiet(alert.TriggeredAt) {
+		trace.Add(domain.CheckQuietHours, domain.OutcomePass, "critical alerts bypass quiet hours")
+	}
+
+	// Notified alerts on adjacent cameras are grouped into incidents that share one notification
+	if alert.SuppressedBy != "" || !s.incidents.Enabled() {
+		return nil, false
+	}
+	// A replayed alert would be grouped with the incidents open now rather than those open when it was raised
+	if trace != nil && trace.AlertID != "" {
+		trace.Add(domain.CheckIncident, domain.OutcomeSkip, "incident grouping depends on the incidents open when the alert was raised and is not replayed")
+		return nil, false
+	}
+	incident, isNewIncident, err := s.incidents.Correlate(alert)
+	if err != nil {
+		// Fall back to a notification of its own rather than losing the alert
+		slog.Error("Failed to correlate alert into an incident", "error", err, "camera", alert.CameraName, "alert_id", alert.ID)
+		trace.Add(domain.CheckIncident, domain.OutcomeSkip, "incidents not available, notified on its own: "+err.Error())
+		return nil, false
+	}
+	if isNewIncident {
+		trace.Add(domain.CheckIncident, domain.OutcomeMatch, "starts a new incident")
+	} else {
+		trace.Add(domain.CheckIncident, domain.OutcomeMatch, fmt.Sprintf("joins incident %s on %s", incident.ID, strings.Join(incident.Cameras, ", ")))
+	}
+	return incident, isNewIncident
+}
+
+// suppressionReason records the current mode on the alert and returns why its notification must be skipped,
+// or "" to send it. Failing to read the mode or snoozes must not lose alerts, so such errors let it through.
+func (s *AlertService) suppressionReason(alert *domain.Alert, trace *domain.DecisionTrace) string {
+	state, err := s.modes.Current(alert.TriggeredAt)
+	switch {
+	case err != nil:
+		slog.Error("Failed to determine the current mode", "error", err)
+		trace.Add(domain.CheckMode, domain.OutcomeSkip, "mode not available: "+err.Error())
+	case state.Mode == "":
+		trace.Add(domain.CheckMode, domain.OutcomePass, "no arming mode configured")
+	default:
+		alert.Mode = state.Mode
+		if !s.modes.Allows(state.Mode, alert.CameraName, alert.Label) {
+			trace.Add(domain.CheckMode, domain.OutcomeMute, fmt.Sprintf("mode %s (%s) does not arm this camera and label", state.Mode, state.Source))
+			return domain.SuppressedByMode
+		}
+		trace.Add(domain.CheckMode, domain.OutcomePass, fmt.Sprintf("mode %s (%s) arms this camera and label", state.Mode, state.Source))
+	}
+
+	snooze, err := s.snoozes.Silencing(alert.CameraName, alert.Label, alert.TriggeredAt)
+	if err != nil {
+		slog.Error("Failed to check camera snoozes", "error", err, "camera", alert.CameraName)
+		trace.Add(domain.CheckSnooze, domain.OutcomeSkip, "snoozes not available: "+err.Error())
+	}
+	if snooze != nil {
+		trace.Add(domain.CheckSnooze, domain.OutcomeMute, fmt.Sprintf("camera snoozed until %s", snooze.Until.In(s.config.Location).Format(time.RFC3339)))
+		return domain.SuppressedBySnooze
+	}
+	if err == nil {
+		trace.Add(domain.CheckSnooze, domain.OutcomePass, "camera not snoozed")
+	}
+	return ""
+}
@@ -0,0 +1,197 @@
+package application_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/adapters"
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// stubNotifier records the notifications it is asked to send
+type stubNotifier struct {
+	sent    []*domain.Alert
+	digests []*domain.Digest
+	reports []*domain.Report
+}
+
+func (n *stubNotifier) SendAlert(alert *domain.Alert) error {
+	n.sent = append(n.sent, alert)
+	return nil
+}
+
+func (n *stubNotifier) SendIncident(incident *domain.Incident, alert *domain.Alert) (string, error) {
+	n.sent = append(n.sent, alert)
+	return "message-" + incident.ID, nil
+}
+
+func (n *stubNotifier) UpdateIncident(incident *domain.Incident, alert *domain.Alert, messageID string) error {
+	return nil
+}
+
+func (n *stubNotifier) SendDigest(digest *domain.Digest) error {
+	n.digests = append(n.digests, digest)
+	return nil
+}
+
+func (n *stubNotifier) SendReport(report *domain.Report) error {
+	n.reports = append(n.reports, report)
+	return nil
+}
+
+// newTestRepository creates a SQLite repository in a temporary directory
+func newTestRepository(t *testing.T) *adapters.SQLiteAlertRepository {
+	t.Helper()
+
+	repository, err := adapters.NewSQLiteAlertRepository(filepath.Join(t.TempDir(), "alerts.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	t.Cleanup(func() { repository.Close() })
+	return repository
+}
+
+// newTestAlertService creates an alert service and its collaborators for the configuration
+func newTestAlertService(t *testing.T, cfg *config.Config, repository *adapters.SQLiteAlertRepository, notifier *stubNotifier) *application.AlertService {
+	t.Helper()
+
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+	return application.NewAlertService(application.AlertServiceDeps{
+		Repository: repository,
+		Notifier:   notifier,
+		Snoozes:    application.NewSnoozeService(repository, cfg),
+		Modes:      application.NewModeService(repository, application.NewPresenceService(cfg), cfg),
+		Incidents:  application.NewIncidentService(repository, repository, notifier, cfg),
+		Loitering:  application.NewLoiteringDetector(cfg),
+		Sequences:  application.NewZoneSequenceDetector(cfg),
+		Watchlist:  application.NewWatchlistService(repository, cfg),
+		Digests:    application.NewDigestService(repository, notifier, nil, cfg),
+	}, cfg)
+}
+
+func TestAlertServiceHoldsBackFilteredObjects(t *testing.T) {
+	cfg := &config.Config{
+		CameraFrames:     map[string]config.FrameSize{"driveway": {Width: 1000, Height: 500}},
+		DetectionFilters: []config.DetectionFilter{{Labels: []string{"person"}, MinArea: 2500, MaxRatio: 1}},
+		// The street runs along the top fifth of the frame
+		CameraMasks: map[string]config.CameraMask{"driveway": {Exclude: [][][2]float64{{{0, 0}, {1, 0}, {1, 0.2}, {0, 0.2}}}}},
+	}
+	notifier := &stubNotifier{}
+	alertService := newTestAlertService(t, cfg, newTestRepository(t), notifier)
+
+	distant := domain.FrigateBefore{ID: "1", Camera: "driveway", Label: "person", Box: []int{500, 200, 510, 230}}
+	nearby := domain.FrigateBefore{ID: "1", Camera: "driveway", Label: "person", Box: []int{500, 300, 560, 420}}
+	street := domain.FrigateBefore{ID: "2", Camera: "driveway", Label: "car", Snapshot: domain.FrigateSnapshot{Box: []int{100, 20, 300, 90}}}
+	for i, event := range []*domain.FrigateEvent{
+		{Type: "new", Before: distant, After: distant},
+		{Type: "update", Before: distant, After: nearby},
+		{Type: "update", Before: nearby, After: nearby},
+		{Type: "new", Before: street},
+	} {
+		if err := alertService.ProcessEvent(event); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+	}
+
+	// The distant person alerts once it comes close; the car on the street never does
+	if len(notifier.sent) != 1 || notifier.sent[0].EventID != "1" || notifier.sent[0].Type != "new" {
+		t.Fatalf("sent %d notifications, want one new alert for event 1", len(notifier.sent))
+	}
+}
+
+func TestAlertServiceAlertCondition(t *testing.T) {
+	condition, err := config.CompileAlertCondition(`label == "person" && score > 0.8 && "porch" in zones && hour >= 22`)
+	if err != nil {
+		t.Fatalf("failed to compile the alert condition: %v", err)
+	}
+	cfg := &config.Config{DefaultSeverity: domain.SeverityWarning, AlertCondition: condition}
+	notifier := &stubNotifier{}
+	alertService := newTestAlertService(t, cfg, newTestRepository(t), notifier)
+
+	night := time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC)
+	porch := domain.FrigateBefore{ID: "1", Camera: "front", Label: "person", Score: 0.9, CurrentZones: []string{"porch"}}
+	tests := []struct {
+		name    string
+		object  domain.FrigateBefore
+		at      time.Time
+		outcome string
+	}{
+		{"meets the condition", porch, night, domain.OutcomePass},
+		{"during the day", porch, night.Add(-12 * time.Hour), domain.OutcomeMute},
+		{"outside the zone", domain.FrigateBefore{ID: "2", Camera: "front", Label: "person", Score: 0.9}, night, domain.OutcomeMute},
+		{"low score", domain.FrigateBefore{ID: "3", Camera: "front", Label: "person", Score: 0.5, CurrentZones: []string{"porch"}}, night, domain.OutcomeMute},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			traces, err := alertService.TestRules(application.RuleTest{Event: &domain.FrigateEvent{Type: "new", Before: test.object}, At: test.at})
+			if err != nil {
+				t.Fatalf("dry run failed: %v", err)
+			}
+			trace := traces[0]
+			var step *domain.DecisionStep
+			for i := range trace.Steps {
+				if trace.Steps[i].Check == domain.CheckCondition {
+					step = &trace.Steps[i]
+				}
+			}
+			if step == nil || step.Outcome != test.outcome {
+				t.Fatalf("condition step = %+v, want outcome %s", step, test.outcome)
+			}
+			if muted := trace.Alert.SuppressedBy == domain.SuppressedByCondition; muted != (test.outcome == domain.OutcomeMute) {
+				t.Errorf("suppressed by %q, want muted %v", trace.Alert.SuppressedBy, test.outcome == domain.OutcomeMute)
+			}
+		})
+	}
+
+	// A live alert that does not meet the condition is recorded without a notification
+	if err := alertService.ProcessEvent(&domain.FrigateEvent{Type: "new", Before: domain.FrigateBefore{ID: "4", Camera: "front", Label: "car"}}); err != nil {
+		t.Fatalf("failed to process event: %v", err)
+	}
+	if len(notifier.sent) != 0 {
+		t.Errorf("sent %d notifications, want none", len(notifier.sent))
+	}
+}
+
+func TestAlertServiceSeverityRules(t *testing.T) {
+	cfg := &config.Config{
+		DefaultSeverity: domain.SeverityWarning,
+		SeverityRules: []config.SeverityRule{
+			{Severity: domain.SeverityCritical, Labels: []string{"person"}, Zones: []string{"porch"}, MinScore: 0.8},
+			{Severity: domain.SeverityInfo, Labels: []string{"person"}},
+			{Severity: domain.SeverityCritical, Cameras: []string{"front"}},
+		},
+	}
+	notifier := &stubNotifier{}
+	alertService := newTestAlertService(t, cfg, newTestRepository(t), notifier)
+
+	tests := []struct {
+		name   string
+		object domain.FrigateBefore
+		want   string
+	}{
+		{"person on the porch", domain.FrigateBefore{Camera: "front", Label: "person", TopScore: 0.9, EnteredZones: []string{"porch"}}, domain.SeverityCritical},
+		// The first matching rule wins, although the camera rule after it would make the alert critical
+		{"unsure person on the porch", domain.FrigateBefore{Camera: "front", Label: "person", TopScore: 0.5, EnteredZones: []string{"porch"}}, domain.SeverityInfo},
+		{"car on the front camera", domain.FrigateBefore{Camera: "front", Label: "car"}, domain.SeverityCritical},
+		{"car on the back camera", domain.FrigateBefore{Camera: "back", Label: "car"}, domain.SeverityWarning},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.object.ID = fmt.Sprint(i)
+			if err := alertService.ProcessEvent(&domain.FrigateEvent{Type: "new", Before: test.object}); err != nil {
+				t.Fatalf("failed to process event: %v", err)
+			}
+			// Notifiers see the severity, so Discord mentions DISCORD_CRITICAL_MENTION for critical alerts only
+			sent := notifier.sent[len(notifier.sent)-1]
+			if sent.EventID != test.object.ID || sent.Severity != test.want {
+				t.Errorf("notified event %s with severity %s, want event %s with %s", sent.EventID, sent.Severity, test.object.ID, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package application
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// anomalyCheckInterval is how often the alert volume of the current hour is compared with the baseline
+const anomalyCheckInterval = 5 * time.Minute
+
+// Anomaly reports a camera whose alerts in the current hour are unusual for that hour of the week
+type Anomaly struct {
+	Camera string
+	// Hour is the start of the hour the alerts were counted in
+	Hour  time.Time
+	Count int
+	// Baseline is the average number of alerts in the same hour of the week; zero for hours that are normally silent
+	Baseline float64
+}
+
+// AnomalyDetector learns the usual number of alerts of each camera per hour of the week from the alert history
+// and reports hours with far more alerts than usual, or with alerts at an hour that is normally silent.
+// Only alerts about Frigate detections count, so the alerts it raises do not feed its own baseline.
+type AnomalyDetector struct {
+	repository ports.AlertRepository
+	config     *config.Config
+	// reported holds, by camera, the hour it was last reported for; a camera is reported once per hour
+	mu       sync.Mutex
+	reported map[string]time.Time
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewAnomalyDetector creates a new anomaly detector
+func NewAnomalyDetector(repository ports.AlertRepository, config *config.Config) *AnomalyDetector {
+	return &AnomalyDetector{
+		repository: repository,
+		config:     config,
+		reported:   make(map[string]time.Time),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Run checks for unusual activity every check interval until Stop is called
+func (d *AnomalyDetector) Run(handle func(anomaly Anomaly)) {
+	defer close(d.done)
+	if d.config.AnomalyDetection == nil {
+		return
+	}
+
+	ticker := time.NewTicker(anomalyCheckInterval)
+	defer ticker.Stop()
+
+	slog.Info("Anomaly detector started", "baseline_weeks", d.config.AnomalyDetection.BaselineWeeks, "factor", d.config.AnomalyDetection.Factor)
+	for {
+		select {
+		case now := <-ticker.C:
+			anomalies, err := d.Check(now)
+			if err != nil {
+				slog.Error("Failed to check for anomalies", "error", err)
+			}
+			for _, anomaly := range anomalies {
+				handle(anomaly)
+			}
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// Stop ends the periodic checks and waits for a running check to finish
+func (d *AnomalyDetector) Stop() {
+	close(d.stop)
+	<-d.done
+}
+
+// Check compares the alerts of each camera in the hour up to now with its baseline and returns the unusual ones
+// not reported yet. A camera is not reported until its history covers the whole baseline.
+func (d *AnomalyDetector) Check(now time.Time) ([]Anomaly, error) {
+	settings := d.config.AnomalyDetection
+	now = now.In(d.config.Location)
+	hour := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, d.config.Location)
+	from := hour.AddDate(0, 0, -7*settings.BaselineWeeks)
+
+	history, err := d.repository.GetAlertStats(domain.AlertFilter{Type: "new", From: from, To: hour})
+	if err != nil {
+		return nil, err
+	}
+	// A camera whose first alert came after the baseline started, such as one added since, has a baseline
+	// of silent hours that only reflect the missing history
+	firstAlerts, err := d.repository.GetFirstAlertTimes(domain.AlertFilter{Type: "new", To: hour})
+	if err != nil {
+		return nil, err
+	}
+
+	baseline := make(map[string]float64)
+	for _, bucket := range history.Hourly {
+		if bucket.Start.Weekday() == hour.Weekday() && bucket.Start.Hour() == hour.Hour() {
+			baseline[bucket.Camera] += float64(bucket.Count) / float64(settings.BaselineWeeks)
+		}
+	}
+
+	current, err := d.repository.GetCameraStats(domain.AlertFilter{Type: "new", From: hour, To: now.Add(time.Second)})
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for camera, reported := range d.reported {
+		if reported.Before(hour) {
+			delete(d.reported, camera)
+		}
+	}
+
+	var anomalies []Anomaly
+	for _, stats := range current {
+		if _, ok := d.reported[stats.Camera]; ok {
+			continue
+		}
+		if first, ok := firstAlerts[stats.Camera]; !ok || first.After(from.AddDate(0, 0, 1)) {
+			slog.Debug("Not enough history for an alert baseline", "camera", stats.Camera, "from", from)
+			continue
+		}
+		usual := baseline[stats.Camera]
+		unusual := stats.Total >= settings.SilentMinCount
+		if usual > 0 {
+			unusual = stats.Total >= settings.MinCount && float64(stats.Total) >= settings.Factor*usual
+		}
+		if !unusual {
+			continue
+		}
+		d.reported[stats.Camera] = hour
+		anomalies = append(anomalies, Anomaly{Camera: stats.Camera, Hour: hour, Count: stats.Total, Baseline: usual})
+	}
+	return anomalies, nil
+}
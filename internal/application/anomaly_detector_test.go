@@ -0,0 +1,72 @@
+package application_test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestAnomalyDetector(t *testing.T) {
+	cfg := &config.Config{Location: time.UTC, AnomalyDetection: &config.AnomalyConfig{BaselineWeeks: 4, Factor: 5, MinCount: 5, SilentMinCount: 1}}
+	repository := newTestRepository(t)
+
+	// On the four Mondays before, the driveway saw two alerts and the front door one between 14:00 and 15:00,
+	// while the garage only saw alerts in the morning
+	now := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC)
+	var alerts []domain.Alert
+	for week := 1; week <= 4; week++ {
+		hour := now.Add(-30*time.Minute).AddDate(0, 0, -7*week)
+		alerts = append(alerts,
+			domain.Alert{CameraName: "driveway", TriggeredAt: hour.Add(10 * time.Minute)},
+			domain.Alert{CameraName: "driveway", TriggeredAt: hour.Add(20 * time.Minute)},
+			domain.Alert{CameraName: "front_door", TriggeredAt: hour.Add(5 * time.Minute)},
+			domain.Alert{CameraName: "garage", TriggeredAt: hour.Add(-4 * time.Hour)},
+		)
+	}
+	// The shed camera was added last week, so its history does not cover the baseline yet
+	alerts = append(alerts, domain.Alert{CameraName: "shed", TriggeredAt: now.Add(-4*time.Hour).AddDate(0, 0, -7)})
+	// This Monday the driveway has five times its usual alerts, and the garage and the shed are active
+	for i := 0; i < 10; i++ {
+		alerts = append(alerts, domain.Alert{CameraName: "driveway", TriggeredAt: now.Add(-time.Duration(i) * time.Minute)})
+	}
+	for i := 0; i < 3; i++ {
+		alerts = append(alerts, domain.Alert{CameraName: "front_door", TriggeredAt: now.Add(-time.Duration(i) * time.Minute)})
+	}
+	alerts = append(alerts,
+		domain.Alert{CameraName: "garage", TriggeredAt: now.Add(-25 * time.Minute)},
+		domain.Alert{CameraName: "shed", TriggeredAt: now.Add(-20 * time.Minute)},
+	)
+	for i, alert := range alerts {
+		alert.ID = fmt.Sprintf("history_%d", i)
+		alert.Type = "new"
+		alert.AlertMessage = "A person detected in the " + alert.CameraName + " camera"
+		if err := repository.SaveAlert(&alert); err != nil {
+			t.Fatalf("failed to save alert: %v", err)
+		}
+	}
+
+	detector := application.NewAnomalyDetector(repository, cfg)
+	anomalies, err := detector.Check(now)
+	if err != nil {
+		t.Fatalf("failed to check for anomalies: %v", err)
+	}
+	sort.Slice(anomalies, func(i, j int) bool { return anomalies[i].Camera < anomalies[j].Camera })
+	if len(anomalies) != 2 || anomalies[0].Camera != "driveway" || anomalies[0].Baseline != 2 || anomalies[1].Camera != "garage" || anomalies[1].Baseline != 0 {
+		t.Fatalf("anomalies = %+v, want the busy driveway and the normally silent garage", anomalies)
+	}
+	if repeated, err := detector.Check(now.Add(5 * time.Minute)); err != nil || len(repeated) != 0 {
+		t.Errorf("reported %d anomalies again within the hour (err %v), want none", len(repeated), err)
+	}
+
+	notifier := &stubNotifier{}
+	newTestAlertService(t, cfg, repository, notifier).RaiseAnomaly(anomalies[0])
+	if len(notifier.sent) != 1 || notifier.sent[0].Type != domain.AlertTypeAnomaly || !strings.Contains(notifier.sent[0].AlertMessage, "5× the usual 2.0") {
+		t.Errorf("sent %+v, want an anomaly alert about five times the usual volume", notifier.sent)
+	}
+}
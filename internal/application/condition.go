@@ -0,0 +1,41 @@
+package application
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/expr"
+)
+
+// conditionEnv returns the variables the when conditions of rules are evaluated with, at the local time of the alert.
+// Without a detected object, as for stored alerts being escalated, the score, area, zones, sub label and plate are
+// left out; escalation conditions cannot use them.
+func conditionEnv(alert *domain.Alert, object *domain.FrigateBefore, location *time.Location) expr.Env {
+	t := alert.TriggeredAt.In(location)
+	env := expr.Env{
+		"type":     alert.Type,
+		"camera":   alert.CameraName,
+		"label":    alert.Label,
+		"mode":     alert.Mode,
+		"severity": alert.Severity,
+		"hour":     float64(t.Hour()),
+		"minute":   float64(t.Minute()),
+		"weekday":  strings.ToLower(t.Weekday().String()[:3]),
+	}
+	if object == nil {
+		return env
+	}
+
+	area := object.Area
+	if area == 0 {
+		area = object.Snapshot.Area
+	}
+	env["score"] = max(object.Score, object.TopScore)
+	env["area"] = float64(area)
+	env["zones"] = slices.Concat(object.CurrentZones, object.EnteredZones)
+	env["sub_label"] = string(object.SubLabel)
+	env["plate"] = object.RecognizedLicensePlate
+	return env
+}
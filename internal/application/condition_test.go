@@ -0,0 +1,163 @@
+package application
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/expr"
+)
+
+func TestConditionEnv(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database not available:", err)
+	}
+	// 03:30 UTC on a Monday is 23:30 on Sunday in New York
+	alert := &domain.Alert{Type: "new", CameraName: "front_door", Label: "person", Mode: "away", Severity: domain.SeverityCritical,
+		TriggeredAt: time.Date(2026, 10, 19, 3, 30, 0, 0, time.UTC)}
+	object := &domain.FrigateBefore{
+		Camera: "front_door", Label: "person", SubLabel: "alice", RecognizedLicensePlate: "AB-123",
+		Score: 0.7, TopScore: 0.9, Snapshot: domain.FrigateSnapshot{Area: 4200},
+		CurrentZones: []string{"porch"}, EnteredZones: []string{"path", "porch"},
+	}
+
+	env := conditionEnv(alert, object, location)
+	want := expr.Env{
+		"type": "new", "camera": "front_door", "label": "person", "mode": "away", "severity": domain.SeverityCritical,
+		"hour": float64(23), "minute": float64(30), "weekday": "sun",
+		// The best score and the snapshot's area when the object has no box of its own
+		"score": 0.9, "area": float64(4200), "sub_label": "alice", "plate": "AB-123",
+	}
+	for name, value := range want {
+		if env[name] != value {
+			t.Errorf("%s = %v, want %v", name, env[name], value)
+		}
+	}
+	if zones, _ := env["zones"].([]string); !slices.Equal(zones, []string{"porch", "path", "porch"}) {
+		t.Errorf("zones = %v, want the current and entered zones", env["zones"])
+	}
+
+	// Without an object, as for stored alerts being escalated, only the alert's own variables are bound
+	env = conditionEnv(alert, nil, location)
+	for _, name := range []string{"score", "area", "zones", "sub_label", "plate"} {
+		if _, ok := env[name]; ok {
+			t.Errorf("%s is bound without an object", name)
+		}
+	}
+	if env["hour"] != float64(23) || env["camera"] != "front_door" {
+		t.Errorf("env without an object = %v, want the alert's variables", env)
+	}
+}
+
+func TestConditionCompileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		compile func(string) (*expr.Expression, error)
+		source  string
+		want    string
+	}{
+		{"misspelt variable", config.CompileSeverityCondition, `scroe > 0.8`, `column 1: unknown variable "scroe"`},
+		{"number as operand of &&", config.CompileSeverityCondition, `label == "person" && hour`, `column 19: && needs true or false on its right, not a number`},
+		{"number compared with string", config.CompileSeverityCondition, `score > "high"`, `column 7: > compares values of the same type, not a number with a string`},
+		{"assignment", config.CompileSeverityCondition, `label = "person"`, `column 7: unexpected character '=', did you mean "=="`},
+		{"unclosed parenthesis", config.CompileSeverityCondition, `"porch" in zones && (hour > 22`, `column 31: expected ")" to close the "(" at column 21`},
+		// Severity rules decide the severity, so they cannot depend on it
+		{"severity in a severity rule", config.CompileSeverityCondition, `severity == "critical"`, `column 1: unknown variable "severity"`},
+		// Escalations evaluate stored alerts, which keep no detected object
+		{"score in an escalation", config.CompileEscalationCondition, `score > 0.8`, `unknown variable "score"`},
+		{"zones in an escalation", config.CompileEscalationCondition, `"porch" in zones`, `unknown variable "zones"`},
+		{"area in an escalation", config.CompileEscalationCondition, `area > 100`, `unknown variable "area"`},
+		{"sub label in an escalation", config.CompileEscalationCondition, `sub_label == "bob"`, `unknown variable "sub_label"`},
+		{"plate in an escalation", config.CompileEscalationCondition, `plate != ""`, `unknown variable "plate"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := test.compile(test.source); err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("compiling %s: error %v, want %s", test.source, err, test.want)
+			}
+		})
+	}
+
+	// The alert condition is checked once the severity is known
+	for _, compile := range []func(string) (*expr.Expression, error){config.CompileEscalationCondition, config.CompileAlertCondition} {
+		if _, err := compile(`severity == "critical" && hour < 6`); err != nil {
+			t.Errorf("compiling a condition on the severity: %v", err)
+		}
+	}
+}
+
+func TestSeverityConditions(t *testing.T) {
+	cfg := &config.Config{Location: time.UTC, DefaultSeverity: domain.SeverityInfo}
+	for _, rule := range []config.SeverityRule{
+		{Severity: domain.SeverityCritical, When: `label == "person" && score > 0.8 && "porch" in zones`},
+		{Severity: domain.SeverityWarning, When: `label not in ["cat", "dog"] && weekday != "xyz" && hour >= 22`},
+	} {
+		var err error
+		if rule.Condition, err = config.CompileSeverityCondition(rule.When); err != nil {
+			t.Fatalf("compiling %s: %v", rule.When, err)
+		}
+		cfg.SeverityRules = append(cfg.SeverityRules, rule)
+	}
+	service := &AlertService{config: cfg}
+
+	night := time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		object domain.FrigateBefore
+		at     time.Time
+		want   string
+	}{
+		{"person entered the porch", domain.FrigateBefore{Label: "person", TopScore: 0.9, EnteredZones: []string{"porch"}}, night, domain.SeverityCritical},
+		{"person on the street", domain.FrigateBefore{Label: "person", TopScore: 0.9, CurrentZones: []string{"street"}}, night, domain.SeverityWarning},
+		{"person on the porch by day", domain.FrigateBefore{Label: "person", TopScore: 0.9, CurrentZones: []string{"porch"}}, night.Add(-12 * time.Hour), domain.SeverityCritical},
+		{"car by day", domain.FrigateBefore{Label: "car"}, night.Add(-12 * time.Hour), domain.SeverityInfo},
+		{"cat on the porch", domain.FrigateBefore{Label: "cat", TopScore: 0.9, CurrentZones: []string{"porch"}}, night, domain.SeverityInfo},
+		// A detection without a score or zones gets the zero values
+		{"person without score", domain.FrigateBefore{Label: "person"}, night, domain.SeverityWarning},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			alert := &domain.Alert{Type: "new", CameraName: "front_door", Label: test.object.Label, TriggeredAt: test.at}
+			trace := &domain.DecisionTrace{}
+			if got := service.severity(alert, &test.object, trace); got != test.want {
+				t.Errorf("severity = %s, want %s (%+v)", got, test.want, trace.Steps)
+			}
+		})
+	}
+}
+
+func TestEscalationConditions(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available:", err)
+	}
+	condition, err := config.CompileEscalationCondition(`severity == "critical" && (hour >= 22 || hour < 6)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{Location: location, Escalations: []config.EscalationPolicy{{Name: "critical at night", Condition: condition}}}
+
+	tests := []struct {
+		name     string
+		severity string
+		at       time.Time
+		want     bool
+	}{
+		// 21:30 UTC is 23:30 in Berlin in summer time
+		{"critical at night", domain.SeverityCritical, time.Date(2026, 7, 1, 21, 30, 0, 0, time.UTC), true},
+		{"critical in the evening", domain.SeverityCritical, time.Date(2026, 7, 1, 18, 0, 0, 0, time.UTC), false},
+		{"warning at night", domain.SeverityWarning, time.Date(2026, 7, 1, 21, 30, 0, 0, time.UTC), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			alert := &domain.Alert{Type: "new", CameraName: "front_door", Label: "person", Severity: test.severity, TriggeredAt: test.at}
+			if got := escalationPolicy(cfg, alert) != nil; got != test.want {
+				t.Errorf("escalated = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+)
+
+// CountMonitor follows the live object counts Frigate publishes per camera and zone, together with the
+// topics count rules depend on, and raises an alert when a rule's threshold is reached. A rule that
+// alerted stays quiet until its count drops to the re-arm count, so a flapping count alerts only once.
+type CountMonitor struct {
+	alerts *AlertService
+	config *config.Config
+
+	mu       sync.Mutex
+	counts   map[string]int
+	states   map[string]string
+	active   map[int]bool
+	stopping bool
+	// raising tracks the alerts being raised in the background, so shutdown can wait for them
+	raising sync.WaitGroup
+}
+
+// NewCountMonitor creates a new count monitor that raises its alerts through the alert service
+func NewCountMonitor(alerts *AlertService, config *config.Config) *CountMonitor {
+	return &CountMonitor{
+		alerts: alerts,
+		config: config,
+		counts: make(map[string]int),
+		states: make(map[string]string),
+		active: make(map[int]bool),
+	}
+}
+
+// Topics returns the count and condition topics to subscribe to
+func (m *CountMonitor) Topics() []string {
+	var topics []string
+	for i := range m.config.CountRules {
+		rule := &m.config.CountRules[i]
+		topics = appendUnique(topics, countTopic(rule))
+		for _, condition := range rule.When {
+			topics = appendUnique(topics, condition.Topic)
+		}
+	}
+	return topics
+}
+
+// HandleMessage records a count or a condition state and raises the alerts of rules that reached their threshold.
+// Alerts are raised in the background, so slow notifiers do not hold up MQTT delivery; once shutdown has
+// started, reached thresholds are logged instead.
+func (m *CountMonitor) HandleMessage(topic string, payload []byte) {
+	value := strings.TrimSpace(string(payload))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.isCountTopic(topic) {
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 0 {
+			slog.Error("Invalid object count", "topic", topic, "payload", value)
+			return
+		}
+		m.counts[topic] = count
+	} else {
+		m.states[topic] = strings.ToLower(strings.Trim(value, `"`))
+	}
+
+	for i := range m.config.CountRules {
+		rule := &m.config.CountRules[i]
+		count := m.counts[countTopic(rule)]
+		holds := m.conditionsHold(rule)
+
+		switch {
+		case !m.active[i] && holds && count >= rule.Min:
+			m.active[i] = true
+			slog.Info("Count threshold reached", "rule", rule.Name, "count", count, "min", rule.Min)
+			if m.stopping {
+				slog.Warn("Count alert not raised", "reason", "monitor is shutting down", "rule", rule.Name, "count", count)
+				continue
+			}
+			m.raising.Add(1)
+			go func() {
+				defer m.raising.Done()
+				m.alerts.RaiseCount(rule, count)
+			}()
+		case m.active[i] && (!holds || count <= rule.ClearAt):
+			m.active[i] = false
+			slog.Info("Count rule re-armed", "rule", rule.Name, "count", count)
+		}
+	}
+}
+
+// Shutdown stops raising alerts and waits for the ones being raised, or until the context expires
+func (m *CountMonitor) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.stopping = true
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.raising.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		slog.Warn("Count alerts still being raised at the shutdown deadline")
+		return ctx.Err()
+	}
+}
+
+// conditionsHold reports whether every condition of a rule matches the latest state of its topic
+func (m *CountMonitor) conditionsHold(rule *config.CountRule) bool {
+	for _, condition := range rule.When {
+		if m.states[condition.Topic] != strings.ToLower(condition.State) {
+			return false
+		}
+	}
+	return true
+}
+
+// isCountTopic reports whether a topic carries an object count of a rule
+func (m *CountMonitor) isCountTopic(topic string) bool {
+	for i := range m.config.CountRules {
+		if countTopic(&m.config.CountRules[i]) == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// countTopic returns the topic on which Frigate publishes the count a rule watches
+func countTopic(rule *config.CountRule) string {
+	return "frigate/" + rule.CountedIn() + "/" + rule.Label
+}
+
+// appendUnique appends value unless the list already contains it
+func appendUnique(list []string, value string) []string {
+	if slices.Contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
@@ -0,0 +1,55 @@
+package application_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestCountMonitor(t *testing.T) {
+	clearAt := 1
+	cfg := &config.Config{CountRules: []config.CountRule{
+		{Name: "crowd", Camera: "backyard", Label: "person", Min: 4, Clear: &clearAt, ClearAt: clearAt},
+		{Name: "car at open garage", Camera: "driveway", Label: "car", Min: 1, When: []config.TopicState{{Topic: "home/garage/door", State: "open"}}},
+	}}
+	repository := newTestRepository(t)
+	monitor := application.NewCountMonitor(newTestAlertService(t, cfg, repository, &stubNotifier{}), cfg)
+	if got := strings.Join(monitor.Topics(), ","); got != "frigate/backyard/person,frigate/driveway/car,home/garage/door" {
+		t.Fatalf("topics = %s", got)
+	}
+
+	// The crowd count flaps around the threshold and only alerts again after dropping to the clear count;
+	// the car only alerts once the garage door opens
+	for _, message := range [][2]string{
+		{"frigate/backyard/person", "4"}, {"frigate/backyard/person", "3"}, {"frigate/backyard/person", "5"},
+		{"frigate/backyard/person", "1"}, {"frigate/backyard/person", "4"},
+		{"frigate/driveway/car", "1"}, {"home/garage/door", "OPEN"},
+	} {
+		monitor.HandleMessage(message[0], []byte(message[1]))
+	}
+
+	// Count alerts are raised in the background; shutdown waits for them and stops raising new ones
+	if err := monitor.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	monitor.HandleMessage("frigate/backyard/person", []byte("0"))
+	monitor.HandleMessage("frigate/backyard/person", []byte("6"))
+
+	alerts, err := repository.FindAlerts(domain.AlertFilter{Type: domain.AlertTypeCount})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cameras := make([]string, len(alerts))
+	for i, alert := range alerts {
+		cameras[i] = alert.CameraName
+	}
+	sort.Strings(cameras)
+	if got := strings.Join(cameras, ","); got != "backyard,backyard,driveway" {
+		t.Errorf("count alerts on %s, want two in the backyard and one in the driveway", got)
+	}
+}
@@ -0,0 +1,304 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// digestCheckInterval is how often the end of quiet hours is checked for
+const digestCheckInterval = 30 * time.Second
+
+// digestHighlights is the largest number of snapshots in the collage of a digest
+const digestHighlights = 4
+
+// digestTileWidth and digestTileHeight are the size of one snapshot in the collage
+const (
+	digestTileWidth  = 480
+	digestTileHeight = 270
+)
+
+// snapshotTimeout bounds fetching one snapshot for the collage
+const snapshotTimeout = 10 * time.Second
+
+// digestRecoveryWindow is how far back held back alerts are recovered when no digest was ever sent
+const digestRecoveryWindow = 24 * time.Hour
+
+// DigestService holds back the notifications of alerts during quiet hours and sends one digest of them
+// once quiet hours are over. Held back alerts are kept in memory and stored like any other alert; the
+// repository records how far the digests got, so the alerts of a digest lost to a restart are recovered
+// when the service starts.
+type DigestService struct {
+	repository ports.DigestRepository
+	notifier   ports.DigestNotifier
+	snapshots  ports.SnapshotProvider
+	config     *config.Config
+
+	mu   sync.Mutex
+	held []*domain.Alert
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewDigestService creates a new digest service
+func NewDigestService(repository ports.DigestRepository, notifier ports.DigestNotifier, snapshots ports.SnapshotProvider, config *config.Config) *DigestService {
+	return &DigestService{
+		repository: repository,
+		notifier:   notifier,
+		snapshots:  snapshots,
+		config:     config,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Quiet reports whether time t falls into quiet hours
+func (s *DigestService) Quiet(t time.Time) bool {
+	for _, window := range s.config.QuietHours {
+		if _, ok := windowEnd(window, t.In(s.config.Location)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Holds reports whether the notification of an alert raised during quiet hours is held back for the digest
+func (s *DigestService) Holds(alert *domain.Alert) bool {
+	if !s.Quiet(alert.TriggeredAt) {
+		return false
+	}
+	return alert.Severity != domain.SeverityCritical || !s.config.QuietHoursBypassCritical
+}
+
+// Hold adds an alert to the next digest
+func (s *DigestService) Hold(alert *domain.Alert) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.held = append(s.held, alert)
+}
+
+// Recover adds the stored alerts held back since the last digest was sent, such as those of a digest lost
+// to a restart, to the next digest. Without any digest sent, it looks back over digestRecoveryWindow.
+func (s *DigestService) Recover(now time.Time) error {
+	after, err := s.repository.GetDigestCursor()
+	if err != nil {
+		return err
+	}
+	if after.IsZero() {
+		after = now.Add(-digestRecoveryWindow)
+	}
+	stored, err := s.repository.GetHeldAlerts(after)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Alerts raised since the service was created may already be held
+	recovered := slices.DeleteFunc(stored, func(alert *domain.Alert) bool {
+		return slices.ContainsFunc(s.held, func(held *domain.Alert) bool { return held.ID == alert.ID })
+	})
+	s.held = append(recovered, s.held...)
+	if len(recovered) > 0 {
+		slog.Info("Recovered alerts held back for the quiet hours digest", "alerts", len(recovered), "since", after)
+	}
+	return nil
+}
+
+// Run sends the digest once quiet hours end, checking every digest interval until Stop is called.
+// It starts by recovering the alerts held back before the alerter started.
+func (s *DigestService) Run() {
+	defer close(s.done)
+	if len(s.config.QuietHours) == 0 {
+		return
+	}
+	if err := s.Recover(time.Now()); err != nil {
+		slog.Error("Failed to recover alerts held back for the quiet hours digest", "error", err)
+	}
+
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	slog.Info("Quiet hours digest scheduler started", "windows", len(s.config.QuietHours))
+	for {
+		select {
+		case now := <-ticker.C:
+			if err := s.Check(now); err != nil {
+				slog.Error("Failed to send quiet hours digest", "error", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Stop ends the scheduler and waits for a digest being sent; alerts still held back are recovered on the next start
+func (s *DigestService) Stop() {
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.held) > 0 {
+		slog.Info("Quiet hours digest not sent before shutdown, it is sent after the next start", "alerts", len(s.held))
+	}
+}
+
+// Check sends the digest of the held back alerts when quiet hours are over at the given time.
+// A digest that fails to send is retried with the next check.
+func (s *DigestService) Check(now time.Time) error {
+	if s.Quiet(now) {
+		return nil
+	}
+	s.mu.Lock()
+	held := s.held
+	s.mu.Unlock()
+	if len(held) == 0 {
+		return nil
+	}
+
+	digest := s.digest(held, now)
+	if err := s.notifier.SendDigest(digest); err != nil {
+		return err
+	}
+
+	// Alerts held back while the digest was sent stay for the next one
+	s.mu.Lock()
+	s.held = s.held[len(held):]
+	s.mu.Unlock()
+
+	// Should the cursor not be saved, the alerts of this digest are sent again after a restart
+	sentUntil := held[0].TriggeredAt
+	for _, alert := range held {
+		if alert.TriggeredAt.After(sentUntil) {
+			sentUntil = alert.TriggeredAt
+		}
+	}
+	if err := s.repository.SaveDigestCursor(sentUntil); err != nil {
+		slog.Error("Failed to record the quiet hours digest as sent", "error", err)
+	}
+
+	slog.Info("Quiet hours digest sent", "alerts", digest.Total, "from", digest.From, "to", digest.To)
+	return nil
+}
+
+// digest summarizes held back alerts, oldest first, with a collage of the snapshots of the most severe ones
+func (s *DigestService) digest(alerts []*domain.Alert, now time.Time) *domain.Digest {
+	digest := &domain.Digest{
+		From:  alerts[0].TriggeredAt,
+		To:    now.In(s.config.Location),
+		Total: len(alerts),
+	}
+
+	counts := make(map[domain.DigestCount]int)
+	for _, alert := range alerts {
+		counts[domain.DigestCount{Camera: alert.CameraName, Label: alert.Label}]++
+	}
+	for count, n := range counts {
+		count.Count = n
+		digest.Counts = append(digest.Counts, count)
+	}
+	slices.SortFunc(digest.Counts, func(a, b domain.DigestCount) int {
+		if a.Camera != b.Camera {
+			return strings.Compare(a.Camera, b.Camera)
+		}
+		return strings.Compare(a.Label, b.Label)
+	})
+
+	// The most severe alerts make the highlights, the latest first among equals
+	candidates := slices.Clone(alerts)
+	slices.Reverse(candidates)
+	slices.SortStableFunc(candidates, func(a, b *domain.Alert) int {
+		return domain.SeverityRank(b.Severity) - domain.SeverityRank(a.Severity)
+	})
+
+	var snapshots []image.Image
+	for _, alert := range candidates {
+		if len(snapshots) == digestHighlights {
+			break
+		}
+		if alert.EventID == "" {
+			continue
+		}
+		snapshot, err := s.snapshot(alert.EventID)
+		if err != nil {
+			slog.Warn("Snapshot not available for digest", "alert_id", alert.ID, "event_id", alert.EventID, "error", err)
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+		digest.Highlights = append(digest.Highlights, alert)
+	}
+
+	if len(snapshots) > 0 {
+		collage, err := encodeCollage(snapshots)
+		if err != nil {
+			slog.Error("Failed to create digest collage", "error", err)
+		}
+		digest.Collage = collage
+	}
+	return digest
+}
+
+// snapshot fetches and decodes the snapshot of an event
+func (s *DigestService) snapshot(eventID string) (image.Image, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+
+	reader, err := s.snapshots.GetEventSnapshot(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	snapshot, _, err := image.Decode(reader)
+	return snapshot, err
+}
+
+// encodeCollage lays snapshots out in a grid two tiles wide and encodes it as JPEG.
+// Each snapshot is scaled to fit its tile, keeping its aspect ratio.
+func encodeCollage(snapshots []image.Image) ([]byte, error) {
+	columns := min(len(snapshots), 2)
+	rows := (len(snapshots) + columns - 1) / columns
+	collage := image.NewRGBA(image.Rect(0, 0, columns*digestTileWidth, rows*digestTileHeight))
+	draw.Draw(collage, collage.Bounds(), image.Black, image.Point{}, draw.Src)
+
+	for i, snapshot := range snapshots {
+		tile := image.Rect(0, 0, digestTileWidth, digestTileHeight).Add(image.Pt(i%columns*digestTileWidth, i/columns*digestTileHeight))
+		drawScaled(collage, tile, snapshot)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, collage, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawScaled draws src centred in the tile of dst, scaled with nearest neighbour sampling to fit it
+func drawScaled(dst *image.RGBA, tile image.Rectangle, src image.Image) {
+	bounds := src.Bounds()
+	if bounds.Empty() {
+		return
+	}
+	scale := min(float64(tile.Dx())/float64(bounds.Dx()), float64(tile.Dy())/float64(bounds.Dy()))
+	width, height := int(float64(bounds.Dx())*scale), int(float64(bounds.Dy())*scale)
+	offset := tile.Min.Add(image.Pt((tile.Dx()-width)/2, (tile.Dy()-height)/2))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dst.Set(offset.X+x, offset.Y+y, src.At(bounds.Min.X+int(float64(x)/scale), bounds.Min.Y+int(float64(y)/scale)))
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package application_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestDigestServiceRecoversAfterRestart(t *testing.T) {
+	cfg := &config.Config{Location: time.UTC}
+	repository := newTestRepository(t)
+	now := time.Date(2025, 3, 10, 7, 30, 0, 0, time.UTC)
+
+	// A digest was sent at midnight; two cars and a person were held back for the next one before a restart,
+	// and a notified alert does not belong in a digest
+	if err := repository.SaveDigestCursor(now.Add(-7*time.Hour - 30*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	for i, alert := range []domain.Alert{
+		{CameraName: "driveway", Label: "car", TriggeredAt: now.Add(-8 * time.Hour), SuppressedBy: domain.SuppressedByQuietHours},
+		{CameraName: "driveway", Label: "car", TriggeredAt: now.Add(-3 * time.Hour), SuppressedBy: domain.SuppressedByQuietHours},
+		{CameraName: "driveway", Label: "car", TriggeredAt: now.Add(-2 * time.Hour), SuppressedBy: domain.SuppressedByQuietHours},
+		{CameraName: "front_door", Label: "person", TriggeredAt: now.Add(-time.Hour), SuppressedBy: domain.SuppressedByQuietHours},
+		{CameraName: "front_door", Label: "dog", TriggeredAt: now.Add(-time.Hour)},
+	} {
+		alert.ID = fmt.Sprintf("held_%d", i)
+		alert.Type = "new"
+		alert.AlertMessage = "A " + alert.Label + " detected in the " + alert.CameraName + " camera"
+		if err := repository.SaveAlert(&alert); err != nil {
+			t.Fatalf("failed to save alert: %v", err)
+		}
+	}
+
+	notifier := &stubNotifier{}
+	service := application.NewDigestService(repository, notifier, nil, cfg)
+	// The person was also held back in memory after the service was created
+	person, err := repository.GetAlert("held_3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.Hold(person)
+	if err := service.Recover(now); err != nil {
+		t.Fatalf("failed to recover held back alerts: %v", err)
+	}
+	if err := service.Check(now); err != nil {
+		t.Fatalf("failed to send digest: %v", err)
+	}
+	if len(notifier.digests) != 1 || notifier.digests[0].Total != 3 || !notifier.digests[0].From.Equal(now.Add(-3*time.Hour)) {
+		t.Fatalf("sent %+v, want one digest of the 3 alerts since 04:30", notifier.digests)
+	}
+
+	// After another restart the digest that was sent is not sent again
+	restarted := application.NewDigestService(repository, notifier, nil, cfg)
+	if err := restarted.Recover(now.Add(time.Minute)); err != nil {
+		t.Fatalf("failed to recover held back alerts: %v", err)
+	}
+	if err := restarted.Check(now.Add(time.Minute)); err != nil || len(notifier.digests) != 1 {
+		t.Errorf("sent %d digests after the restart (err %v), want none more", len(notifier.digests)-1, err)
+	}
+}
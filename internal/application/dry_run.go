@@ -0,0 +1,173 @@
+package application
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// Default and largest number of stored alerts a dry run over a time range replays
+const (
+	defaultRuleTestAlerts = 100
+	maxRuleTestAlerts     = 1000
+)
+
+// RuleTest names what a dry run evaluates: a Frigate event at a given time, one stored alert,
+// or the stored alerts of a time range
+type RuleTest struct {
+	Event *domain.FrigateEvent
+	// At is when the event is evaluated; zero means now
+	At      time.Time
+	AlertID string
+	From    time.Time
+	To      time.Time
+	// Limit caps the alerts replayed from a time range; zero means the default
+	Limit int
+}
+
+// TestRules traces what the alerter would do under the current rules, without saving or sending anything.
+// It returns domain.ErrInvalidRuleTest unless the test names exactly one of an event, an alert or a time range,
+// and domain.ErrAlertNotFound for an unknown alert. Time ranges are replayed oldest first.
+func (s *AlertService) TestRules(test RuleTest) ([]*domain.DecisionTrace, error) {
+	ranged := !test.From.IsZero() || !test.To.IsZero()
+	given := 0
+	for _, ok := range []bool{test.Event != nil, test.AlertID != "", ranged} {
+		if ok {
+			given++
+		}
+	}
+	if given != 1 {
+		return nil, fmt.Errorf("%w: give exactly one of an event, an alert ID or a time range", domain.ErrInvalidRuleTest)
+	}
+	if test.Limit < 0 || test.Limit > maxRuleTestAlerts {
+		return nil, fmt.Errorf("%w: limit must be at most %d", domain.ErrInvalidRuleTest, maxRuleTestAlerts)
+	}
+
+	switch {
+	case test.Event != nil:
+		at := test.At
+		if at.IsZero() {
+			at = time.Now()
+		}
+		return []*domain.DecisionTrace{s.traceEvent(test.Event, at)}, nil
+	case test.AlertID != "":
+		stored, err := s.repository.GetAlert(test.AlertID)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.DecisionTrace{s.traceStoredAlert(stored)}, nil
+	}
+
+	limit := test.Limit
+	if limit == 0 {
+		limit = defaultRuleTestAlerts
+	}
+	alerts, err := s.repository.FindAlerts(domain.AlertFilter{From: test.From, To: test.To, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(alerts)
+	traces := []*domain.DecisionTrace{}
+	for _, stored := range alerts {
+		traces = append(traces, s.traceStoredAlert(stored))
+	}
+	return traces, nil
+}
+
+// traceEvent follows a Frigate event through ProcessEvent at time at. What the alerter remembers about
+// tracked objects is only read, so a dry run does not change how later events are handled.
+func (s *AlertService) traceEvent(event *domain.FrigateEvent, at time.Time) *domain.DecisionTrace {
+	trace := &domain.DecisionTrace{Event: event}
+	object := event.Object()
+	rejection := s.geometryRejection(object)
+
+	var alert *domain.Alert
+	switch {
+	case event.Type == "end":
+		trace.Add(domain.CheckEvent, domain.OutcomeReject, "end events raise no alert")
+	case rejection != "":
+		detail := rejection
+		if event.Type == "new" {
+			detail += "; the object alerts with the first update whose box passes"
+		}
+		trace.Add(domain.CheckFilters, domain.OutcomeReject, detail)
+	case event.Type == "new":
+		trace.Add(domain.CheckFilters, domain.OutcomePass, "passes the detection filters and masks")
+		alert = s.newAlert(&event.Before)
+	case s.isHeldBack(object.ID):
+		trace.Add(domain.CheckFilters, domain.OutcomePass, "held back by its box until now, passes the detection filters and masks")
+		alert = s.newAlert(object)
+	default:
+		trace.Add(domain.CheckFilters, domain.OutcomePass, "passes the detection filters and masks")
+		if match, err := s.watchlist.Match(object, at); err == nil && match.Raises() {
+			trace.Add(domain.CheckEvent, domain.OutcomeMatch, "recognized "+match.Describe()+"; alerts unless the object carried it before")
+			alert = s.recognizedAlert(object)
+		} else {
+			trace.Add(domain.CheckEvent, domain.OutcomeReject, "updates only alert for objects held back by their box or newly recognized as denylisted or unknown")
+		}
+	}
+
+	if len(s.config.Loitering) > 0 || len(s.config.ZoneSequences) > 0 {
+		trace.Add(domain.CheckDetectors, domain.OutcomeSkip, "loitering and zone sequence rules follow objects across events and are not part of a dry run")
+	}
+	if alert != nil {
+		alert.TriggeredAt = at.In(s.config.Location)
+		s.traceAlert(trace, alert, object)
+	}
+	if trace.Notifications == nil {
+		trace.Notifications = []domain.PlannedNotification{}
+	}
+	return trace
+}
+
+// traceStoredAlert replays a stored alert at the time it was raised. Stored alerts keep no box, zones, score
+// or identity of their object, so the rules depending on those are not replayed.
+func (s *AlertService) traceStoredAlert(stored *domain.Alert) *domain.DecisionTrace {
+	trace := &domain.DecisionTrace{AlertID: stored.ID, Notifications: []domain.PlannedNotification{}}
+	trace.Add(domain.CheckFilters, domain.OutcomeSkip, "stored alerts keep no box, zones, score or face and plate, so filters and masks are not rechecked and rules on them do not match")
+
+	alert := &domain.Alert{
+		ID:           stored.ID,
+		Type:         stored.Type,
+		CameraName:   stored.CameraName,
+		Label:        stored.Label,
+		EventID:      stored.EventID,
+		TriggeredAt:  stored.TriggeredAt,
+		AlertMessage: stored.AlertMessage,
+	}
+	s.traceAlert(trace, alert, &domain.FrigateBefore{ID: stored.EventID, Camera: stored.CameraName, Label: stored.Label})
+	return trace
+}
+
+// traceAlert records the decisions about an alert on the trace and the notifications they lead to
+func (s *AlertService) traceAlert(trace *domain.DecisionTrace, alert *domain.Alert, object *domain.FrigateBefore) {
+	incident, isNewIncident := s.decide(alert, object, trace)
+	trace.Alert = alert
+
+	switch {
+	case alert.SuppressedBy == domain.SuppressedByQuietHours:
+		trace.Notifications = append(trace.Notifications, domain.PlannedNotification{Notifier: config.NotifierDiscord, Detail: "listed in the digest when quiet hours end"})
+		return
+	case alert.SuppressedBy != "":
+		return
+	case incident != nil && (isNewIncident || incident.MessageID == ""):
+		trace.Notifications = append(trace.Notifications, domain.PlannedNotification{Notifier: config.NotifierDiscord, Detail: "new incident message"})
+	case incident != nil:
+		trace.Notifications = append(trace.Notifications, domain.PlannedNotification{Notifier: config.NotifierDiscord, Detail: "update of the message of incident " + incident.ID})
+	default:
+		trace.Notifications = append(trace.Notifications, domain.PlannedNotification{Notifier: config.NotifierDiscord, Detail: "alert message"})
+	}
+
+	if policy := escalationPolicy(s.config, alert); policy != nil {
+		for i, step := range policy.Steps {
+			trace.Notifications = append(trace.Notifications, domain.PlannedNotification{
+				Notifier: step.Notifier,
+				After:    step.After,
+				Detail:   fmt.Sprintf("escalation %s step %d of %d unless acknowledged", policy.Name, i+1, len(policy.Steps)),
+			})
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package application
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// escalationActor is recorded in the audit trail for escalation steps
+const escalationActor = "escalation"
+
+// staleEscalationAge bounds how late a last escalation step is still sent, e.g. after the alerter was down
+const staleEscalationAge = 15 * time.Minute
+
+// EscalationService periodically re-notifies about alerts nobody acknowledged, following the escalation policies.
+// Progress is stored with each alert, so restarts neither repeat nor skip steps.
+type EscalationService struct {
+	repository ports.AlertRepository
+	notifiers  map[string]ports.AlertNotifier
+	config     *config.Config
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// NewEscalationService creates a new escalation service with the notifiers available to escalation steps by name
+func NewEscalationService(repository ports.AlertRepository, notifiers map[string]ports.AlertNotifier, config *config.Config) *EscalationService {
+	return &EscalationService{
+		repository: repository,
+		notifiers:  notifiers,
+		config:     config,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Run checks for due escalation steps every escalation interval until Stop is called
+func (s *EscalationService) Run() {
+	defer close(s.done)
+	if len(s.config.Escalations) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.EscalationCheckInterval)
+	defer ticker.Stop()
+
+	slog.Info("Escalation scheduler started", "policies", len(s.config.Escalations), "interval", s.config.EscalationCheckInterval)
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Check(time.Now()); err != nil {
+				slog.Error("Failed to check escalations", "error", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Stop ends the scheduler and waits for a running check to finish
+func (s *EscalationService) Stop() {
+	close(s.stop)
+	<-s.done
+}
+
+// Check sends the escalation steps that are due at the given time.
+// When several steps of an alert became due at once, only the latest is sent. A step that cannot
+// be recorded is logged and sent again on the next check, without holding back the other alerts.
+func (s *EscalationService) Check(now time.Time) error {
+	var longest time.Duration
+	for _, policy := range s.config.Escalations {
+		longest = max(longest, policy.Steps[len(policy.Steps)-1].Delay)
+	}
+	if longest == 0 {
+		return nil
+	}
+
+	alerts, err := s.repository.GetUnacknowledgedAlerts(now.Add(-(longest + staleEscalationAge)))
+	if err != nil {
+		return err
+	}
+
+	for _, alert := range alerts {
+		policy := escalationPolicy(s.config, alert)
+		if policy == nil {
+			continue
+		}
+		level := 0
+		for _, step := range policy.Steps {
+			if !alert.TriggeredAt.Add(step.Delay).After(now) {
+				level++
+			}
+		}
+		if level <= alert.EscalationLevel {
+			continue
+		}
+		if err := s.escalate(alert, policy, level, now); err != nil {
+			slog.Error("Failed to record escalation", "error", err, "alert_id", alert.ID, "policy", policy.Name, "step", level)
+		}
+	}
+	return nil
+}
+
+// escalate sends a step of a policy and records the new level, also when sending failed,
+// so a broken notifier does not stall the chain
+func (s *EscalationService) escalate(alert *domain.Alert, policy *config.EscalationPolicy, level int, now time.Time) error {
+	step := policy.Steps[level-1]
+	reminder := *alert
+	reminder.AlertMessage = fmt.Sprintf("Not acknowledged after %s: %s", step.After, alert.AlertMessage)
+
+	entry := &domain.AuditEntry{
+		AlertID:   alert.ID,
+		Action:    domain.AuditEscalated,
+		Actor:     escalationActor,
+		Detail:    fmt.Sprintf("%s: step %d of %d via %s", policy.Name, level, len(policy.Steps), step.Notifier),
+		CreatedAt: now,
+	}
+	if err := s.notifiers[step.Notifier].SendAlert(&reminder); err != nil {
+		slog.Error("Failed to send escalation", "error", err, "alert_id", alert.ID, "policy", policy.Name, "step", level, "notifier", step.Notifier)
+		entry.Action = domain.AuditEscalationFailed
+		entry.Detail += ": " + err.Error()
+	} else {
+		slog.Info("Alert escalated", "alert_id", alert.ID, "policy", policy.Name, "step", level, "notifier", step.Notifier)
+	}
+
+	return s.repository.SetEscalationLevel(alert.ID, level, entry)
+}
+
+// escalationPolicy returns the first escalation policy matching the alert, or nil.
+// Manual snapshots were requested by someone already looking and are never escalated.
+func escalationPolicy(config *config.Config, alert *domain.Alert) *config.EscalationPolicy {
+	if alert.Type == domain.AlertTypeManual {
+		return nil
+	}
+	for i := range config.Escalations {
+		policy := &config.Escalations[i]
+		if !matchesAny(policy.Cameras, alert.CameraName) || !matchesAny(policy.Labels, alert.Label) || !matchesAny(policy.Modes, alert.Mode) {
+			continue
+		}
+		if policy.Condition == nil || policy.Condition.Eval(conditionEnv(alert, nil, config.Location)) {
+			return policy
+		}
+	}
+	return nil
+}
+
+// matchesAny reports whether value is in the list; an empty list matches every value
+func matchesAny(list []string, value string) bool {
+	return len(list) == 0 || slices.Contains(list, value)
+}
@@ -0,0 +1,192 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// fakeEscalationRepository keeps alerts in memory; it implements only what the escalation service uses
+type fakeEscalationRepository struct {
+	ports.AlertRepository
+	alerts  []*domain.Alert
+	entries []*domain.AuditEntry
+	// failing makes recording an escalation of the alert with this ID fail
+	failing string
+}
+
+func (r *fakeEscalationRepository) GetUnacknowledgedAlerts(since time.Time) ([]*domain.Alert, error) {
+	var alerts []*domain.Alert
+	for _, alert := range r.alerts {
+		if alert.AcknowledgedAt == nil && !alert.FalsePositive && alert.SuppressedBy == "" && !alert.TriggeredAt.Before(since) {
+			copied := *alert
+			alerts = append(alerts, &copied)
+		}
+	}
+	return alerts, nil
+}
+
+func (r *fakeEscalationRepository) SetEscalationLevel(id string, level int, entry *domain.AuditEntry) error {
+	if id == r.failing {
+		return errors.New("database is locked")
+	}
+	for _, alert := range r.alerts {
+		if alert.ID == id && alert.EscalationLevel < level && alert.AcknowledgedAt == nil {
+			alert.EscalationLevel = level
+			r.entries = append(r.entries, entry)
+		}
+	}
+	return nil
+}
+
+// fakeNotifier records the escalations it is asked to send, failing them when err is set
+type fakeNotifier struct {
+	sent []*domain.Alert
+	err  error
+}
+
+func (n *fakeNotifier) SendAlert(alert *domain.Alert) error {
+	n.sent = append(n.sent, alert)
+	return n.err
+}
+
+// newTestEscalationService creates an escalation service with a policy escalating on Discord after 5 minutes
+// and by email after 15 minutes
+func newTestEscalationService(repository *fakeEscalationRepository, discord, email *fakeNotifier) *application.EscalationService {
+	cfg := &config.Config{
+		Location: time.UTC,
+		Escalations: []config.EscalationPolicy{{
+			Name: "unanswered",
+			Steps: []config.EscalationStep{
+				{After: "5m", Notifier: config.NotifierDiscord, Delay: 5 * time.Minute},
+				{After: "15m", Notifier: config.NotifierEmail, Delay: 15 * time.Minute},
+			},
+		}},
+	}
+	notifiers := map[string]ports.AlertNotifier{config.NotifierDiscord: discord, config.NotifierEmail: email}
+	return application.NewEscalationService(repository, notifiers, cfg)
+}
+
+func TestEscalationServiceSteps(t *testing.T) {
+	start := time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC)
+	alert := &domain.Alert{ID: "1", Type: "new", CameraName: "front_door", Label: "person", EventID: "e1", TriggeredAt: start}
+	repository := &fakeEscalationRepository{alerts: []*domain.Alert{alert}}
+	discord, email := &fakeNotifier{}, &fakeNotifier{}
+	service := newTestEscalationService(repository, discord, email)
+
+	tests := []struct {
+		after   time.Duration
+		level   int
+		discord int
+		email   int
+	}{
+		{time.Minute, 0, 0, 0},
+		{5 * time.Minute, 1, 1, 0},
+		{10 * time.Minute, 1, 1, 0},
+		{15 * time.Minute, 2, 1, 1},
+		{20 * time.Minute, 2, 1, 1},
+	}
+	for _, test := range tests {
+		if err := service.Check(start.Add(test.after)); err != nil {
+			t.Fatalf("check after %s: %v", test.after, err)
+		}
+		if alert.EscalationLevel != test.level || len(discord.sent) != test.discord || len(email.sent) != test.email {
+			t.Errorf("after %s: level %d with %d discord and %d email escalations, want level %d with %d and %d",
+				test.after, alert.EscalationLevel, len(discord.sent), len(email.sent), test.level, test.discord, test.email)
+		}
+	}
+	if len(repository.entries) != 2 || repository.entries[1].Action != domain.AuditEscalated || repository.entries[1].Detail != "unanswered: step 2 of 2 via email" {
+		t.Errorf("audited %+v, want two escalation steps", repository.entries)
+	}
+}
+
+func TestEscalationServiceSendsLatestDueStep(t *testing.T) {
+	start := time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC)
+	alert := &domain.Alert{ID: "1", Type: "new", CameraName: "front_door", Label: "person", EventID: "e1", TriggeredAt: start}
+	repository := &fakeEscalationRepository{alerts: []*domain.Alert{alert}}
+	discord, email := &fakeNotifier{}, &fakeNotifier{}
+	service := newTestEscalationService(repository, discord, email)
+
+	// After downtime both steps are due; only the email is sent
+	if err := service.Check(start.Add(16 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if alert.EscalationLevel != 2 || len(discord.sent) != 0 || len(email.sent) != 1 {
+		t.Errorf("level %d with %d discord and %d email escalations, want only the email step", alert.EscalationLevel, len(discord.sent), len(email.sent))
+	}
+}
+
+func TestEscalationServiceStopsWhenAcknowledged(t *testing.T) {
+	start := time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC)
+	alert := &domain.Alert{ID: "1", Type: "new", CameraName: "front_door", Label: "person", EventID: "e1", TriggeredAt: start}
+	repository := &fakeEscalationRepository{alerts: []*domain.Alert{alert}}
+	discord, email := &fakeNotifier{}, &fakeNotifier{}
+	service := newTestEscalationService(repository, discord, email)
+
+	if err := service.Check(start.Add(5 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	acknowledgedAt := start.Add(7 * time.Minute)
+	alert.AcknowledgedAt = &acknowledgedAt
+	if err := service.Check(start.Add(15 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if alert.EscalationLevel != 1 || len(email.sent) != 0 {
+		t.Errorf("level %d with %d email escalations after the acknowledgement, want level 1 and none", alert.EscalationLevel, len(email.sent))
+	}
+}
+
+func TestEscalationServiceContinuesAfterFailures(t *testing.T) {
+	start := time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC)
+	unrecorded := &domain.Alert{ID: "1", Type: "new", CameraName: "front_door", Label: "person", EventID: "e1", TriggeredAt: start}
+	alert := &domain.Alert{ID: "2", Type: "new", CameraName: "driveway", Label: "car", EventID: "e2", TriggeredAt: start.Add(time.Minute)}
+	repository := &fakeEscalationRepository{alerts: []*domain.Alert{unrecorded, alert}, failing: "1"}
+	discord, email := &fakeNotifier{err: errors.New("discord is down")}, &fakeNotifier{}
+	service := newTestEscalationService(repository, discord, email)
+
+	// The first alert's step cannot be recorded and Discord fails, yet the second alert still escalates
+	if err := service.Check(start.Add(6 * time.Minute)); err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if len(discord.sent) != 2 || alert.EscalationLevel != 1 || unrecorded.EscalationLevel != 0 {
+		t.Fatalf("sent %d escalations, levels %d and %d, want 2 sent and only the second alert at level 1",
+			len(discord.sent), unrecorded.EscalationLevel, alert.EscalationLevel)
+	}
+	if len(repository.entries) != 1 || repository.entries[0].Action != domain.AuditEscalationFailed {
+		t.Errorf("audited %+v, want the failed escalation of the second alert", repository.entries)
+	}
+}
+
+func TestEscalationServiceAlertTypes(t *testing.T) {
+	start := time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		alert     *domain.Alert
+		escalated bool
+	}{
+		{"detection", &domain.Alert{ID: "1", Type: "new", CameraName: "driveway", Label: "person", EventID: "e1", TriggeredAt: start}, true},
+		// Count and anomaly alerts belong to no Frigate event
+		{"count", &domain.Alert{ID: "2", Type: domain.AlertTypeCount, CameraName: "driveway", Label: "person", TriggeredAt: start}, true},
+		{"anomaly", &domain.Alert{ID: "4", Type: domain.AlertTypeAnomaly, CameraName: "driveway", TriggeredAt: start}, true},
+		{"manual snapshot", &domain.Alert{ID: "3", Type: domain.AlertTypeManual, CameraName: "driveway", TriggeredAt: start}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := &fakeEscalationRepository{alerts: []*domain.Alert{test.alert}}
+			discord := &fakeNotifier{}
+			service := newTestEscalationService(repository, discord, &fakeNotifier{})
+
+			if err := service.Check(start.Add(5 * time.Minute)); err != nil {
+				t.Fatal(err)
+			}
+			if escalated := len(discord.sent) == 1 && test.alert.EscalationLevel == 1; escalated != test.escalated {
+				t.Errorf("escalated %v, want %v", escalated, test.escalated)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// eventQueueSize is the number of events buffered between the MQTT handler and the worker
+const eventQueueSize = 100
+
+// EventQueue decouples event delivery from processing so that in-flight events can be drained on shutdown
+type EventQueue struct {
+	service ports.AlertService
+	events  chan *domain.FrigateEvent
+	// mu makes accepting an event and starting the shutdown mutually exclusive
+	mu sync.Mutex
+	// senders counts the Enqueue calls waiting for room, which the drain waits for
+	senders  sync.WaitGroup
+	stopping chan struct{}
+	abort    chan struct{}
+	done     chan struct{}
+}
+
+// NewEventQueue creates a new event queue that feeds the given alert service
+func NewEventQueue(service ports.AlertService) *EventQueue {
+	return &EventQueue{
+		service:  service,
+		events:   make(chan *domain.FrigateEvent, eventQueueSize),
+		stopping: make(chan struct{}),
+		abort:    make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Enqueue adds an event to the queue, blocking while the queue is full.
+// Events offered after shutdown has started are logged and dropped.
+func (q *EventQueue) Enqueue(event *domain.FrigateEvent) {
+	q.mu.Lock()
+	select {
+	case <-q.stopping:
+		q.mu.Unlock()
+		logUndelivered(event, "queue is shutting down")
+		return
+	default:
+	}
+	select {
+	case q.events <- event:
+		q.mu.Unlock()
+		return
+	default:
+	}
+	q.senders.Add(1)
+	q.mu.Unlock()
+	defer q.senders.Done()
+
+	// The queue is full; wait for room without holding up the shutdown
+	select {
+	case q.events <- event:
+	case <-q.stopping:
+		logUndelivered(event, "queue is shutting down")
+	}
+}
+
+// Run processes queued events until Shutdown is called and the queue has been drained
+func (q *EventQueue) Run() {
+	defer close(q.done)
+
+	for {
+		select {
+		case event := <-q.events:
+			q.process(event)
+		case <-q.stopping:
+			// Senders waiting for room return once stopping is closed, having queued or dropped their event
+			q.senders.Wait()
+			q.drain()
+			return
+		}
+	}
+}
+
+// drain processes the events that were queued before shutdown started
+func (q *EventQueue) drain() {
+	for {
+		select {
+		case <-q.abort:
+			return
+		default:
+		}
+
+		select {
+		case event := <-q.events:
+			q.process(event)
+		default:
+			return
+		}
+	}
+}
+
+// process hands a single event to the alert service
+func (q *EventQueue) process(event *domain.FrigateEvent) {
+	if err := q.service.ProcessEvent(event); err != nil {
+		slog.Error("Error processing event", "error", err, "event_type", event.Type)
+	}
+}
+
+// Shutdown stops accepting events and waits for queued events to be processed.
+// If the context expires first, the remaining events are logged as undelivered.
+func (q *EventQueue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	close(q.stopping)
+	q.mu.Unlock()
+	slog.Info("Draining event queue", "pending", len(q.events))
+
+	select {
+	case <-q.done:
+		slog.Info("Event queue drained")
+		return nil
+	case <-ctx.Done():
+		close(q.abort)
+	}
+
+	// Log whatever the worker did not get to before the deadline
+	q.dropRemaining("shutdown deadline exceeded")
+	slog.Warn("Event queue not drained before shutdown deadline")
+	return ctx.Err()
+}
+
+// dropRemaining logs the events left in the queue as undelivered
+func (q *EventQueue) dropRemaining(reason string) {
+	for {
+		select {
+		case event := <-q.events:
+			logUndelivered(event, reason)
+		default:
+			return
+		}
+	}
+}
+
+// logUndelivered records an event that will not be processed
+func logUndelivered(event *domain.FrigateEvent, reason string) {
+	slog.Warn("Event not processed",
+		"reason", reason,
+		"event_type", event.Type,
+		"event_id", event.Before.ID,
+		"camera", event.Before.Camera,
+	)
+}
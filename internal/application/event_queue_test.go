@@ -0,0 +1,149 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// recordingAlertService records the events it processes; while blocked is open, each event waits for it to close
+type recordingAlertService struct {
+	mu        sync.Mutex
+	processed []string
+	blocked   chan struct{}
+}
+
+func (s *recordingAlertService) ProcessEvent(event *domain.FrigateEvent) error {
+	if s.blocked != nil {
+		<-s.blocked
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processed = append(s.processed, event.Before.ID)
+	return nil
+}
+
+func (s *recordingAlertService) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.processed)
+}
+
+// captureLogs sends the default logger's output to a buffer until the test ends
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &logs
+}
+
+func testEvent(id int) *domain.FrigateEvent {
+	return &domain.FrigateEvent{Type: "new", Before: domain.FrigateBefore{ID: fmt.Sprint(id), Camera: "driveway"}}
+}
+
+func TestEventQueueDrainsOnShutdown(t *testing.T) {
+	service := &recordingAlertService{blocked: make(chan struct{})}
+	queue := NewEventQueue(service)
+	go queue.Run()
+
+	for i := range 5 {
+		queue.Enqueue(testEvent(i))
+	}
+	// The worker is busy with the first event when shutdown starts
+	close(service.blocked)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := queue.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+	if service.count() != 5 {
+		t.Errorf("processed %d events, want all 5 queued before shutdown", service.count())
+	}
+}
+
+func TestEventQueueDropsAfterDeadline(t *testing.T) {
+	logs := captureLogs(t)
+	service := &recordingAlertService{blocked: make(chan struct{})}
+	queue := NewEventQueue(service)
+	go queue.Run()
+
+	for i := range 4 {
+		queue.Enqueue(testEvent(i))
+	}
+	// The worker stays stuck on the first event past the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := queue.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("shutdown error = %v, want the deadline", err)
+	}
+	close(service.blocked)
+	<-queue.done
+
+	if len(queue.events) != 0 {
+		t.Errorf("%d events left in the queue", len(queue.events))
+	}
+	dropped := strings.Count(logs.String(), "reason=\"shutdown deadline exceeded\"")
+	if service.count() != 1 || dropped != 3 {
+		t.Errorf("processed %d and dropped %d events, want the first processed and the other 3 logged as dropped", service.count(), dropped)
+	}
+}
+
+func TestEventQueueRejectsAfterShutdown(t *testing.T) {
+	logs := captureLogs(t)
+	service := &recordingAlertService{}
+	queue := NewEventQueue(service)
+	go queue.Run()
+
+	if err := queue.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+	// The queue has room, yet the event is not accepted
+	queue.Enqueue(testEvent(1))
+	if len(queue.events) != 0 || service.count() != 0 {
+		t.Errorf("accepted an event after shutdown")
+	}
+	if !strings.Contains(logs.String(), "reason=\"queue is shutting down\"") {
+		t.Errorf("the rejected event was not logged: %s", logs.String())
+	}
+}
+
+func TestEventQueueWaitingSenders(t *testing.T) {
+	service := &recordingAlertService{blocked: make(chan struct{})}
+	queue := NewEventQueue(service)
+	go queue.Run()
+
+	// Fill the queue while the worker is stuck, and let more senders wait for room
+	for i := range eventQueueSize {
+		queue.Enqueue(testEvent(i))
+	}
+	var senders sync.WaitGroup
+	for i := range 3 {
+		senders.Add(1)
+		go func() {
+			defer senders.Done()
+			queue.Enqueue(testEvent(eventQueueSize + i))
+		}()
+	}
+
+	shutdown := make(chan error)
+	go func() { shutdown <- queue.Shutdown(context.Background()) }()
+	senders.Wait()
+	close(service.blocked)
+	if err := <-shutdown; err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+	// Every waiting sender either queued its event, which was then processed, or dropped it
+	if len(queue.events) != 0 || service.count() < eventQueueSize {
+		t.Errorf("processed %d events with %d left in the queue, want at least %d and none left", service.count(), len(queue.events), eventQueueSize)
+	}
+}
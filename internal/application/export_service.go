@@ -0,0 +1,217 @@
+package application
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// ExportFormat is a file format alerts can be exported in
+type ExportFormat string
+
+const (
+	// ExportCSV writes one CSV row per alert
+	ExportCSV ExportFormat = "csv"
+	// ExportNDJSON writes one JSON object per line
+	ExportNDJSON ExportFormat = "ndjson"
+	// ExportZIP writes a ZIP archive with a JSON file and the archived snapshot of every alert
+	ExportZIP ExportFormat = "zip"
+)
+
+// ExportFormats lists the supported export formats
+var ExportFormats = []ExportFormat{ExportCSV, ExportNDJSON, ExportZIP}
+
+// ParseExportFormat validates an export format name
+func ParseExportFormat(name string) (ExportFormat, error) {
+	for _, format := range ExportFormats {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported export format %q, use csv, ndjson or zip", name)
+}
+
+// csvHeader names the columns of a CSV export
+var csvHeader = []string{
+	"id", "type", "camera_name", "label", "event_id", "triggered_at", "alert_message",
+	"acknowledged_by", "acknowledged_at", "false_positive", "severity",
+}
+
+// exportedAlert is an alert as written to a ZIP export, pointing at its snapshot inside the archive
+type exportedAlert struct {
+	*domain.Alert
+	Snapshot string `json:"snapshot,omitempty"`
+}
+
+// ExportService writes alert history to files that can be handed over to third parties
+type ExportService struct {
+	repository ports.AlertRepository
+	snapshots  ports.SnapshotProvider
+	config     *config.Config
+}
+
+// NewExportService creates a new export service
+func NewExportService(repository ports.AlertRepository, snapshots ports.SnapshotProvider, config *config.Config) *ExportService {
+	return &ExportService{
+		repository: repository,
+		snapshots:  snapshots,
+		config:     config,
+	}
+}
+
+// Export streams the alerts matching the filter to w, oldest first.
+// Alerts are written as they are read, so exports of any size use constant memory.
+func (s *ExportService) Export(ctx context.Context, w io.Writer, format ExportFormat, filter domain.AlertFilter) error {
+	slog.Info("Exporting alerts", "format", format, "camera", filter.Camera, "label", filter.Label, "from", filter.From, "to", filter.To)
+
+	var count int
+	var err error
+	switch format {
+	case ExportCSV:
+		count, err = s.exportCSV(ctx, w, filter)
+	case ExportNDJSON:
+		count, err = s.exportNDJSON(ctx, w, filter)
+	case ExportZIP:
+		count, err = s.exportZIP(ctx, w, filter)
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("export failed after %d alerts: %w", count, err)
+	}
+
+	slog.Info("Alerts exported", "format", format, "count", count)
+	return nil
+}
+
+// exportCSV writes a header row followed by one row per alert
+func (s *ExportService) exportCSV(ctx context.Context, w io.Writer, filter domain.AlertFilter) (int, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err := s.repository.StreamAlerts(ctx, filter, func(alert *domain.Alert) error {
+		count++
+		acknowledgedAt := ""
+		if alert.AcknowledgedAt != nil {
+			acknowledgedAt = alert.AcknowledgedAt.In(s.config.Location).Format(time.RFC3339)
+		}
+		return writer.Write([]string{
+			alert.ID,
+			alert.Type,
+			alert.CameraName,
+			alert.Label,
+			alert.EventID,
+			alert.TriggeredAt.In(s.config.Location).Format(time.RFC3339),
+			alert.AlertMessage,
+			alert.AcknowledgedBy,
+			acknowledgedAt,
+			strconv.FormatBool(alert.FalsePositive),
+			alert.Severity,
+		})
+	})
+	if err != nil {
+		return count, err
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
+
+// exportNDJSON writes one JSON encoded alert per line
+func (s *ExportService) exportNDJSON(ctx context.Context, w io.Writer, filter domain.AlertFilter) (int, error) {
+	encoder := json.NewEncoder(w)
+	count := 0
+	err := s.repository.StreamAlerts(ctx, filter, func(alert *domain.Alert) error {
+		count++
+		alert.TriggeredAt = alert.TriggeredAt.In(s.config.Location)
+		return encoder.Encode(alert)
+	})
+	return count, err
+}
+
+// exportZIP writes alerts/<id>.json for every alert and snapshots/<id>.jpg for those Frigate still has a snapshot of
+func (s *ExportService) exportZIP(ctx context.Context, w io.Writer, filter domain.AlertFilter) (int, error) {
+	archive := zip.NewWriter(w)
+	count := 0
+	err := s.repository.StreamAlerts(ctx, filter, func(alert *domain.Alert) error {
+		count++
+		alert.TriggeredAt = alert.TriggeredAt.In(s.config.Location)
+		name := archiveName(alert.ID)
+		record := exportedAlert{Alert: alert}
+
+		if alert.EventID != "" {
+			snapshotPath := "snapshots/" + name + ".jpg"
+			written, err := s.writeSnapshot(ctx, archive, snapshotPath, alert)
+			if err != nil {
+				return err
+			}
+			if written {
+				record.Snapshot = snapshotPath
+			}
+		}
+
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     "alerts/" + name + ".json",
+			Method:   zip.Deflate,
+			Modified: alert.TriggeredAt,
+		})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(record)
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, archive.Close()
+}
+
+// writeSnapshot copies the archived snapshot of an alert into the archive.
+// A snapshot Frigate no longer has is skipped, while errors writing the archive abort the export.
+func (s *ExportService) writeSnapshot(ctx context.Context, archive *zip.Writer, path string, alert *domain.Alert) (bool, error) {
+	snapshot, err := s.snapshots.GetEventSnapshot(ctx, alert.EventID)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		slog.Warn("Snapshot not available for export", "alert_id", alert.ID, "event_id", alert.EventID, "error", err)
+		return false, nil
+	}
+	defer snapshot.Close()
+
+	// JPEG data does not compress any further, so it is stored as is
+	file, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     path,
+		Method:   zip.Store,
+		Modified: alert.TriggeredAt,
+	})
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(file, snapshot); err != nil {
+		return false, fmt.Errorf("failed to copy snapshot of alert %s: %w", alert.ID, err)
+	}
+	return true, nil
+}
+
+// archiveName turns an alert ID into a file name that cannot escape its directory in the archive
+func archiveName(id string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(id)
+}
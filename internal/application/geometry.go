@@ -0,0 +1,81 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// geometryRejection returns why the box of a detected object fails the detection filters or camera mask,
+// or "" to keep it. Objects without a box are kept, as there is nothing to judge them by.
+func (s *AlertService) geometryRejection(object *domain.FrigateBefore) string {
+	box, area := object.Box, object.Area
+	if len(box) != 4 {
+		box, area = object.Snapshot.Box, object.Snapshot.Area
+	}
+	if len(box) != 4 {
+		return ""
+	}
+	width, height := box[2]-box[0], box[3]-box[1]
+	if area <= 0 {
+		area = width * height
+	}
+	ratio := 0.0
+	if height > 0 {
+		ratio = float64(width) / float64(height)
+	}
+
+	// Objects stand on the bottom edge of their box, so that is where they are
+	var x, y float64
+	frame, framed := s.config.CameraFrames[object.Camera]
+	if framed {
+		x = float64(box[0]+box[2]) / 2 / float64(frame.Width)
+		y = float64(box[3]) / float64(frame.Height)
+	}
+
+	for i, filter := range s.config.DetectionFilters {
+		if !matchesAny(filter.Cameras, object.Camera) || !matchesAny(filter.Labels, object.Label) {
+			continue
+		}
+		switch {
+		case area < filter.MinArea || filter.MaxArea > 0 && area > filter.MaxArea:
+			return fmt.Sprintf("area %d outside detection filter %d", area, i+1)
+		case ratio < filter.MinRatio || filter.MaxRatio > 0 && ratio > filter.MaxRatio:
+			return fmt.Sprintf("aspect ratio %.2f outside detection filter %d", ratio, i+1)
+		case filter.Position != nil && framed &&
+			(x < filter.Position.MinX || x > filter.Position.MaxX || y < filter.Position.MinY || y > filter.Position.MaxY):
+			return fmt.Sprintf("position %.2f,%.2f outside detection filter %d", x, y, i+1)
+		}
+	}
+
+	mask, masked := s.config.CameraMasks[object.Camera]
+	if !masked || !framed {
+		return ""
+	}
+	for _, polygon := range mask.Exclude {
+		if insidePolygon(x, y, polygon) {
+			return fmt.Sprintf("position %.2f,%.2f inside an exclude mask", x, y)
+		}
+	}
+	if len(mask.Include) == 0 {
+		return ""
+	}
+	for _, polygon := range mask.Include {
+		if insidePolygon(x, y, polygon) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("position %.2f,%.2f outside the include masks", x, y)
+}
+
+// insidePolygon reports whether a point lies inside a polygon, by counting how often a ray to its right crosses an edge
+func insidePolygon(x, y float64, polygon [][2]float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a[1] > y) != (b[1] > y) && x < (b[0]-a[0])*(y-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,70 @@
+package application
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestInsidePolygon(t *testing.T) {
+	square := [][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	// An L shape whose notch at the top right is outside
+	ell := [][2]float64{{0, 0}, {0.5, 0}, {0.5, 0.5}, {1, 0.5}, {1, 1}, {0, 1}}
+
+	tests := []struct {
+		name    string
+		x, y    float64
+		polygon [][2]float64
+		want    bool
+	}{
+		{"centre of square", 0.5, 0.5, square, true},
+		{"left of square", -0.1, 0.5, square, false},
+		{"right of square", 1.1, 0.5, square, false},
+		{"below square", 0.5, 1.1, square, false},
+		{"inside the L", 0.25, 0.25, ell, true},
+		{"inside the foot of the L", 0.75, 0.75, ell, true},
+		{"in the notch of the L", 0.75, 0.25, ell, false},
+		{"empty polygon", 0.5, 0.5, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := insidePolygon(tt.x, tt.y, tt.polygon); got != tt.want {
+				t.Errorf("insidePolygon(%v, %v) = %v, want %v", tt.x, tt.y, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeometryRejection(t *testing.T) {
+	service := &AlertService{config: &config.Config{
+		CameraFrames:     map[string]config.FrameSize{"driveway": {Width: 1000, Height: 500}},
+		DetectionFilters: []config.DetectionFilter{{Labels: []string{"person"}, MinArea: 2500, MaxRatio: 1}},
+		// The street runs along the top fifth of the frame
+		CameraMasks: map[string]config.CameraMask{"driveway": {Exclude: [][][2]float64{{{0, 0}, {1, 0}, {1, 0.2}, {0, 0.2}}}}},
+	}}
+
+	tests := []struct {
+		name   string
+		object domain.FrigateBefore
+		// want is a part of the rejection reason, or "" to keep the object
+		want string
+	}{
+		{"nearby person", domain.FrigateBefore{Camera: "driveway", Label: "person", Box: []int{500, 300, 560, 420}}, ""},
+		{"distant person", domain.FrigateBefore{Camera: "driveway", Label: "person", Box: []int{500, 200, 510, 230}}, "area 300 outside detection filter 1"},
+		{"lying person", domain.FrigateBefore{Camera: "driveway", Label: "person", Box: []int{500, 300, 620, 360}}, "aspect ratio 2.00"},
+		{"car on the street", domain.FrigateBefore{Camera: "driveway", Label: "car", Snapshot: domain.FrigateSnapshot{Box: []int{100, 20, 300, 90}}}, "inside an exclude mask"},
+		{"car on the driveway", domain.FrigateBefore{Camera: "driveway", Label: "car", Box: []int{100, 200, 300, 400}}, ""},
+		{"without a box", domain.FrigateBefore{Camera: "driveway", Label: "person"}, ""},
+		{"camera without a frame", domain.FrigateBefore{Camera: "porch", Label: "car", Box: []int{100, 20, 300, 90}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := service.geometryRejection(&tt.object)
+			if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+				t.Errorf("geometryRejection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
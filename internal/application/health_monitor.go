@@ -0,0 +1,110 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// healthMonitorInterval is how often dependencies are checked for outages
+const healthMonitorInterval = time.Minute
+
+// HealthMonitor checks the dependencies periodically and records the times they were unhealthy as outages,
+// which the activity reports list
+type HealthMonitor struct {
+	health     *HealthService
+	repository ports.HealthRepository
+	config     *config.Config
+	// open holds the ongoing outage of each unhealthy dependency
+	open map[string]*domain.HealthOutage
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewHealthMonitor creates a new health monitor
+func NewHealthMonitor(health *HealthService, repository ports.HealthRepository, config *config.Config) *HealthMonitor {
+	return &HealthMonitor{
+		health:     health,
+		repository: repository,
+		config:     config,
+		open:       make(map[string]*domain.HealthOutage),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Run checks the dependencies every monitor interval until Stop is called.
+// Outages still open from before a restart are continued.
+func (m *HealthMonitor) Run() {
+	defer close(m.done)
+
+	now := time.Now()
+	outages, err := m.repository.GetHealthOutages(now, now)
+	if err != nil {
+		slog.Error("Failed to load ongoing outages", "error", err)
+	}
+	for _, outage := range outages {
+		if outage.Ended == nil {
+			m.open[outage.Dependency] = &outage
+		}
+	}
+
+	ticker := time.NewTicker(healthMonitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			m.Check(context.Background(), now)
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// Stop ends the periodic checks and waits for a running check to finish
+func (m *HealthMonitor) Stop() {
+	close(m.stop)
+	<-m.done
+}
+
+// Check opens an outage for each dependency that turned unhealthy and ends those of dependencies that recovered
+func (m *HealthMonitor) Check(ctx context.Context, now time.Time) {
+	report := m.health.Readiness(ctx)
+	now = now.In(m.config.Location)
+
+	for _, status := range report.Dependencies {
+		outage := m.open[status.Name]
+		switch {
+		case !status.Healthy && outage == nil:
+			outage = &domain.HealthOutage{
+				ID:         fmt.Sprintf("%s_%d", status.Name, now.UnixNano()),
+				Dependency: status.Name,
+				State:      status.State,
+				Detail:     status.Detail,
+				Started:    now,
+			}
+			slog.Warn("Dependency outage started", "dependency", status.Name, "state", status.State, "detail", status.Detail)
+		case status.Healthy && outage != nil:
+			outage.Ended = &now
+			slog.Info("Dependency outage ended", "dependency", status.Name, "duration", now.Sub(outage.Started).Truncate(time.Second))
+		default:
+			continue
+		}
+
+		if err := m.repository.SaveHealthOutage(outage); err != nil {
+			// The outage is retried with the next check
+			slog.Error("Failed to record outage", "error", err, "dependency", status.Name)
+			continue
+		}
+		if outage.Ended == nil {
+			m.open[status.Name] = outage
+		} else {
+			delete(m.open, status.Name)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// healthCheckTimeout bounds how long a single dependency check may take
+const healthCheckTimeout = 3 * time.Second
+
+// HealthService aggregates dependency health checks into a readiness report
+type HealthService struct {
+	checkers []ports.HealthChecker
+	config   *config.Config
+}
+
+// NewHealthService creates a new health service
+func NewHealthService(config *config.Config, checkers ...ports.HealthChecker) *HealthService {
+	return &HealthService{
+		checkers: checkers,
+		config:   config,
+	}
+}
+
+// Readiness checks every dependency concurrently and applies the configured readiness criteria
+func (s *HealthService) Readiness(ctx context.Context) *domain.ReadinessReport {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	statuses := make([]domain.DependencyStatus, len(s.checkers))
+	var wg sync.WaitGroup
+	for i, checker := range s.checkers {
+		wg.Add(1)
+		go func(i int, checker ports.HealthChecker) {
+			defer wg.Done()
+			statuses[i] = checker.CheckHealth(ctx)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	now := time.Now().In(s.config.Location)
+	report := &domain.ReadinessReport{
+		Ready:        true,
+		CheckedAt:    now,
+		Dependencies: statuses,
+	}
+
+	for i := range report.Dependencies {
+		status := &report.Dependencies[i]
+		s.applyMessageAge(status, now)
+		status.Required = s.isRequired(status.Name)
+		if status.Required && !status.Healthy {
+			report.Ready = false
+		}
+	}
+
+	return report
+}
+
+// applyMessageAge marks a dependency unhealthy when its last message is older than the configured limit
+func (s *HealthService) applyMessageAge(status *domain.DependencyStatus, now time.Time) {
+	if status.LastMessageAt == nil {
+		return
+	}
+
+	age := now.Sub(*status.LastMessageAt).Truncate(time.Second)
+	status.LastMessageAge = age.String()
+
+	if s.config.MaxMessageAge > 0 && age > s.config.MaxMessageAge && status.Healthy {
+		status.Healthy = false
+		status.Detail = fmt.Sprintf("no message received for %s (limit %s)", age, s.config.MaxMessageAge)
+	}
+}
+
+// isRequired reports whether the dependency counts towards readiness
+func (s *HealthService) isRequired(name string) bool {
+	for _, required := range s.config.HealthRequired {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package application_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// fakeChecker reports a fixed state, standing in for the MQTT subscriber, the Discord notifier and Frigate
+type fakeChecker struct {
+	status domain.DependencyStatus
+}
+
+func (c *fakeChecker) CheckHealth(ctx context.Context) domain.DependencyStatus {
+	return c.status
+}
+
+func TestHealthServiceReadiness(t *testing.T) {
+	recent := time.Now().Add(-time.Minute)
+	stale := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		required  []string
+		mqtt      domain.DependencyStatus
+		discord   domain.DependencyStatus
+		frigate   domain.DependencyStatus
+		ready     bool
+		unhealthy []string
+	}{
+		{
+			name:     "everything healthy",
+			required: []string{"mqtt", "discord", "frigate"},
+			mqtt:     domain.DependencyStatus{Name: "mqtt", Healthy: true, State: domain.StateConnected, LastMessageAt: &recent},
+			discord:  domain.DependencyStatus{Name: "discord", Healthy: true, State: domain.StateConnected},
+			frigate:  domain.DependencyStatus{Name: "frigate", Healthy: true, State: domain.StateConnected},
+			ready:    true,
+		},
+		{
+			name:      "required dependency down",
+			required:  []string{"mqtt", "discord", "frigate"},
+			mqtt:      domain.DependencyStatus{Name: "mqtt", Healthy: true, State: domain.StateConnected, LastMessageAt: &recent},
+			discord:   domain.DependencyStatus{Name: "discord", Healthy: false, State: domain.StateDisconnected},
+			frigate:   domain.DependencyStatus{Name: "frigate", Healthy: true, State: domain.StateConnected},
+			ready:     false,
+			unhealthy: []string{"discord"},
+		},
+		{
+			name:      "optional dependency down",
+			required:  []string{"mqtt", "discord"},
+			mqtt:      domain.DependencyStatus{Name: "mqtt", Healthy: true, State: domain.StateConnected, LastMessageAt: &recent},
+			discord:   domain.DependencyStatus{Name: "discord", Healthy: true, State: domain.StateConnected},
+			frigate:   domain.DependencyStatus{Name: "frigate", Healthy: false, State: domain.StateUnreachable},
+			ready:     true,
+			unhealthy: []string{"frigate"},
+		},
+		{
+			name:      "stale messages",
+			required:  []string{"mqtt", "discord", "frigate"},
+			mqtt:      domain.DependencyStatus{Name: "mqtt", Healthy: true, State: domain.StateConnected, LastMessageAt: &stale},
+			discord:   domain.DependencyStatus{Name: "discord", Healthy: true, State: domain.StateConnected},
+			frigate:   domain.DependencyStatus{Name: "frigate", Healthy: true, State: domain.StateConnected},
+			ready:     false,
+			unhealthy: []string{"mqtt"},
+		},
+		{
+			name:      "stale messages of an optional dependency",
+			required:  []string{"discord", "frigate"},
+			mqtt:      domain.DependencyStatus{Name: "mqtt", Healthy: true, State: domain.StateConnected, LastMessageAt: &stale},
+			discord:   domain.DependencyStatus{Name: "discord", Healthy: true, State: domain.StateConnected},
+			frigate:   domain.DependencyStatus{Name: "frigate", Healthy: true, State: domain.StateConnected},
+			ready:     true,
+			unhealthy: []string{"mqtt"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &config.Config{Location: time.UTC, HealthRequired: test.required, MaxMessageAge: 30 * time.Minute}
+			service := application.NewHealthService(cfg, &fakeChecker{test.mqtt}, &fakeChecker{test.discord}, &fakeChecker{test.frigate})
+
+			report := service.Readiness(context.Background())
+			if report.Ready != test.ready {
+				t.Errorf("ready = %v, want %v", report.Ready, test.ready)
+			}
+			var unhealthy []string
+			for _, status := range report.Dependencies {
+				if !status.Healthy {
+					unhealthy = append(unhealthy, status.Name)
+				}
+			}
+			if !slices.Equal(unhealthy, test.unhealthy) {
+				t.Errorf("unhealthy = %v, want %v", unhealthy, test.unhealthy)
+			}
+		})
+	}
+}
+
+func TestHealthServiceMessageAge(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   time.Duration
+		age     time.Duration
+		healthy bool
+	}{
+		{"within the limit", 30 * time.Minute, 29 * time.Minute, true},
+		{"beyond the limit", 30 * time.Minute, 31 * time.Minute, false},
+		{"check disabled", 0, 24 * time.Hour, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &config.Config{Location: time.UTC, HealthRequired: []string{"mqtt"}, MaxMessageAge: test.limit}
+			last := time.Now().Add(-test.age)
+			service := application.NewHealthService(cfg, &fakeChecker{domain.DependencyStatus{Name: "mqtt", Healthy: true, LastMessageAt: &last}})
+
+			status := service.Readiness(context.Background()).Dependencies[0]
+			if status.Healthy != test.healthy {
+				t.Errorf("healthy = %v (%s), want %v", status.Healthy, status.Detail, test.healthy)
+			}
+			if status.LastMessageAge != test.age.String() {
+				t.Errorf("last message age = %s, want %s", status.LastMessageAge, test.age)
+			}
+			if !status.Required {
+				t.Error("mqtt is not marked as required")
+			}
+		})
+	}
+}
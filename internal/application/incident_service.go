@@ -0,0 +1,140 @@
+package application
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// IncidentService groups alerts on adjacent cameras into incidents and keeps one notification per incident up to date
+type IncidentService struct {
+	repository ports.IncidentRepository
+	alerts     ports.AlertRepository
+	notifier   ports.IncidentNotifier
+	config     *config.Config
+}
+
+// NewIncidentService creates a new incident service
+func NewIncidentService(
+	repository ports.IncidentRepository,
+	alerts ports.AlertRepository,
+	notifier ports.IncidentNotifier,
+	config *config.Config,
+) *IncidentService {
+	return &IncidentService{
+		repository: repository,
+		alerts:     alerts,
+		notifier:   notifier,
+		config:     config,
+	}
+}
+
+// Enabled reports whether camera adjacency is configured, without which alerts are not grouped
+func (s *IncidentService) Enabled() bool {
+	return len(s.config.CameraAdjacency) > 0
+}
+
+// Correlate adds an alert to the most recently active incident that saw the same or an adjacent camera within the
+// incident window, or starts a new incident. It links the alert to the incident; Notify stores the incident.
+func (s *IncidentService) Correlate(alert *domain.Alert) (*domain.Incident, bool, error) {
+	recent, err := s.repository.GetRecentIncidents(alert.TriggeredAt.Add(-s.config.IncidentGap))
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, incident := range recent {
+		if !s.reaches(incident, alert.CameraName) {
+			continue
+		}
+		if incident.Cameras[len(incident.Cameras)-1] != alert.CameraName {
+			incident.Cameras = append(incident.Cameras, alert.CameraName)
+		}
+		if alert.Label != "" && !slices.Contains(incident.Labels, alert.Label) {
+			incident.Labels = append(incident.Labels, alert.Label)
+		}
+		if domain.SeverityRank(alert.Severity) > domain.SeverityRank(incident.Severity) {
+			// Edited messages notify nobody, so an incident turning critical is posted anew
+			if alert.Severity == domain.SeverityCritical {
+				incident.MessageID = ""
+			}
+			incident.Severity = alert.Severity
+		}
+		incident.LastAlertAt = alert.TriggeredAt
+		incident.AlertCount++
+		alert.IncidentID = incident.ID
+		return incident, false, nil
+	}
+
+	incident := &domain.Incident{
+		ID:          fmt.Sprintf("incident_%d", alert.TriggeredAt.UnixNano()),
+		StartedAt:   alert.TriggeredAt,
+		LastAlertAt: alert.TriggeredAt,
+		Cameras:     []string{alert.CameraName},
+		Labels:      []string{},
+		AlertCount:  1,
+		Severity:    alert.Severity,
+	}
+	if alert.Label != "" {
+		incident.Labels = append(incident.Labels, alert.Label)
+	}
+	alert.IncidentID = incident.ID
+	return incident, true, nil
+}
+
+// Notify posts the notification of a new incident or updates the one of an ongoing incident, then stores the incident.
+// The incident is stored even when notifying fails, so later alerts keep joining it.
+func (s *IncidentService) Notify(incident *domain.Incident, alert *domain.Alert, isNew bool) error {
+	var notifyErr error
+	switch {
+	case isNew || incident.MessageID == "":
+		var messageID string
+		messageID, notifyErr = s.notifier.SendIncident(incident, alert)
+		if notifyErr == nil {
+			incident.MessageID = messageID
+		}
+	default:
+		notifyErr = s.notifier.UpdateIncident(incident, alert, incident.MessageID)
+	}
+
+	if err := s.repository.SaveIncident(incident); err != nil {
+		slog.Error("Failed to save incident", "error", err, "incident_id", incident.ID)
+		return err
+	}
+	return notifyErr
+}
+
+// GetIncidents retrieves incidents, most recently active first
+func (s *IncidentService) GetIncidents(limit int, offset int) ([]*domain.Incident, error) {
+	return s.repository.GetIncidents(limit, offset)
+}
+
+// GetIncidentDetails retrieves an incident with its alerts, oldest first
+func (s *IncidentService) GetIncidentDetails(id string) (*domain.IncidentDetails, error) {
+	incident, err := s.repository.GetIncident(id)
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := s.alerts.FindAlerts(domain.AlertFilter{Incident: id})
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(alerts)
+	if alerts == nil {
+		alerts = []*domain.Alert{}
+	}
+	return &domain.IncidentDetails{Incident: incident, Alerts: alerts}, nil
+}
+
+// reaches reports whether an object seen during the incident can have moved to the camera
+func (s *IncidentService) reaches(incident *domain.Incident, camera string) bool {
+	for _, seen := range incident.Cameras {
+		if seen == camera || s.config.Adjacent(seen, camera) {
+			return true
+		}
+	}
+	return false
+}
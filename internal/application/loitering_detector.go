@@ -0,0 +1,160 @@
+package application
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// loiteringCheckInterval is how often objects that stopped sending updates are checked for loitering
+const loiteringCheckInterval = 10 * time.Second
+
+// staleTrackAge is how long the detectors track an object without updates, in case its end event was missed
+const staleTrackAge = time.Hour
+
+// Loitering reports an object that stayed in the zones of a loitering rule for longer than the rule allows
+type Loitering struct {
+	Rule   *config.LoiteringRule
+	Object domain.FrigateBefore
+	// Zone is a zone of the rule the object was last seen in
+	Zone  string
+	Since time.Time
+}
+
+// loiteringTrack is the state of one tracked object
+type loiteringTrack struct {
+	object   domain.FrigateBefore
+	lastSeen time.Time
+	// entered holds, per rule index, when the object entered the zones of the rule
+	entered map[int]time.Time
+	// reported holds the rules the object was already reported for; an object is reported once per rule
+	reported map[int]bool
+}
+
+// LoiteringDetector follows Frigate's update events and measures how long each object stays in the zones
+// of the loitering rules. The state of an object is cleared by its end event.
+type LoiteringDetector struct {
+	config *config.Config
+	mu     sync.Mutex
+	tracks map[string]*loiteringTrack
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewLoiteringDetector creates a new loitering detector
+func NewLoiteringDetector(config *config.Config) *LoiteringDetector {
+	return &LoiteringDetector{
+		config: config,
+		tracks: make(map[string]*loiteringTrack),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Observe records the latest state of the object of an event and returns the loitering it caused
+func (d *LoiteringDetector) Observe(event *domain.FrigateEvent, now time.Time) []Loitering {
+	if len(d.config.Loitering) == 0 {
+		return nil
+	}
+	object := event.Object()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if event.Type == "end" {
+		delete(d.tracks, object.ID)
+		return nil
+	}
+
+	track, ok := d.tracks[object.ID]
+	if !ok {
+		track = &loiteringTrack{entered: make(map[int]time.Time), reported: make(map[int]bool)}
+		d.tracks[object.ID] = track
+	}
+	track.object = *object
+	track.lastSeen = now
+
+	for i, rule := range d.config.Loitering {
+		inside := matchesAny(rule.Cameras, object.Camera) && matchesAny(rule.Labels, object.Label) &&
+			slices.ContainsFunc(rule.Zones, func(zone string) bool { return slices.Contains(object.CurrentZones, zone) })
+		if !inside {
+			// Leaving the zones restarts the clock
+			delete(track.entered, i)
+			continue
+		}
+		if _, ok := track.entered[i]; !ok {
+			track.entered[i] = now
+		}
+	}
+
+	for id, stale := range d.tracks {
+		if now.Sub(stale.lastSeen) > staleTrackAge {
+			slog.Debug("Forgetting object without updates", "event_id", id)
+			delete(d.tracks, id)
+		}
+	}
+
+	return d.due(track, now)
+}
+
+// Due returns the loitering of tracked objects that became due without a new update, such as stationary objects
+func (d *LoiteringDetector) Due(now time.Time) []Loitering {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var loitering []Loitering
+	for _, track := range d.tracks {
+		loitering = append(loitering, d.due(track, now)...)
+	}
+	return loitering
+}
+
+// Run hands loitering that becomes due between updates to handle every check interval until Stop is called
+func (d *LoiteringDetector) Run(handle func(loitering Loitering)) {
+	defer close(d.done)
+	if len(d.config.Loitering) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(loiteringCheckInterval)
+	defer ticker.Stop()
+
+	slog.Info("Loitering detector started", "rules", len(d.config.Loitering))
+	for {
+		select {
+		case now := <-ticker.C:
+			for _, loitering := range d.Due(now) {
+				handle(loitering)
+			}
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// Stop ends the periodic checks and waits for a running check to finish
+func (d *LoiteringDetector) Stop() {
+	close(d.stop)
+	<-d.done
+}
+
+// due marks and returns the rules an object has now been in the zones of for longer than they allow
+func (d *LoiteringDetector) due(track *loiteringTrack, now time.Time) []Loitering {
+	var loitering []Loitering
+	for i, since := range track.entered {
+		rule := &d.config.Loitering[i]
+		if track.reported[i] || now.Sub(since) < rule.Dwell {
+			continue
+		}
+		track.reported[i] = true
+		zone := rule.Zones[slices.IndexFunc(rule.Zones, func(zone string) bool {
+			return slices.Contains(track.object.CurrentZones, zone)
+		})]
+		loitering = append(loitering, Loitering{Rule: rule, Object: track.object, Zone: zone, Since: since})
+	}
+	return loitering
+}
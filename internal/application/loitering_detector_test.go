@@ -0,0 +1,77 @@
+package application
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestLoiteringDetector(t *testing.T) {
+	cfg := &config.Config{Loitering: []config.LoiteringRule{
+		{Labels: []string{"person"}, Zones: []string{"porch", "steps"}, After: "2m", Dwell: 2 * time.Minute},
+	}}
+	detector := NewLoiteringDetector(cfg)
+	start := time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+
+	object := func(id string, label string, zones ...string) domain.FrigateBefore {
+		return domain.FrigateBefore{ID: id, Camera: "front_door", Label: label, CurrentZones: zones}
+	}
+	steps := []struct {
+		name  string
+		event *domain.FrigateEvent
+		after time.Duration
+		// want holds the event IDs reported as loitering, nil for an event means none
+		want []string
+	}{
+		{"person appears on the porch", &domain.FrigateEvent{Type: "new", After: object("1", "person", "porch")}, 0, nil},
+		{"car on the porch is not watched", &domain.FrigateEvent{Type: "new", After: object("2", "car", "porch")}, 0, nil},
+		{"person passing by", &domain.FrigateEvent{Type: "new", After: object("3", "person", "porch")}, 0, nil},
+		{"person moves to the steps", &domain.FrigateEvent{Type: "update", After: object("1", "person", "steps")}, time.Minute, nil},
+		{"passer-by leaves the zones", &domain.FrigateEvent{Type: "update", After: object("3", "person")}, 90 * time.Second, nil},
+		{"not due yet", nil, 2*time.Minute - time.Second, nil},
+		{"due without an update", nil, 2 * time.Minute, []string{"1"}},
+		{"passer-by comes back", &domain.FrigateEvent{Type: "update", After: object("3", "person", "porch")}, 3 * time.Minute, nil},
+		{"reported once", &domain.FrigateEvent{Type: "update", After: object("1", "person", "steps")}, 4 * time.Minute, nil},
+		{"clock restarted on coming back", nil, 5*time.Minute - time.Second, nil},
+		{"passer-by due after coming back", nil, 5 * time.Minute, []string{"3"}},
+		{"new object ends early", &domain.FrigateEvent{Type: "new", After: object("4", "person", "porch")}, 5 * time.Minute, nil},
+		{"end forgets the object", &domain.FrigateEvent{Type: "end", After: object("4", "person", "porch")}, 6 * time.Minute, nil},
+		{"ended object is not due", nil, 10 * time.Minute, nil},
+	}
+	for _, step := range steps {
+		now := start.Add(step.after)
+		var loitering []Loitering
+		if step.event != nil {
+			loitering = detector.Observe(step.event, now)
+		} else {
+			loitering = detector.Due(now)
+		}
+
+		var got []string
+		for _, l := range loitering {
+			got = append(got, l.Object.ID)
+		}
+		if !slices.Equal(got, step.want) {
+			t.Fatalf("%s: loitering %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestLoiteringDetectorReportsZone(t *testing.T) {
+	cfg := &config.Config{Loitering: []config.LoiteringRule{{Zones: []string{"porch", "steps"}, After: "1m", Dwell: time.Minute}}}
+	detector := NewLoiteringDetector(cfg)
+	start := time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+
+	person := domain.FrigateBefore{ID: "1", Camera: "front_door", Label: "person", CurrentZones: []string{"driveway", "steps"}}
+	detector.Observe(&domain.FrigateEvent{Type: "new", After: person}, start)
+	loitering := detector.Observe(&domain.FrigateEvent{Type: "update", After: person}, start.Add(time.Minute))
+	if len(loitering) != 1 {
+		t.Fatalf("loitering = %+v, want one report", loitering)
+	}
+	if loitering[0].Zone != "steps" || !loitering[0].Since.Equal(start) {
+		t.Errorf("loitering in %s since %s, want the steps since %s", loitering[0].Zone, loitering[0].Since, start)
+	}
+}
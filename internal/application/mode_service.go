@@ -0,0 +1,210 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// modeAuto clears a manual mode when sent as a mode command
+const modeAuto = "auto"
+
+// ModeService decides which arming mode is in effect and whether it lets an alert through.
+// A manual mode takes precedence over the alarm panel and presence, which take precedence over
+// the schedule, which takes precedence over the default mode.
+type ModeService struct {
+	repository ports.ModeRepository
+	presence   *PresenceService
+	config     *config.Config
+}
+
+// NewModeService creates a new mode service
+func NewModeService(repository ports.ModeRepository, presence *PresenceService, config *config.Config) *ModeService {
+	return &ModeService{
+		repository: repository,
+		presence:   presence,
+		config:     config,
+	}
+}
+
+// Enabled reports whether any modes are configured
+func (s *ModeService) Enabled() bool {
+	return len(s.config.Modes) > 0
+}
+
+// Current returns the mode in effect at time t
+func (s *ModeService) Current(t time.Time) (*domain.ModeState, error) {
+	if !s.Enabled() {
+		return &domain.ModeState{Source: domain.ModeSourceDisabled, Modes: []string{}}, nil
+	}
+	t = t.In(s.config.Location)
+	state := &domain.ModeState{Modes: s.modeNames()}
+	if len(s.presence.Topics()) > 0 {
+		state.Presence, state.AlarmPanel = s.presence.States()
+	}
+
+	override, err := s.repository.GetModeOverride()
+	if err != nil {
+		return nil, err
+	}
+	// A mode that was removed from the configuration since it was set no longer applies
+	if override != nil && (override.Until == nil || t.Before(*override.Until)) && s.isMode(override.Mode) {
+		state.Mode = override.Mode
+		state.Source = domain.ModeSourceManual
+		state.Until = override.Until
+		state.SetBy = override.SetBy
+		return state, nil
+	}
+
+	if mode, source := s.presence.Mode(); mode != "" {
+		state.Mode = mode
+		state.Source = source
+		return state, nil
+	}
+
+	for _, window := range s.config.Schedule {
+		if until, ok := windowEnd(window.TimeWindow, t); ok {
+			state.Mode = window.Mode
+			state.Source = domain.ModeSourceSchedule
+			state.Until = &until
+			return state, nil
+		}
+	}
+
+	state.Mode = s.config.DefaultMode
+	state.Source = domain.ModeSourceDefault
+	return state, nil
+}
+
+// SetMode sets a manual mode until the given time, or until it is cleared if until is nil
+func (s *ModeService) SetMode(mode string, until *time.Time, user string) (*domain.ModeState, error) {
+	now := time.Now().In(s.config.Location)
+	if !s.isMode(mode) {
+		return nil, fmt.Errorf("%w: %q is not configured, use one of %s", domain.ErrInvalidMode, mode, strings.Join(s.modeNames(), ", "))
+	}
+	if until != nil && !until.After(now) {
+		return nil, fmt.Errorf("%w: the manual mode must end in the future", domain.ErrInvalidMode)
+	}
+
+	override := &domain.ModeOverride{Mode: mode, Until: until, SetBy: user, SetAt: now}
+	if err := s.repository.SaveModeOverride(override); err != nil {
+		return nil, err
+	}
+	slog.Info("Mode set manually", "mode", mode, "until", until, "user", user)
+	return s.Current(now)
+}
+
+// ClearMode removes the manual mode so the schedule or default mode applies again
+func (s *ModeService) ClearMode() (*domain.ModeState, error) {
+	if err := s.repository.DeleteModeOverride(); err != nil {
+		return nil, err
+	}
+	slog.Info("Manual mode cleared")
+	return s.Current(time.Now())
+}
+
+// Allows reports whether alerts of the camera and label are armed in the given mode.
+// A camera/label rule takes precedence over a camera rule, which takes precedence over the mode's default.
+func (s *ModeService) Allows(mode string, camera string, label string) bool {
+	modeConfig, ok := s.config.Modes[mode]
+	if !ok {
+		return true
+	}
+	if allowed, ok := modeConfig.Rules[camera+"/"+label]; ok && label != "" {
+		return allowed
+	}
+	if allowed, ok := modeConfig.Rules[camera]; ok {
+		return allowed
+	}
+	return modeConfig.Default
+}
+
+// HandleCommand sets the mode from an MQTT command. The payload is either a mode name, "auto" to clear
+// the manual mode, or a JSON object like {"mode": "away", "duration": "2h", "user": "alarm panel"}.
+func (s *ModeService) HandleCommand(topic string, payload []byte) {
+	command := struct {
+		Mode     string `json:"mode"`
+		Duration string `json:"duration"`
+		User     string `json:"user"`
+	}{User: "mqtt"}
+
+	text := strings.TrimSpace(string(payload))
+	if strings.HasPrefix(text, "{") {
+		if err := json.Unmarshal([]byte(text), &command); err != nil {
+			slog.Error("Invalid mode command", "topic", topic, "payload", text, "error", err)
+			return
+		}
+	} else {
+		command.Mode = text
+	}
+
+	var err error
+	if strings.EqualFold(command.Mode, modeAuto) {
+		_, err = s.ClearMode()
+	} else {
+		var until *time.Time
+		if command.Duration != "" {
+			duration, parseErr := time.ParseDuration(command.Duration)
+			if parseErr != nil {
+				slog.Error("Invalid mode command duration", "topic", topic, "duration", command.Duration, "error", parseErr)
+				return
+			}
+			t := time.Now().Add(duration)
+			until = &t
+		}
+		_, err = s.SetMode(command.Mode, until, command.User)
+	}
+	if err != nil {
+		slog.Error("Failed to apply mode command", "topic", topic, "payload", text, "error", err)
+	}
+}
+
+// isMode reports whether a mode is configured
+func (s *ModeService) isMode(mode string) bool {
+	_, ok := s.config.Modes[mode]
+	return ok
+}
+
+// modeNames returns the configured modes in alphabetical order
+func (s *ModeService) modeNames() []string {
+	names := make([]string, 0, len(s.config.Modes))
+	for name := range s.config.Modes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// windowEnd reports whether a time window contains t and, if so, when the window ends
+func windowEnd(window config.TimeWindow, t time.Time) (time.Time, bool) {
+	minute := t.Hour()*60 + t.Minute()
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	endOn := func(day time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), window.EndMinute/60, window.EndMinute%60, 0, 0, t.Location())
+	}
+
+	if window.StartMinute < window.EndMinute {
+		if slices.Contains(window.Weekdays, t.Weekday()) && minute >= window.StartMinute && minute < window.EndMinute {
+			return endOn(today), true
+		}
+		return time.Time{}, false
+	}
+
+	// The window runs past midnight: it covers the evening of its start day and the morning after
+	if slices.Contains(window.Weekdays, t.Weekday()) && minute >= window.StartMinute {
+		return endOn(today.AddDate(0, 0, 1)), true
+	}
+	yesterday := today.AddDate(0, 0, -1)
+	if slices.Contains(window.Weekdays, yesterday.Weekday()) && minute < window.EndMinute {
+		return endOn(today), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,118 @@
+package application
+
+import (
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// PresenceService derives the arming mode from presence and alarm panel states published over MQTT.
+// States are only kept in memory; retained messages restore them when the topics are subscribed again.
+type PresenceService struct {
+	config *config.Config
+
+	mu         sync.Mutex
+	people     map[string]string
+	alarmPanel string
+}
+
+// NewPresenceService creates a new presence service
+func NewPresenceService(config *config.Config) *PresenceService {
+	return &PresenceService{
+		config: config,
+		people: make(map[string]string),
+	}
+}
+
+// Topics returns the presence and alarm panel topics to subscribe to
+func (s *PresenceService) Topics() []string {
+	topics := slices.Clone(s.config.PresenceTopics)
+	if s.config.AlarmPanelTopic != "" {
+		topics = append(topics, s.config.AlarmPanelTopic)
+	}
+	return topics
+}
+
+// HandleState records a state published on a presence or alarm panel topic. The payload is either
+// the plain state, as published by Home Assistant's MQTT statestream, or a JSON object with a state field.
+func (s *PresenceService) HandleState(topic string, payload []byte) {
+	state := strings.TrimSpace(string(payload))
+	if strings.HasPrefix(state, "{") {
+		var message struct {
+			State string `json:"state"`
+		}
+		if err := json.Unmarshal(payload, &message); err != nil {
+			slog.Error("Invalid presence state", "topic", topic, "payload", state, "error", err)
+			return
+		}
+		state = message.State
+	}
+	state = strings.ToLower(strings.Trim(state, `"`))
+
+	s.mu.Lock()
+	before, _ := s.mode()
+	if topic == s.config.AlarmPanelTopic {
+		s.alarmPanel = state
+	} else {
+		s.people[topic] = state
+	}
+	after, source := s.mode()
+	s.mu.Unlock()
+
+	slog.Debug("Presence state received", "topic", topic, "state", state)
+	if after != before {
+		slog.Info("Presence changed the mode", "mode", after, "source", source, "topic", topic, "state", state)
+	}
+}
+
+// Mode returns the mode derived from the last reported states and its source, or "" when they decide nothing
+func (s *PresenceService) Mode() (string, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mode()
+}
+
+// States returns the last state of every presence topic and of the alarm panel
+func (s *PresenceService) States() (map[string]string, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	people := make(map[string]string, len(s.people))
+	for topic, state := range s.people {
+		people[topic] = state
+	}
+	return people, s.alarmPanel
+}
+
+// mode derives the mode; s.mu must be held.
+// An alarm panel state with a mode wins. Otherwise anyone at home selects the home mode, while the away
+// mode needs every person to have reported, so a restart does not arm the house before presence is known.
+func (s *PresenceService) mode() (string, string) {
+	if mode, ok := s.config.AlarmPanelModes[s.alarmPanel]; ok {
+		return mode, domain.ModeSourceAlarmPanel
+	}
+	if len(s.config.PresenceTopics) == 0 {
+		return "", ""
+	}
+
+	reported := 0
+	for _, topic := range s.config.PresenceTopics {
+		state, ok := s.people[topic]
+		if !ok {
+			continue
+		}
+		if slices.Contains(s.config.PresenceHomeStates, state) {
+			return s.config.PresenceHomeMode, domain.ModeSourcePresence
+		}
+		reported++
+	}
+	if reported == len(s.config.PresenceTopics) {
+		return s.config.PresenceAwayMode, domain.ModeSourcePresence
+	}
+	return "", ""
+}
@@ -0,0 +1,72 @@
+package application_test
+
+import (
+	"testing"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestPresenceServiceMode(t *testing.T) {
+	const (
+		alice = "homeassistant/person/alice/state"
+		bob   = "homeassistant/person/bob/state"
+		panel = "homeassistant/alarm_control_panel/house/state"
+	)
+	type message struct {
+		topic   string
+		payload string
+	}
+
+	tests := []struct {
+		name     string
+		messages []message
+		mode     string
+		source   string
+	}{
+		{"nothing reported", nil, "", ""},
+		{"one person home", []message{{alice, "home"}}, "home", domain.ModeSourcePresence},
+		// Bob has not reported yet, so the house is not armed
+		{"one person away", []message{{alice, "not_home"}}, "", ""},
+		{"everyone away", []message{{alice, "not_home"}, {bob, "work"}}, "away", domain.ModeSourcePresence},
+		{"someone came back", []message{{alice, "not_home"}, {bob, "not_home"}, {bob, "home"}}, "home", domain.ModeSourcePresence},
+		{"another home state", []message{{alice, "not_home"}, {bob, "garden"}}, "home", domain.ModeSourcePresence},
+		{"JSON payload", []message{{alice, `{"state": "not_home"}`}, {bob, ` "NOT_HOME" `}}, "away", domain.ModeSourcePresence},
+		{"invalid JSON is ignored", []message{{alice, "home"}, {alice, `{"state":`}}, "home", domain.ModeSourcePresence},
+		{"unknown topic is ignored", []message{{alice, "not_home"}, {"homeassistant/person/carol/state", "not_home"}}, "", ""},
+		{"alarm panel wins", []message{{alice, "home"}, {panel, "armed_away"}}, "away", domain.ModeSourceAlarmPanel},
+		{"alarm panel in an unmapped state", []message{{alice, "home"}, {panel, "pending"}}, "home", domain.ModeSourcePresence},
+		{"alarm panel disarmed", []message{{alice, "not_home"}, {bob, "not_home"}, {panel, "Disarmed"}}, "home", domain.ModeSourceAlarmPanel},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &config.Config{
+				PresenceTopics:     []string{alice, bob},
+				PresenceHomeStates: []string{"home", "garden"},
+				PresenceHomeMode:   "home",
+				PresenceAwayMode:   "away",
+				AlarmPanelTopic:    panel,
+				AlarmPanelModes:    map[string]string{"armed_away": "away", "disarmed": "home"},
+			}
+			presence := application.NewPresenceService(cfg)
+			for _, message := range test.messages {
+				presence.HandleState(message.topic, []byte(message.payload))
+			}
+
+			mode, source := presence.Mode()
+			if mode != test.mode || source != test.source {
+				t.Errorf("mode = %q from %q, want %q from %q", mode, source, test.mode, test.source)
+			}
+		})
+	}
+}
+
+func TestPresenceServiceWithoutPresenceTopics(t *testing.T) {
+	presence := application.NewPresenceService(&config.Config{PresenceHomeMode: "home", PresenceAwayMode: "away"})
+	presence.HandleState("homeassistant/person/alice/state", []byte("not_home"))
+
+	if mode, source := presence.Mode(); mode != "" || source != "" {
+		t.Errorf("mode = %q from %q without presence topics, want none", mode, source)
+	}
+}
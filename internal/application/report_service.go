@@ -0,0 +1,236 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// reportCheckInterval is how often the report scheduler checks for due reports
+const reportCheckInterval = time.Minute
+
+// busiestHourCount is how many hours of the day a report lists as the busiest
+const busiestHourCount = 3
+
+// ReportService produces the daily and weekly activity reports and sends them at their configured times
+type ReportService struct {
+	repository ports.AlertRepository
+	outages    ports.HealthRepository
+	notifiers  map[string]ports.ReportNotifier
+	config     *config.Config
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// NewReportService creates a new report service with the notifiers reports can be delivered through by name
+func NewReportService(repository ports.AlertRepository, outages ports.HealthRepository, notifiers map[string]ports.ReportNotifier, config *config.Config) *ReportService {
+	return &ReportService{
+		repository: repository,
+		outages:    outages,
+		notifiers:  notifiers,
+		config:     config,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Run sends each configured report when it is due until Stop is called.
+// Reports due while the alerter was down are not sent late.
+func (s *ReportService) Run() {
+	defer close(s.done)
+
+	next := make(map[string]time.Time)
+	for _, period := range s.scheduled() {
+		next[period] = s.nextReport(period, time.Now())
+		slog.Info("Report scheduled", "period", period, "next", next[period])
+	}
+	if len(next) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(reportCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			for period, due := range next {
+				if now.Before(due) {
+					continue
+				}
+				if err := s.Send(period, due); err != nil {
+					slog.Error("Failed to send report", "error", err, "period", period)
+				}
+				next[period] = s.nextReport(period, now)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Stop ends the scheduler and waits for a report being sent
+func (s *ReportService) Stop() {
+	close(s.stop)
+	<-s.done
+}
+
+// Send generates the report of the period ending at the given time and delivers it through every report notifier.
+// A failing notifier does not keep the others from receiving the report.
+func (s *ReportService) Send(period string, to time.Time) error {
+	report, err := s.Generate(period, to)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, name := range s.config.ReportNotifiers {
+		notifier, ok := s.notifiers[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("report notifier %s is not available", name))
+			continue
+		}
+		if err := notifier.SendReport(report); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		slog.Info("Report sent", "period", period, "notifier", name, "alerts", report.Total)
+	}
+	return errors.Join(errs...)
+}
+
+// Generate builds the report of the day or week ending at the given time
+func (s *ReportService) Generate(period string, to time.Time) (*domain.Report, error) {
+	days, err := reportDays(period)
+	if err != nil {
+		return nil, err
+	}
+	to = to.In(s.config.Location)
+	from := to.AddDate(0, 0, -days)
+	report := &domain.Report{Period: period, From: from, To: to}
+
+	current, err := s.repository.GetCameraStats(domain.AlertFilter{From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.repository.GetCameraStats(domain.AlertFilter{From: from.AddDate(0, 0, -days), To: from})
+	if err != nil {
+		return nil, err
+	}
+
+	cameras := make(map[string]*domain.ReportCamera)
+	camera := func(name string) *domain.ReportCamera {
+		if cameras[name] == nil {
+			cameras[name] = &domain.ReportCamera{Camera: name, ByLabel: make(map[string]int)}
+		}
+		return cameras[name]
+	}
+	for _, stats := range current {
+		c := camera(stats.Camera)
+		c.Total = stats.Total
+		c.ByLabel = stats.ByLabel
+		report.Total += stats.Total
+	}
+	for _, stats := range previous {
+		camera(stats.Camera).Previous = stats.Total
+		report.Previous += stats.Total
+	}
+	for _, c := range cameras {
+		report.Cameras = append(report.Cameras, *c)
+	}
+	slices.SortFunc(report.Cameras, func(a, b domain.ReportCamera) int {
+		if a.Total != b.Total {
+			return b.Total - a.Total
+		}
+		if a.Camera < b.Camera {
+			return -1
+		}
+		return 1
+	})
+
+	// The heatmap already counts alerts by local hour of day
+	cells, err := s.repository.GetActivityHeatmap(domain.AlertFilter{From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	hours := make([]domain.ReportHour, 24)
+	for i := range hours {
+		hours[i].Hour = i
+	}
+	for _, cell := range cells {
+		hours[cell.Hour].Count += cell.Count
+	}
+	slices.SortStableFunc(hours, func(a, b domain.ReportHour) int {
+		return b.Count - a.Count
+	})
+	for _, hour := range hours[:busiestHourCount] {
+		if hour.Count > 0 {
+			report.BusiestHours = append(report.BusiestHours, hour)
+		}
+	}
+
+	if report.Outages, err = s.outages.GetHealthOutages(from, to); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// LatestEnd returns when the latest report of a period due at or before t ended, or t when the report is not scheduled
+func (s *ReportService) LatestEnd(period string, t time.Time) time.Time {
+	if !slices.Contains(s.scheduled(), period) {
+		return t
+	}
+	days, _ := reportDays(period)
+	next := s.nextReport(period, t)
+	return next.AddDate(0, 0, -days)
+}
+
+// scheduled returns the periods whose reports are configured
+func (s *ReportService) scheduled() []string {
+	var periods []string
+	if s.config.DailyReportAt != "" {
+		periods = append(periods, domain.ReportDaily)
+	}
+	if s.config.WeeklyReportAt != "" {
+		periods = append(periods, domain.ReportWeekly)
+	}
+	return periods
+}
+
+// nextReport returns the first time after t the report of a period is due
+func (s *ReportService) nextReport(period string, t time.Time) time.Time {
+	t = t.In(s.config.Location)
+	minute := s.config.DailyReportMinute
+	if period == domain.ReportWeekly {
+		minute = s.config.WeeklyReportMinute
+	}
+
+	for day := 0; ; day++ {
+		date := t.AddDate(0, 0, day)
+		due := time.Date(date.Year(), date.Month(), date.Day(), minute/60, minute%60, 0, 0, s.config.Location)
+		if !due.After(t) {
+			continue
+		}
+		if period == domain.ReportWeekly && due.Weekday() != s.config.WeeklyReportDay {
+			continue
+		}
+		return due
+	}
+}
+
+// reportDays returns how many days the period of a report covers
+func reportDays(period string) (int, error) {
+	switch period {
+	case domain.ReportDaily:
+		return 1, nil
+	case domain.ReportWeekly:
+		return 7, nil
+	default:
+		return 0, fmt.Errorf("%w %q, use daily or weekly", domain.ErrUnknownReportPeriod, period)
+	}
+}
@@ -0,0 +1,61 @@
+package application_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/application"
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+func TestReportService(t *testing.T) {
+	cfg := &config.Config{Location: time.UTC, ReportNotifiers: []string{config.NotifierDiscord}}
+	repository := newTestRepository(t)
+
+	// Two cars and a person on the driveway this day, one car the day before
+	to := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	for i, alert := range []domain.Alert{
+		{CameraName: "driveway", Label: "car", TriggeredAt: to.Add(-2 * time.Hour)},
+		{CameraName: "driveway", Label: "car", TriggeredAt: to.Add(-2*time.Hour + time.Minute)},
+		{CameraName: "driveway", Label: "person", TriggeredAt: to.Add(-5 * time.Hour)},
+		{CameraName: "driveway", Label: "car", TriggeredAt: to.Add(-30 * time.Hour)},
+	} {
+		alert.ID = fmt.Sprintf("report_%d", i)
+		alert.Type = "new"
+		alert.AlertMessage = "A " + alert.Label + " detected in the driveway camera"
+		if err := repository.SaveAlert(&alert); err != nil {
+			t.Fatalf("failed to save alert: %v", err)
+		}
+	}
+	ended := to.Add(-3 * time.Hour)
+	if err := repository.SaveHealthOutage(&domain.HealthOutage{
+		ID: "discord_1", Dependency: "discord", State: domain.StateDisconnected, Started: to.Add(-4 * time.Hour), Ended: &ended,
+	}); err != nil {
+		t.Fatalf("failed to save outage: %v", err)
+	}
+
+	notifier := &stubNotifier{}
+	service := application.NewReportService(repository, repository, map[string]ports.ReportNotifier{config.NotifierDiscord: notifier}, cfg)
+	if err := service.Send(domain.ReportDaily, to); err != nil {
+		t.Fatalf("failed to send report: %v", err)
+	}
+	if len(notifier.reports) != 1 {
+		t.Fatalf("sent %d reports, want 1", len(notifier.reports))
+	}
+	report := notifier.reports[0]
+	if report.Total != 3 || report.Previous != 1 || report.Change() != "+200%" {
+		t.Errorf("report counts %d alerts after %d (%s), want 3 after 1 (+200%%)", report.Total, report.Previous, report.Change())
+	}
+	if len(report.Cameras) != 1 || report.Cameras[0].ByLabel["car"] != 2 || report.Cameras[0].ByLabel["person"] != 1 {
+		t.Errorf("report cameras = %+v, want the driveway with 2 cars and a person", report.Cameras)
+	}
+	if len(report.BusiestHours) == 0 || report.BusiestHours[0].Hour != 6 || report.BusiestHours[0].Count != 2 {
+		t.Errorf("busiest hours = %+v, want 06:00 with 2 alerts first", report.BusiestHours)
+	}
+	if len(report.Outages) != 1 || report.Outages[0].Dependency != "discord" {
+		t.Errorf("outages = %+v, want the Discord outage", report.Outages)
+	}
+}
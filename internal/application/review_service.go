@@ -0,0 +1,149 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+const (
+	// maxActorLength bounds the name recorded for who reviewed an alert
+	maxActorLength = 100
+	// maxNoteLength bounds the text of a single note
+	maxNoteLength = 4000
+)
+
+// ReviewService handles acknowledging alerts, attaching notes and flagging false positives
+type ReviewService struct {
+	repository ports.AlertRepository
+	frigate    ports.FalsePositiveReporter
+	config     *config.Config
+}
+
+// NewReviewService creates a new review service
+func NewReviewService(repository ports.AlertRepository, frigate ports.FalsePositiveReporter, config *config.Config) *ReviewService {
+	return &ReviewService{
+		repository: repository,
+		frigate:    frigate,
+		config:     config,
+	}
+}
+
+// GetAlertDetails returns an alert with its notes and audit trail
+func (s *ReviewService) GetAlertDetails(id string) (*domain.AlertDetails, error) {
+	alert, err := s.repository.GetAlert(id)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := s.repository.GetAlertNotes(id)
+	if err != nil {
+		return nil, err
+	}
+	audit, err := s.repository.GetAlertAudit(id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.AlertDetails{Alert: alert, Notes: notes, Audit: audit}, nil
+}
+
+// Acknowledge records that actor has seen the alert
+func (s *ReviewService) Acknowledge(id string, actor string) (*domain.AlertDetails, error) {
+	actor, err := validateActor(actor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.AcknowledgeAlert(id, actor, s.now()); err != nil {
+		return nil, err
+	}
+	slog.Info("Alert acknowledged", "alert_id", id, "actor", actor)
+	return s.GetAlertDetails(id)
+}
+
+// AddNote attaches a free-text note to the alert
+func (s *ReviewService) AddNote(id string, actor string, text string) (*domain.AlertDetails, error) {
+	actor, err := validateActor(actor)
+	if err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("%w: note text is required", domain.ErrInvalidReview)
+	}
+	if utf8.RuneCountInString(text) > maxNoteLength {
+		return nil, fmt.Errorf("%w: notes are limited to %d characters", domain.ErrInvalidReview, maxNoteLength)
+	}
+
+	note := &domain.AlertNote{AlertID: id, Author: actor, Text: text, CreatedAt: s.now()}
+	if err := s.repository.AddAlertNote(note); err != nil {
+		return nil, err
+	}
+	slog.Info("Note added to alert", "alert_id", id, "actor", actor, "note_id", note.ID)
+	return s.GetAlertDetails(id)
+}
+
+// SetFalsePositive flags or unflags the alert as a false positive. When syncing is enabled, flagging it
+// is forwarded to Frigate for the underlying event; a failed sync is audited but does not undo the flag.
+func (s *ReviewService) SetFalsePositive(ctx context.Context, id string, actor string, falsePositive bool) (*domain.AlertDetails, error) {
+	actor, err := validateActor(actor)
+	if err != nil {
+		return nil, err
+	}
+	alert, err := s.repository.GetAlert(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.SetFalsePositive(id, actor, falsePositive, s.now()); err != nil {
+		return nil, err
+	}
+	slog.Info("Alert false positive flag changed", "alert_id", id, "actor", actor, "false_positive", falsePositive)
+
+	// Frigate cannot take a false positive back, so only newly flagged alerts are forwarded
+	if falsePositive && !alert.FalsePositive && s.config.FrigateSyncFalsePositives && alert.EventID != "" {
+		s.syncFalsePositive(ctx, alert, actor)
+	}
+
+	return s.GetAlertDetails(id)
+}
+
+// syncFalsePositive forwards a false positive to Frigate and audits the outcome
+func (s *ReviewService) syncFalsePositive(ctx context.Context, alert *domain.Alert, actor string) {
+	entry := &domain.AuditEntry{
+		AlertID: alert.ID,
+		Action:  domain.AuditFrigateSynced,
+		Actor:   actor,
+		Detail:  fmt.Sprintf("event %s", alert.EventID),
+	}
+	if err := s.frigate.ReportFalsePositive(ctx, alert.EventID); err != nil {
+		slog.Warn("Failed to report false positive to Frigate", "alert_id", alert.ID, "event_id", alert.EventID, "error", err)
+		entry.Action = domain.AuditFrigateSyncFailed
+		entry.Detail = err.Error()
+	}
+	entry.CreatedAt = s.now()
+	if err := s.repository.AddAuditEntry(entry); err != nil {
+		slog.Error("Failed to audit Frigate sync", "alert_id", alert.ID, "error", err)
+	}
+}
+
+// now returns the current time in the configured time zone
+func (s *ReviewService) now() time.Time {
+	return time.Now().In(s.config.Location)
+}
+
+// validateActor trims and checks the name of whoever reviews an alert
+func validateActor(actor string) (string, error) {
+	actor = strings.TrimSpace(actor)
+	if actor == "" {
+		return "", fmt.Errorf("%w: user is required", domain.ErrInvalidReview)
+	}
+	if utf8.RuneCountInString(actor) > maxActorLength {
+		return "", fmt.Errorf("%w: user is limited to %d characters", domain.ErrInvalidReview, maxActorLength)
+	}
+	return actor, nil
+}
@@ -0,0 +1,43 @@
+package application
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// severity returns the severity of the first rule matching the alert and the detected object, or the default severity
+func (s *AlertService) severity(alert *domain.Alert, object *domain.FrigateBefore, trace *domain.DecisionTrace) string {
+	score := max(object.Score, object.TopScore)
+	for i, rule := range s.config.SeverityRules {
+		if !matchesAny(rule.Types, alert.Type) || !matchesAny(rule.Cameras, alert.CameraName) ||
+			!matchesAny(rule.Labels, alert.Label) || !matchesAny(rule.Modes, alert.Mode) {
+			continue
+		}
+		if len(rule.Zones) > 0 && !slices.ContainsFunc(rule.Zones, func(zone string) bool {
+			return slices.Contains(object.CurrentZones, zone) || slices.Contains(object.EnteredZones, zone)
+		}) {
+			continue
+		}
+		if score < rule.MinScore {
+			continue
+		}
+		if rule.Schedule != nil {
+			if _, ok := windowEnd(*rule.Schedule, alert.TriggeredAt.In(s.config.Location)); !ok {
+				continue
+			}
+		}
+		if rule.Condition != nil && !rule.Condition.Eval(conditionEnv(alert, object, s.config.Location)) {
+			continue
+		}
+		detail := fmt.Sprintf("severity rule %d sets %s", i+1, rule.Severity)
+		if rule.Condition != nil {
+			detail = fmt.Sprintf("severity rule %d, when %s, sets %s", i+1, rule.Condition, rule.Severity)
+		}
+		trace.Add(domain.CheckSeverity, domain.OutcomeMatch, detail)
+		return rule.Severity
+	}
+	trace.Add(domain.CheckSeverity, domain.OutcomePass, "no severity rule matches, default "+s.config.DefaultSeverity)
+	return s.config.DefaultSeverity
+}
@@ -0,0 +1,95 @@
+package application
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// SnoozeService silences the notifications of cameras, or of single labels on cameras, for a while
+type SnoozeService struct {
+	repository ports.SnoozeRepository
+	config     *config.Config
+}
+
+// NewSnoozeService creates a new snooze service
+func NewSnoozeService(repository ports.SnoozeRepository, config *config.Config) *SnoozeService {
+	return &SnoozeService{
+		repository: repository,
+		config:     config,
+	}
+}
+
+// Snooze silences a camera until the given time; an empty label silences all labels of the camera.
+// Snoozing the same camera and label again replaces the previous expiry.
+func (s *SnoozeService) Snooze(camera string, label string, until time.Time, user string) (*domain.Snooze, error) {
+	now := time.Now().In(s.config.Location)
+	camera = strings.TrimSpace(camera)
+	if camera == "" {
+		return nil, fmt.Errorf("%w: camera is required", domain.ErrInvalidSnooze)
+	}
+	if !until.After(now) {
+		return nil, fmt.Errorf("%w: the snooze must end in the future", domain.ErrInvalidSnooze)
+	}
+
+	snooze := &domain.Snooze{
+		Camera:    camera,
+		Label:     strings.TrimSpace(label),
+		Until:     until.In(s.config.Location),
+		CreatedBy: strings.TrimSpace(user),
+		CreatedAt: now,
+	}
+	if err := s.repository.SaveSnooze(snooze); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Camera snoozed", "camera", snooze.Camera, "label", snooze.Label, "until", snooze.Until, "user", snooze.CreatedBy)
+	return snooze, nil
+}
+
+// Unsnooze ends the snooze of a camera and label early
+func (s *SnoozeService) Unsnooze(camera string, label string) error {
+	if err := s.repository.DeleteSnooze(camera, label); err != nil {
+		return err
+	}
+	slog.Info("Camera snooze removed", "camera", camera, "label", label)
+	return nil
+}
+
+// ActiveSnoozes returns the snoozes in effect for a camera, or for every camera if camera is empty
+func (s *SnoozeService) ActiveSnoozes(camera string) ([]domain.Snooze, error) {
+	snoozes, err := s.repository.GetActiveSnoozes(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if camera == "" {
+		return snoozes, nil
+	}
+
+	filtered := []domain.Snooze{}
+	for _, snooze := range snoozes {
+		if snooze.Camera == camera {
+			filtered = append(filtered, snooze)
+		}
+	}
+	return filtered, nil
+}
+
+// Silencing returns the snooze that silences alerts of the camera and label at time t, or nil if there is none
+func (s *SnoozeService) Silencing(camera string, label string, t time.Time) (*domain.Snooze, error) {
+	snoozes, err := s.ActiveSnoozes(camera)
+	if err != nil {
+		return nil, err
+	}
+	for _, snooze := range snoozes {
+		if snooze.Matches(label, t) {
+			return &snooze, nil
+		}
+	}
+	return nil, nil
+}
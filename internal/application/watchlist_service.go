@@ -0,0 +1,224 @@
+package application
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/ports"
+)
+
+// watchlistUnknown is the list of a match on a plate that is on neither list, reported during the unknown plate hours
+const watchlistUnknown = "unknown"
+
+// WatchlistMatch is a sub label or plate of an object found on a watchlist, or an unknown plate seen at night
+type WatchlistMatch struct {
+	Kind  string
+	Value string
+	// List is domain.WatchlistAllow, domain.WatchlistDeny or "unknown"
+	List string
+	Note string
+}
+
+// Raises reports whether the match makes alerts critical
+func (m *WatchlistMatch) Raises() bool {
+	return m != nil && m.List != domain.WatchlistAllow
+}
+
+// Describe explains the match for alert messages
+func (m *WatchlistMatch) Describe() string {
+	name := "plate"
+	if m.Kind == domain.WatchlistSubLabel {
+		name = "person"
+	}
+	description := fmt.Sprintf("%s %s is on the %slist", name, m.Value, m.List)
+	if m.List == watchlistUnknown {
+		description = fmt.Sprintf("unknown plate %s", m.Value)
+	}
+	if m.Note != "" {
+		description += " (" + m.Note + ")"
+	}
+	return description
+}
+
+// WatchlistService keeps the allowlist and denylist of recognized faces and license plates and matches objects against them
+type WatchlistService struct {
+	repository ports.WatchlistRepository
+	config     *config.Config
+
+	// identities holds, by event ID, the sub labels and plates already seen on an object and when it was last seen
+	mu         sync.Mutex
+	identities map[string]*recognizedIdentities
+}
+
+// recognizedIdentities are the sub labels and plates Frigate attached to one tracked object
+type recognizedIdentities struct {
+	seen     map[string]bool
+	lastSeen time.Time
+}
+
+// NewWatchlistService creates a new watchlist service
+func NewWatchlistService(repository ports.WatchlistRepository, config *config.Config) *WatchlistService {
+	return &WatchlistService{
+		repository: repository,
+		config:     config,
+		identities: make(map[string]*recognizedIdentities),
+	}
+}
+
+// GetEntries returns every watchlist entry
+func (s *WatchlistService) GetEntries() ([]domain.WatchlistEntry, error) {
+	return s.repository.GetWatchlist()
+}
+
+// SetEntry puts a sub label or plate on a list, replacing any previous entry of it
+func (s *WatchlistService) SetEntry(kind string, value string, list string, note string, user string) (*domain.WatchlistEntry, error) {
+	if kind != domain.WatchlistSubLabel && kind != domain.WatchlistPlate {
+		return nil, fmt.Errorf("%w: kind %q must be sub_label or plate", domain.ErrInvalidWatchlistEntry, kind)
+	}
+	if list != domain.WatchlistAllow && list != domain.WatchlistDeny {
+		return nil, fmt.Errorf("%w: list %q must be allow or deny", domain.ErrInvalidWatchlistEntry, list)
+	}
+	value = domain.NormalizeIdentity(kind, value)
+	if value == "" {
+		return nil, fmt.Errorf("%w: value is required", domain.ErrInvalidWatchlistEntry)
+	}
+
+	entry := &domain.WatchlistEntry{
+		Kind:      kind,
+		Value:     value,
+		List:      list,
+		Note:      strings.TrimSpace(note),
+		CreatedBy: strings.TrimSpace(user),
+		CreatedAt: time.Now().In(s.config.Location),
+	}
+	if err := s.repository.SaveWatchlistEntry(entry); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Watchlist entry set", "kind", entry.Kind, "value", entry.Value, "list", entry.List, "user", entry.CreatedBy)
+	return entry, nil
+}
+
+// RemoveEntry takes a sub label or plate off its list, returning domain.ErrWatchlistEntryNotFound if it is on none
+func (s *WatchlistService) RemoveEntry(kind string, value string) error {
+	value = domain.NormalizeIdentity(kind, value)
+	if err := s.repository.DeleteWatchlistEntry(kind, value); err != nil {
+		return err
+	}
+	slog.Info("Watchlist entry removed", "kind", kind, "value", value)
+	return nil
+}
+
+// Match checks the sub label and plate of an object at time t. A denylisted identity wins over an allowlisted one,
+// and an allowlisted one over an unknown plate, so a known person in an unknown car does not raise the alert.
+// It returns nil when the object carries no identity on a list.
+func (s *WatchlistService) Match(object *domain.FrigateBefore, t time.Time) (*WatchlistMatch, error) {
+	identities := objectIdentities(object)
+	if len(identities) == 0 {
+		return nil, nil
+	}
+	entries, err := s.repository.GetWatchlist()
+	if err != nil {
+		return nil, err
+	}
+
+	var denied, allowed, unknown *WatchlistMatch
+	for _, identity := range identities {
+		var entry *domain.WatchlistEntry
+		for i := range entries {
+			if entries[i].Kind == identity.Kind && entries[i].Value == identity.Value {
+				entry = &entries[i]
+				break
+			}
+		}
+
+		match := identity
+		switch {
+		case entry != nil && entry.List == domain.WatchlistDeny:
+			match.List, match.Note = entry.List, entry.Note
+			denied = &match
+		case entry != nil:
+			match.List, match.Note = entry.List, entry.Note
+			allowed = &match
+		case identity.Kind == domain.WatchlistPlate && s.unknownPlateHours(t):
+			match.List = watchlistUnknown
+			unknown = &match
+		}
+	}
+
+	switch {
+	case denied != nil:
+		return denied, nil
+	case allowed != nil:
+		return allowed, nil
+	default:
+		return unknown, nil
+	}
+}
+
+// Recognized reports whether the object of an event carries a sub label or plate not seen on it before.
+// End events forget the object.
+func (s *WatchlistService) Recognized(event *domain.FrigateEvent) bool {
+	object := event.Object()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if event.Type == "end" {
+		delete(s.identities, object.ID)
+		return false
+	}
+
+	now := time.Now()
+	for id, track := range s.identities {
+		if now.Sub(track.lastSeen) > staleTrackAge {
+			delete(s.identities, id)
+		}
+	}
+
+	identities := objectIdentities(object)
+	if len(identities) == 0 {
+		return false
+	}
+	track, ok := s.identities[object.ID]
+	if !ok {
+		track = &recognizedIdentities{seen: make(map[string]bool)}
+		s.identities[object.ID] = track
+	}
+	track.lastSeen = now
+
+	recognized := false
+	for _, identity := range identities {
+		key := identity.Kind + "/" + identity.Value
+		if !track.seen[key] {
+			track.seen[key] = true
+			recognized = true
+		}
+	}
+	return recognized
+}
+
+// unknownPlateHours reports whether unknown plates raise alerts at time t
+func (s *WatchlistService) unknownPlateHours(t time.Time) bool {
+	if s.config.UnknownPlateHours == nil {
+		return false
+	}
+	_, ok := windowEnd(*s.config.UnknownPlateHours, t.In(s.config.Location))
+	return ok
+}
+
+// objectIdentities returns the normalized sub label and plate of an object, as matches without a list
+func objectIdentities(object *domain.FrigateBefore) []WatchlistMatch {
+	var identities []WatchlistMatch
+	if value := domain.NormalizeIdentity(domain.WatchlistSubLabel, string(object.SubLabel)); value != "" {
+		identities = append(identities, WatchlistMatch{Kind: domain.WatchlistSubLabel, Value: value})
+	}
+	if value := domain.NormalizeIdentity(domain.WatchlistPlate, object.RecognizedLicensePlate); value != "" {
+		identities = append(identities, WatchlistMatch{Kind: domain.WatchlistPlate, Value: value})
+	}
+	return identities
+}
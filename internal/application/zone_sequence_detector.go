@@ -0,0 +1,137 @@
+package application
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+// ZoneSequence reports an object that entered the zones of a zone sequence rule in order
+type ZoneSequence struct {
+	Rule   *config.ZoneSequenceRule
+	Object domain.FrigateBefore
+	// Started is when the object entered the first zone of the sequence
+	Started time.Time
+}
+
+// zoneEntry records when an object entered a zone
+type zoneEntry struct {
+	zone string
+	at   time.Time
+}
+
+// zoneTrack is the zone history of one tracked object
+type zoneTrack struct {
+	entries  []zoneEntry
+	current  []string
+	entered  []string
+	lastSeen time.Time
+	// reported holds the rules the object was already reported for; an object is reported once per rule
+	reported map[int]bool
+}
+
+// ZoneSequenceDetector follows Frigate's events and records the order in which each object enters zones,
+// so movements such as street → driveway → porch can be told apart from the opposite direction.
+// The history of an object is cleared by its end event.
+type ZoneSequenceDetector struct {
+	config *config.Config
+	mu     sync.Mutex
+	tracks map[string]*zoneTrack
+}
+
+// NewZoneSequenceDetector creates a new zone sequence detector
+func NewZoneSequenceDetector(config *config.Config) *ZoneSequenceDetector {
+	return &ZoneSequenceDetector{
+		config: config,
+		tracks: make(map[string]*zoneTrack),
+	}
+}
+
+// Observe records the zones the object of an event entered since its previous event and returns the zone
+// sequences it completed
+func (d *ZoneSequenceDetector) Observe(event *domain.FrigateEvent, now time.Time) []ZoneSequence {
+	if len(d.config.ZoneSequences) == 0 {
+		return nil
+	}
+	object := event.Object()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if event.Type == "end" {
+		delete(d.tracks, object.ID)
+		return nil
+	}
+
+	track, ok := d.tracks[object.ID]
+	if !ok {
+		track = &zoneTrack{reported: make(map[int]bool)}
+		d.tracks[object.ID] = track
+	}
+
+	// entered_zones lists each zone once, in order, and catches zones passed between two events;
+	// current_zones catches an object coming back to a zone it entered before
+	var added []string
+	for _, zone := range object.EnteredZones {
+		if !slices.Contains(track.entered, zone) && !slices.Contains(added, zone) {
+			added = append(added, zone)
+		}
+	}
+	for _, zone := range object.CurrentZones {
+		if !slices.Contains(track.current, zone) && !slices.Contains(added, zone) {
+			added = append(added, zone)
+		}
+	}
+	for _, zone := range added {
+		track.entries = append(track.entries, zoneEntry{zone: zone, at: now})
+	}
+	track.current = slices.Clone(object.CurrentZones)
+	track.entered = slices.Clone(object.EnteredZones)
+	track.lastSeen = now
+
+	for id, stale := range d.tracks {
+		if now.Sub(stale.lastSeen) > staleTrackAge {
+			delete(d.tracks, id)
+		}
+	}
+
+	var sequences []ZoneSequence
+	for i := range d.config.ZoneSequences {
+		rule := &d.config.ZoneSequences[i]
+		if track.reported[i] || !matchesAny(rule.Cameras, object.Camera) || !matchesAny(rule.Labels, object.Label) {
+			continue
+		}
+		// Only a sequence completed by this event counts, so an object is not reported for history alone
+		if !slices.Contains(added, rule.Zones[len(rule.Zones)-1]) {
+			continue
+		}
+		if started, ok := track.match(rule, now); ok {
+			track.reported[i] = true
+			sequences = append(sequences, ZoneSequence{Rule: rule, Object: *object, Started: started})
+		}
+	}
+	return sequences
+}
+
+// match looks for the zones of a rule, in order, among the entries that fall within the rule's time limit
+// before now. Matching backwards from the latest entry finds the latest possible start.
+func (t *zoneTrack) match(rule *config.ZoneSequenceRule, now time.Time) (time.Time, bool) {
+	next := len(rule.Zones) - 1
+	for i := len(t.entries) - 1; i >= 0; i-- {
+		entry := t.entries[i]
+		if now.Sub(entry.at) > rule.Limit {
+			break
+		}
+		if entry.zone != rule.Zones[next] {
+			continue
+		}
+		if next == 0 {
+			return entry.at, true
+		}
+		next--
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,60 @@
+package application
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibin/frigate_alerter/internal/config"
+	"github.com/vibin/frigate_alerter/internal/domain"
+)
+
+func TestZoneSequenceDetector(t *testing.T) {
+	cfg := &config.Config{ZoneSequences: []config.ZoneSequenceRule{
+		{Name: "approaching", Zones: []string{"street", "driveway", "porch"}, Within: "1m", Limit: time.Minute},
+		{Name: "leaving", Zones: []string{"porch", "driveway", "street"}, Within: "1m", Limit: time.Minute},
+	}}
+	start := time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		// events holds the current zone and the entered zones, in order, of each event of one person;
+		// the events are a second apart except for a pause before the last one
+		events [][2]string
+		pause  time.Duration
+		want   []string
+	}{
+		{"approaching", [][2]string{{"street", "street"}, {"driveway", "street,driveway"}, {"porch", "street,driveway,porch"}}, 0, []string{"approaching"}},
+		{"leaving", [][2]string{{"porch", "porch"}, {"driveway", "porch,driveway"}, {"street", "porch,driveway,street"}}, 0, []string{"leaving"}},
+		{"out of order", [][2]string{{"driveway", "driveway"}, {"street", "driveway,street"}, {"porch", "driveway,street,porch"}}, 0, nil},
+		{"too slow", [][2]string{{"street", "street"}, {"driveway", "street,driveway"}, {"porch", "street,driveway,porch"}}, time.Minute, nil},
+		{"zone passed between events", [][2]string{{"street", "street"}, {"porch", "street,driveway,porch"}}, 0, []string{"approaching"}},
+		{"there and back", [][2]string{{"street", "street"}, {"driveway", "street,driveway"}, {"porch", "street,driveway,porch"}, {"driveway", "street,driveway,porch"}, {"street", "street,driveway,porch"}}, 0, []string{"approaching", "leaving"}},
+		{"reported once per rule", [][2]string{{"street", "street"}, {"driveway", "street,driveway"}, {"porch", "street,driveway,porch"}, {"driveway", "street,driveway,porch"}, {"porch", "street,driveway,porch"}}, 0, []string{"approaching"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewZoneSequenceDetector(cfg)
+			now := start
+			var got []string
+			for i, zones := range tt.events {
+				eventType := "update"
+				if i == 0 {
+					eventType = "new"
+				}
+				if i == len(tt.events)-1 {
+					now = now.Add(tt.pause)
+				}
+				person := domain.FrigateBefore{ID: "1", Camera: "front_door", Label: "person", CurrentZones: []string{zones[0]}, EnteredZones: strings.Split(zones[1], ",")}
+				for _, sequence := range detector.Observe(&domain.FrigateEvent{Type: eventType, After: person}, now) {
+					got = append(got, sequence.Rule.Name)
+				}
+				now = now.Add(time.Second)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("sequences %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
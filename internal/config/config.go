@@ -2,8 +2,16 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/vibin/frigate_alerter/internal/domain"
+	"github.com/vibin/frigate_alerter/internal/expr"
 )
 
 // Config holds the application configuration
@@ -16,18 +24,402 @@ type Config struct {
 	TimeZone         string `json:"time_zone"`
 	ServerPort       string `json:"server_port"`
 	Location         *time.Location
+
+	// WebDir optionally overrides the embedded templates and static files; files are reloaded on every request
+	WebDir string `json:"web_dir"`
+
+	// HealthRequired lists the dependencies that must be healthy for the service to be ready
+	HealthRequired []string `json:"health_required"`
+	// HealthMaxMessageAge is how long MQTT may stay silent before it is reported unhealthy ("" disables the check)
+	HealthMaxMessageAge string        `json:"health_max_message_age"`
+	MaxMessageAge       time.Duration `json:"-"`
+
+	// Modes maps arming mode names to the cameras and labels that alert in them; no modes means every camera alerts
+	Modes map[string]ModeConfig `json:"modes"`
+	// DefaultMode applies when neither a schedule window nor a manual mode does
+	DefaultMode string `json:"default_mode"`
+	// Schedule switches modes by weekly time windows evaluated in TimeZone; the first matching window wins
+	Schedule []ScheduleWindow `json:"schedule"`
+	// ModeCommandTopic is the MQTT topic on which the mode can be set manually
+	ModeCommandTopic string `json:"mode_command_topic"`
+	// PresenceTopics are MQTT topics reporting whether a person is home, one per person, e.g. Home Assistant person states
+	PresenceTopics []string `json:"presence_topics"`
+	// PresenceHomeStates are the states that mean a person is home; any other state means away
+	PresenceHomeStates []string `json:"presence_home_states"`
+	// PresenceHomeMode applies while anyone is home, PresenceAwayMode once everyone has left
+	PresenceHomeMode string `json:"presence_home_mode"`
+	PresenceAwayMode string `json:"presence_away_mode"`
+	// AlarmPanelTopic is an MQTT topic reporting the state of an alarm panel, such as armed_away or disarmed
+	AlarmPanelTopic string `json:"alarm_panel_topic"`
+	// AlarmPanelModes maps alarm panel states to modes; in other states presence or the schedule decides
+	AlarmPanelModes map[string]string `json:"alarm_panel_modes"`
+
+	// SMTP settings of the email notifier, which is available to escalations when SMTPHost is set
+	SMTPHost     string   `json:"smtp_host"`
+	SMTPPort     string   `json:"smtp_port"`
+	SMTPUsername string   `json:"smtp_username"`
+	SMTPPassword string   `json:"smtp_password"`
+	EmailFrom    string   `json:"email_from"`
+	EmailTo      []string `json:"email_to"`
+	// Telegram bot settings of the Telegram notifier, which is available to escalations when TelegramToken is set
+	TelegramToken  string `json:"telegram_token"`
+	TelegramChatID string `json:"telegram_chat_id"`
+
+	// CameraAdjacency lists, per camera, the cameras an object can walk to next; alerts on the same or adjacent
+	// cameras within IncidentWindow of each other form one incident. Without adjacency, alerts are not grouped.
+	CameraAdjacency map[string][]string `json:"camera_adjacency"`
+	// IncidentWindow is the longest gap between two alerts of the same incident
+	IncidentWindow string        `json:"incident_window"`
+	IncidentGap    time.Duration `json:"-"`
+
+	// Escalations re-notify about alerts nobody acknowledged; the first policy matching an alert applies
+	Escalations []EscalationPolicy `json:"escalations"`
+	// EscalationInterval is how often unacknowledged alerts are checked for due escalation steps
+	EscalationInterval      string        `json:"escalation_interval"`
+	EscalationCheckInterval time.Duration `json:"-"`
+
+	// CameraFrames are the detect resolutions of cameras, which turn Frigate's pixel boxes into normalized positions
+	CameraFrames map[string]FrameSize `json:"camera_frames"`
+	// DetectionFilters drop detections by the size, shape and position of their box; every matching filter applies
+	DetectionFilters []DetectionFilter `json:"detection_filters"`
+	// CameraMasks are polygons per camera that detections must be inside of (include) or outside of (exclude)
+	CameraMasks map[string]CameraMask `json:"camera_masks"`
+
+	// Loitering raises a loitering alert when an object stays in a zone for longer than a rule allows
+	Loitering []LoiteringRule `json:"loitering"`
+
+	// ZoneSequences raise an alert when an object passes through zones in a given order, such as toward the house
+	ZoneSequences []ZoneSequenceRule `json:"zone_sequences"`
+
+	// CountRules alert when Frigate counts at least a number of objects of a label on a camera or in a zone
+	CountRules []CountRule `json:"count_rules"`
+
+	// QuietHours hold back notifications while they last and send one digest of the held back alerts when they end
+	QuietHours []TimeWindow `json:"quiet_hours"`
+	// QuietHoursBypassCritical lets critical alerts notify during quiet hours as usual
+	QuietHoursBypassCritical bool `json:"quiet_hours_bypass_critical"`
+
+	// DailyReportAt sends the daily report at this time of day, e.g. "08:00"; empty disables it
+	DailyReportAt string `json:"daily_report_at"`
+	// WeeklyReportAt sends the weekly report at this day and time, e.g. "mon 08:00"; empty disables it
+	WeeklyReportAt string `json:"weekly_report_at"`
+	// ReportNotifiers deliver the reports: discord, email or telegram
+	ReportNotifiers    []string     `json:"report_notifiers"`
+	DailyReportMinute  int          `json:"-"`
+	WeeklyReportDay    time.Weekday `json:"-"`
+	WeeklyReportMinute int          `json:"-"`
+
+	// UnknownPlateHours makes alerts about license plates on neither watchlist critical during the window, e.g. at night
+	UnknownPlateHours *TimeWindow `json:"unknown_plate_hours"`
+
+	// AnomalyDetection learns the usual alert volume of each camera per hour of the week and alerts about unusual
+	// activity; nil disables it
+	AnomalyDetection *AnomalyConfig `json:"anomaly_detection"`
+
+	// AlertWhen is a condition expression alerts must meet to notify, such as `label == "person" && hour >= 22`;
+	// other alerts are recorded as muted. "" notifies about every alert
+	AlertWhen      string           `json:"alert_when"`
+	AlertCondition *expr.Expression `json:"-"`
+
+	// SeverityRules decide the severity of alerts; the first matching rule wins and DefaultSeverity applies otherwise
+	SeverityRules   []SeverityRule `json:"severity_rules"`
+	DefaultSeverity string         `json:"default_severity"`
+	// DiscordCriticalMention is put in front of Discord notifications of critical alerts, e.g. "@here"; "" mentions nobody
+	DiscordCriticalMention string `json:"discord_critical_mention"`
+
+	// FrigateSyncFalsePositives forwards alerts marked as false positives to Frigate's API
+	FrigateSyncFalsePositives bool `json:"frigate_sync_false_positives"`
+	// ShutdownTimeout bounds how long queued events and in-flight requests may take to finish on shutdown
+	ShutdownTimeout  string        `json:"shutdown_timeout"`
+	ShutdownDeadline time.Duration `json:"-"`
+}
+
+// ModeConfig decides which cameras and labels alert in an arming mode
+type ModeConfig struct {
+	// Default applies to cameras without a rule
+	Default bool `json:"default"`
+	// Rules turn alerts of a "camera" or of a "camera/label" on or off; camera/label rules take precedence
+	Rules map[string]bool `json:"rules"`
+}
+
+// TimeWindow is a weekly time window. A window whose end is not after its start runs past midnight
+// into the next day, and days refer to the day the window starts.
+type TimeWindow struct {
+	Days  []string `json:"days"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+
+	Weekdays    []time.Weekday `json:"-"`
+	StartMinute int            `json:"-"`
+	EndMinute   int            `json:"-"`
+}
+
+// ScheduleWindow activates a mode during a weekly time window
+type ScheduleWindow struct {
+	Mode string `json:"mode"`
+	TimeWindow
+}
+
+// FrameSize is the resolution of a camera's detect stream in pixels
+type FrameSize struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// DetectionFilter keeps only detections of the matching cameras and labels whose box satisfies every bound set.
+// Empty lists match everything and unset bounds do not apply.
+type DetectionFilter struct {
+	Cameras []string `json:"cameras"`
+	Labels  []string `json:"labels"`
+	// MinArea and MaxArea bound the box area in pixels
+	MinArea int `json:"min_area"`
+	MaxArea int `json:"max_area"`
+	// MinRatio and MaxRatio bound the aspect ratio of the box, its width divided by its height
+	MinRatio float64 `json:"min_ratio"`
+	MaxRatio float64 `json:"max_ratio"`
+	// Position bounds where the bottom centre of the box may be; it needs the camera frames of the filter's cameras
+	Position *NormalizedRect `json:"position"`
+}
+
+// NormalizedRect is a rectangle in coordinates relative to the frame, from 0 at the top left to 1 at the bottom right
+type NormalizedRect struct {
+	MinX float64 `json:"min_x"`
+	MinY float64 `json:"min_y"`
+	MaxX float64 `json:"max_x"`
+	MaxY float64 `json:"max_y"`
+}
+
+// CameraMask limits detections of a camera to where the bottom centre of their box lies. Polygons are lists of
+// [x, y] points in normalized coordinates. With include polygons, a detection must be inside one of them.
+type CameraMask struct {
+	Include [][][2]float64 `json:"include"`
+	Exclude [][][2]float64 `json:"exclude"`
+}
+
+// LoiteringRule measures how long objects of the matching cameras and labels stay in its zones.
+// Empty camera and label lists match everything.
+type LoiteringRule struct {
+	Cameras []string `json:"cameras"`
+	Labels  []string `json:"labels"`
+	// Zones are the Frigate zones the time is measured in; moving between them does not restart the clock
+	Zones []string `json:"zones"`
+	// After is how long an object may stay in the zones before it is loitering, e.g. "2m"
+	After string `json:"after"`
+
+	Dwell time.Duration `json:"-"`
+}
+
+// ZoneSequenceRule matches objects entering its zones in order within a time limit; other zones may be entered
+// in between. Empty camera and label lists match everything.
+type ZoneSequenceRule struct {
+	// Name describes the movement in alerts, e.g. "approaching the house"
+	Name    string   `json:"name"`
+	Cameras []string `json:"cameras"`
+	Labels  []string `json:"labels"`
+	// Zones are the Frigate zones in the order they must be entered, at least two
+	Zones []string `json:"zones"`
+	// Within is the longest time from entering the first zone to entering the last, e.g. "30s"
+	Within string `json:"within"`
+
+	Limit time.Duration `json:"-"`
+}
+
+// CountRule alerts when the live object count Frigate publishes on frigate/<camera or zone>/<label> reaches Min
+// while every When condition holds. It alerts again only after the count dropped to Clear or a condition ended.
+type CountRule struct {
+	// Name describes the rule in alerts; it defaults to the camera, zone and label
+	Name   string `json:"name"`
+	Camera string `json:"camera"`
+	// Zone counts the objects in a zone of the camera instead of the whole camera
+	Zone  string `json:"zone"`
+	Label string `json:"label"`
+	Min   int    `json:"min"`
+	// Clear is the count at or below which the rule re-arms, by default Min-1; a lower value keeps
+	// a count that hovers around Min from alerting over and over
+	Clear *int `json:"clear"`
+	// When lists states other MQTT topics must have, such as a garage door being open
+	When []TopicState `json:"when"`
+
+	ClearAt int `json:"-"`
+}
+
+// TopicState is a condition on the latest message of an MQTT topic, compared case-insensitively
+type TopicState struct {
+	Topic string `json:"topic"`
+	State string `json:"state"`
+}
+
+// AnomalyConfig tunes the anomaly detection; zero values take the defaults
+type AnomalyConfig struct {
+	// BaselineWeeks is how many weeks of history the baseline averages, 4 by default
+	BaselineWeeks int `json:"baseline_weeks"`
+	// Factor is how many times the usual count of an hour counts as unusual, 5 by default
+	Factor float64 `json:"factor"`
+	// MinCount is the fewest alerts in an hour that can be unusual in an hour with activity, 5 by default
+	MinCount int `json:"min_count"`
+	// SilentMinCount is the fewest alerts that are unusual in an hour without any in the baseline, 1 by default
+	SilentMinCount int `json:"silent_min_count"`
+}
+
+// SeverityRule assigns a severity to the alerts it matches. Empty lists match everything; zones match
+// when the object is in or has entered any of them.
+type SeverityRule struct {
+	Severity string `json:"severity"`
+	// Types are alert types such as "new" or "loitering"
+	Types   []string `json:"types"`
+	Cameras []string `json:"cameras"`
+	Labels  []string `json:"labels"`
+	Zones   []string `json:"zones"`
+	Modes   []string `json:"modes"`
+	// MinScore is the lowest detection score, between 0 and 1, the rule matches
+	MinScore float64 `json:"min_score"`
+	// Schedule restricts the rule to a weekly time window in TimeZone
+	Schedule *TimeWindow `json:"schedule"`
+	// When is a condition the alert must also meet, such as `score > 0.8 && "porch" in zones`
+	When      string           `json:"when"`
+	Condition *expr.Expression `json:"-"`
+}
+
+// Notifier names that escalation steps can use
+const (
+	NotifierDiscord  = "discord"
+	NotifierEmail    = "email"
+	NotifierTelegram = "telegram"
+)
+
+// EscalationPolicy escalates unacknowledged alerts of the matching cameras, labels and modes step by step.
+// Empty lists match everything; the steps are the maximum number of escalations an alert gets.
+type EscalationPolicy struct {
+	Name    string           `json:"name"`
+	Cameras []string         `json:"cameras"`
+	Labels  []string         `json:"labels"`
+	Modes   []string         `json:"modes"`
+	Steps   []EscalationStep `json:"steps"`
+	// When is a condition the alert must also meet, such as `severity == "critical" && hour < 6`
+	When      string           `json:"when"`
+	Condition *expr.Expression `json:"-"`
+}
+
+// EscalationStep notifies again through a notifier once an alert has not been acknowledged for a while
+type EscalationStep struct {
+	// After is how long after the alert the step is due, e.g. "5m"
+	After    string `json:"after"`
+	Notifier string `json:"notifier"`
+
+	Delay time.Duration `json:"-"`
+}
+
+// conditionVariables are the variables the alert condition can use, evaluated once the severity is known
+var conditionVariables = map[string]expr.Type{
+	"type":      expr.String,
+	"camera":    expr.String,
+	"label":     expr.String,
+	"sub_label": expr.String,
+	"plate":     expr.String,
+	"mode":      expr.String,
+	"severity":  expr.String,
+	"score":     expr.Number,
+	"area":      expr.Number,
+	"zones":     expr.List,
+	"hour":      expr.Number,
+	"minute":    expr.Number,
+	"weekday":   expr.String,
+}
+
+// severityConditionVariables are the variables of severity rule conditions; they decide the severity, so it is not known yet
+var severityConditionVariables = func() map[string]expr.Type {
+	variables := maps.Clone(conditionVariables)
+	delete(variables, "severity")
+	return variables
+}()
+
+// escalationConditionVariables are the variables of escalation policy conditions. Escalations evaluate stored
+// alerts, which keep no detected object, so its score, area, zones, sub label and plate are not available.
+var escalationConditionVariables = func() map[string]expr.Type {
+	variables := maps.Clone(conditionVariables)
+	for _, name := range []string{"score", "area", "zones", "sub_label", "plate"} {
+		delete(variables, name)
+	}
+	return variables
+}()
+
+// CompileAlertCondition compiles the condition alerts must meet to notify, reporting unknown variables
+// and type errors with their column
+func CompileAlertCondition(source string) (*expr.Expression, error) {
+	return compileCondition(source, conditionVariables)
+}
+
+// CompileEscalationCondition compiles the when condition of an escalation policy, which cannot use the detected object
+func CompileEscalationCondition(source string) (*expr.Expression, error) {
+	return compileCondition(source, escalationConditionVariables)
+}
+
+// CompileSeverityCondition compiles the when condition of a severity rule, which cannot use the severity
+func CompileSeverityCondition(source string) (*expr.Expression, error) {
+	return compileCondition(source, severityConditionVariables)
+}
+
+// compileCondition compiles a when condition against the variables it may use
+func compileCondition(source string, variables map[string]expr.Type) (*expr.Expression, error) {
+	condition, err := expr.Compile(source, variables)
+	if err != nil {
+		return nil, fmt.Errorf("when %q: %w", source, err)
+	}
+	return condition, nil
+}
+
+// weekdayNames maps the day names accepted in schedule windows to weekdays
+var weekdayNames = map[string][]time.Weekday{
+	"sun":      {time.Sunday},
+	"mon":      {time.Monday},
+	"tue":      {time.Tuesday},
+	"wed":      {time.Wednesday},
+	"thu":      {time.Thursday},
+	"fri":      {time.Friday},
+	"sat":      {time.Saturday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekends": {time.Saturday, time.Sunday},
+	"daily":    {time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
 }
 
 // LoadConfig loads configuration from environment variables and config.json file
 func LoadConfig() (*Config, error) {
 	config := &Config{
-		FrigateServer:    getEnv("FRIGATE_SERVER", "localhost"),
-		FrigatePort:      getEnv("FRIGATE_PORT", "5000"),
-		MQTTServer:       getEnv("MQTT_SERVER", "tcp://localhost:1883"),
-		DiscordToken:     getEnv("DISCORD_TOKEN", ""),
-		DiscordChannelID: getEnv("DISCORD_CHANNEL_ID", ""),
-		TimeZone:         getEnv("TIME_ZONE", "UTC"),
-		ServerPort:       getEnv("SERVER_PORT", "8080"),
+		FrigateServer:             getEnv("FRIGATE_SERVER", "localhost"),
+		FrigatePort:               getEnv("FRIGATE_PORT", "5000"),
+		MQTTServer:                getEnv("MQTT_SERVER", "tcp://localhost:1883"),
+		DiscordToken:              getEnv("DISCORD_TOKEN", ""),
+		DiscordChannelID:          getEnv("DISCORD_CHANNEL_ID", ""),
+		TimeZone:                  getEnv("TIME_ZONE", "UTC"),
+		ServerPort:                getEnv("SERVER_PORT", "8080"),
+		WebDir:                    getEnv("WEB_DIR", ""),
+		HealthRequired:            getEnvList("HEALTH_REQUIRED", []string{"mqtt", "discord", "frigate", "sqlite"}),
+		HealthMaxMessageAge:       getEnv("HEALTH_MAX_MESSAGE_AGE", ""),
+		ShutdownTimeout:           getEnv("SHUTDOWN_TIMEOUT", "30s"),
+		FrigateSyncFalsePositives: getEnvBool("FRIGATE_SYNC_FALSE_POSITIVES", false),
+		DefaultMode:               getEnv("DEFAULT_MODE", ""),
+		ModeCommandTopic:          getEnv("MODE_COMMAND_TOPIC", "frigate_alerter/mode/set"),
+		PresenceTopics:            getEnvList("PRESENCE_TOPICS", nil),
+		PresenceHomeStates:        getEnvList("PRESENCE_HOME_STATES", []string{"home"}),
+		PresenceHomeMode:          getEnv("PRESENCE_HOME_MODE", ""),
+		PresenceAwayMode:          getEnv("PRESENCE_AWAY_MODE", ""),
+		AlarmPanelTopic:           getEnv("ALARM_PANEL_TOPIC", ""),
+		SMTPHost:                  getEnv("SMTP_HOST", ""),
+		SMTPPort:                  getEnv("SMTP_PORT", "587"),
+		SMTPUsername:              getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:              getEnv("SMTP_PASSWORD", ""),
+		EmailFrom:                 getEnv("EMAIL_FROM", ""),
+		EmailTo:                   getEnvList("EMAIL_TO", nil),
+		TelegramToken:             getEnv("TELEGRAM_TOKEN", ""),
+		TelegramChatID:            getEnv("TELEGRAM_CHAT_ID", ""),
+		EscalationInterval:        getEnv("ESCALATION_INTERVAL", "30s"),
+		IncidentWindow:            getEnv("INCIDENT_WINDOW", "2m"),
+		DefaultSeverity:           getEnv("DEFAULT_SEVERITY", "warning"),
+		DiscordCriticalMention:    getEnv("DISCORD_CRITICAL_MENTION", "@here"),
+		QuietHoursBypassCritical:  getEnvBool("QUIET_HOURS_BYPASS_CRITICAL", true),
+		DailyReportAt:             getEnv("DAILY_REPORT_AT", ""),
+		WeeklyReportAt:            getEnv("WEEKLY_REPORT_AT", ""),
+		ReportNotifiers:           getEnvList("REPORT_NOTIFIERS", []string{NotifierDiscord}),
 	}
 
 	// Try to load from config.json if it exists
@@ -51,9 +443,452 @@ func LoadConfig() (*Config, error) {
 	}
 	config.Location = location
 
+	if config.MaxMessageAge, err = parseDuration("health_max_message_age", config.HealthMaxMessageAge); err != nil {
+		return nil, err
+	}
+	if config.ShutdownDeadline, err = parseDuration("shutdown_timeout", config.ShutdownTimeout); err != nil {
+		return nil, err
+	}
+	if config.ShutdownDeadline <= 0 {
+		return nil, fmt.Errorf("shutdown_timeout must be positive")
+	}
+	if config.EscalationCheckInterval, err = parseDuration("escalation_interval", config.EscalationInterval); err != nil {
+		return nil, err
+	}
+	if config.IncidentGap, err = parseDuration("incident_window", config.IncidentWindow); err != nil {
+		return nil, err
+	}
+	if len(config.CameraAdjacency) > 0 && config.IncidentGap <= 0 {
+		return nil, fmt.Errorf("incident_window must be positive when camera_adjacency is set")
+	}
+	if err := config.parseModes(); err != nil {
+		return nil, err
+	}
+	if err := config.parseEscalations(); err != nil {
+		return nil, err
+	}
+	if err := config.parseGeometry(); err != nil {
+		return nil, err
+	}
+	if err := config.parseLoitering(); err != nil {
+		return nil, err
+	}
+	if err := config.parseZoneSequences(); err != nil {
+		return nil, err
+	}
+	if err := config.parseCountRules(); err != nil {
+		return nil, err
+	}
+	if err := config.parseSeverities(); err != nil {
+		return nil, err
+	}
+	if config.AlertWhen != "" {
+		if config.AlertCondition, err = CompileAlertCondition(config.AlertWhen); err != nil {
+			return nil, fmt.Errorf("alert_when: %w", err)
+		}
+	}
+	if err := config.parseWatchlist(); err != nil {
+		return nil, err
+	}
+	if err := config.parseQuietHours(); err != nil {
+		return nil, err
+	}
+	if err := config.parseReports(); err != nil {
+		return nil, err
+	}
+	if err := config.parseAnomalyDetection(); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
+// parseModes validates the arming modes and parses the schedule windows
+func (c *Config) parseModes() error {
+	if len(c.Modes) == 0 {
+		if len(c.Schedule) > 0 {
+			return fmt.Errorf("schedule is set but no modes are configured")
+		}
+		if len(c.PresenceTopics) > 0 || c.AlarmPanelTopic != "" {
+			return fmt.Errorf("presence or alarm panel topics are set but no modes are configured")
+		}
+		return nil
+	}
+	if _, ok := c.Modes[c.DefaultMode]; !ok {
+		return fmt.Errorf("default_mode %q is not one of the configured modes", c.DefaultMode)
+	}
+
+	for i := range c.Schedule {
+		window := &c.Schedule[i]
+		if _, ok := c.Modes[window.Mode]; !ok {
+			return fmt.Errorf("schedule window %d: mode %q is not configured", i+1, window.Mode)
+		}
+
+		if err := window.parse(); err != nil {
+			return fmt.Errorf("schedule window %d: %w", i+1, err)
+		}
+	}
+	return c.parsePresence()
+}
+
+// parse resolves the days and clock times of a time window; a window without days applies daily
+func (w *TimeWindow) parse() error {
+	days := w.Days
+	if len(days) == 0 {
+		days = []string{"daily"}
+	}
+	w.Weekdays = nil
+	for _, day := range days {
+		weekdays, ok := weekdayNames[strings.ToLower(day)]
+		if !ok {
+			return fmt.Errorf("invalid day %q", day)
+		}
+		w.Weekdays = append(w.Weekdays, weekdays...)
+	}
+
+	var err error
+	if w.StartMinute, err = parseClock(w.Start); err != nil {
+		return fmt.Errorf("invalid start: %w", err)
+	}
+	if w.EndMinute, err = parseClock(w.End); err != nil {
+		return fmt.Errorf("invalid end: %w", err)
+	}
+	return nil
+}
+
+// parsePresence validates the modes that presence and alarm panel states switch to
+func (c *Config) parsePresence() error {
+	if len(c.PresenceTopics) > 0 {
+		for _, topic := range c.PresenceTopics {
+			if strings.ContainsAny(topic, "+#") {
+				return fmt.Errorf("presence topic %q must not contain wildcards, list one topic per person", topic)
+			}
+		}
+		if _, ok := c.Modes[c.PresenceHomeMode]; !ok {
+			return fmt.Errorf("presence_home_mode %q is not one of the configured modes", c.PresenceHomeMode)
+		}
+		if _, ok := c.Modes[c.PresenceAwayMode]; !ok {
+			return fmt.Errorf("presence_away_mode %q is not one of the configured modes", c.PresenceAwayMode)
+		}
+		for i, state := range c.PresenceHomeStates {
+			c.PresenceHomeStates[i] = strings.ToLower(state)
+		}
+	}
+
+	if c.AlarmPanelTopic != "" && len(c.AlarmPanelModes) == 0 {
+		return fmt.Errorf("alarm_panel_topic is set but alarm_panel_modes is empty")
+	}
+	modes := make(map[string]string, len(c.AlarmPanelModes))
+	for state, mode := range c.AlarmPanelModes {
+		if _, ok := c.Modes[mode]; !ok {
+			return fmt.Errorf("alarm panel state %q: mode %q is not configured", state, mode)
+		}
+		modes[strings.ToLower(state)] = mode
+	}
+	c.AlarmPanelModes = modes
+	return nil
+}
+
+// parseEscalations validates the escalation policies and parses their step delays
+func (c *Config) parseEscalations() error {
+	if len(c.Escalations) > 0 && c.EscalationCheckInterval <= 0 {
+		return fmt.Errorf("escalation_interval must be positive")
+	}
+	for i := range c.Escalations {
+		policy := &c.Escalations[i]
+		if policy.Name == "" {
+			policy.Name = fmt.Sprintf("escalation %d", i+1)
+		}
+		if len(policy.Steps) == 0 {
+			return fmt.Errorf("%s: at least one step is required", policy.Name)
+		}
+		for _, mode := range policy.Modes {
+			if _, ok := c.Modes[mode]; !ok {
+				return fmt.Errorf("%s: mode %q is not configured", policy.Name, mode)
+			}
+		}
+		if policy.When != "" {
+			var err error
+			if policy.Condition, err = CompileEscalationCondition(policy.When); err != nil {
+				return fmt.Errorf("%s: %w", policy.Name, err)
+			}
+		}
+
+		var previous time.Duration
+		for j := range policy.Steps {
+			step := &policy.Steps[j]
+			delay, err := time.ParseDuration(step.After)
+			if err != nil || delay <= previous {
+				return fmt.Errorf("%s: step %d must be due after the previous step, got after %q", policy.Name, j+1, step.After)
+			}
+			step.Delay = delay
+			previous = delay
+
+			if err := c.requireNotifier(step.Notifier); err != nil {
+				return fmt.Errorf("%s: step %d: %w", policy.Name, j+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseGeometry validates the detection filters and camera masks against the camera frames they need
+func (c *Config) parseGeometry() error {
+	for camera, frame := range c.CameraFrames {
+		if frame.Width <= 0 || frame.Height <= 0 {
+			return fmt.Errorf("camera frame %s: width and height must be positive", camera)
+		}
+	}
+	for i, filter := range c.DetectionFilters {
+		if filter.MaxArea > 0 && filter.MaxArea < filter.MinArea || filter.MaxRatio > 0 && filter.MaxRatio < filter.MinRatio {
+			return fmt.Errorf("detection filter %d: maximums must not be below minimums", i+1)
+		}
+		if filter.Position == nil {
+			continue
+		}
+		rect := filter.Position
+		if rect.MinX < 0 || rect.MinY < 0 || rect.MaxX > 1 || rect.MaxY > 1 || rect.MinX >= rect.MaxX || rect.MinY >= rect.MaxY {
+			return fmt.Errorf("detection filter %d: position must be a rectangle between 0 and 1", i+1)
+		}
+		if len(filter.Cameras) == 0 {
+			return fmt.Errorf("detection filter %d: a position needs cameras with camera_frames", i+1)
+		}
+		for _, camera := range filter.Cameras {
+			if _, ok := c.CameraFrames[camera]; !ok {
+				return fmt.Errorf("detection filter %d: camera %s has a position but no camera_frames entry", i+1, camera)
+			}
+		}
+	}
+	for camera, mask := range c.CameraMasks {
+		if _, ok := c.CameraFrames[camera]; !ok {
+			return fmt.Errorf("camera mask %s: camera has no camera_frames entry", camera)
+		}
+		for _, polygon := range append(slices.Clone(mask.Include), mask.Exclude...) {
+			if len(polygon) < 3 {
+				return fmt.Errorf("camera mask %s: polygons need at least three points", camera)
+			}
+		}
+	}
+	return nil
+}
+
+// parseLoitering validates the loitering rules and parses their dwell times
+func (c *Config) parseLoitering() error {
+	for i := range c.Loitering {
+		rule := &c.Loitering[i]
+		if len(rule.Zones) == 0 {
+			return fmt.Errorf("loitering rule %d: at least one zone is required", i+1)
+		}
+		dwell, err := time.ParseDuration(rule.After)
+		if err != nil || dwell <= 0 {
+			return fmt.Errorf("loitering rule %d: after must be a positive duration, got %q", i+1, rule.After)
+		}
+		rule.Dwell = dwell
+	}
+	return nil
+}
+
+// parseZoneSequences validates the zone sequence rules and parses their time limits
+func (c *Config) parseZoneSequences() error {
+	for i := range c.ZoneSequences {
+		rule := &c.ZoneSequences[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("zone sequence %d", i+1)
+		}
+		if len(rule.Zones) < 2 {
+			return fmt.Errorf("%s: at least two zones are required", rule.Name)
+		}
+		limit, err := time.ParseDuration(rule.Within)
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("%s: within must be a positive duration, got %q", rule.Name, rule.Within)
+		}
+		rule.Limit = limit
+	}
+	return nil
+}
+
+// parseCountRules validates the count rules and resolves their re-arm counts
+func (c *Config) parseCountRules() error {
+	for i := range c.CountRules {
+		rule := &c.CountRules[i]
+		if rule.Camera == "" || rule.Label == "" {
+			return fmt.Errorf("count rule %d: camera and label are required", i+1)
+		}
+		if strings.ContainsAny(rule.Camera+rule.Zone+rule.Label, "+#/") {
+			return fmt.Errorf("count rule %d: camera, zone and label must not contain MQTT wildcards or slashes", i+1)
+		}
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("%s %s", rule.CountedIn(), rule.Label)
+		}
+		if rule.Min < 1 {
+			return fmt.Errorf("%s: min must be at least 1", rule.Name)
+		}
+		rule.ClearAt = rule.Min - 1
+		if rule.Clear != nil {
+			if *rule.Clear < 0 || *rule.Clear >= rule.Min {
+				return fmt.Errorf("%s: clear must be between 0 and min-1", rule.Name)
+			}
+			rule.ClearAt = *rule.Clear
+		}
+		for j, condition := range rule.When {
+			if condition.Topic == "" || strings.ContainsAny(condition.Topic, "+#") {
+				return fmt.Errorf("%s: condition %d needs a topic without wildcards", rule.Name, j+1)
+			}
+		}
+	}
+	return nil
+}
+
+// CountedIn returns the zone the rule counts in, or its camera
+func (r *CountRule) CountedIn() string {
+	if r.Zone != "" {
+		return r.Zone
+	}
+	return r.Camera
+}
+
+// parseSeverities validates the default severity and the severity rules
+func (c *Config) parseSeverities() error {
+	if !domain.IsSeverity(c.DefaultSeverity) {
+		return fmt.Errorf("default_severity %q must be info, warning or critical", c.DefaultSeverity)
+	}
+	for i := range c.SeverityRules {
+		rule := &c.SeverityRules[i]
+		if !domain.IsSeverity(rule.Severity) {
+			return fmt.Errorf("severity rule %d: severity %q must be info, warning or critical", i+1, rule.Severity)
+		}
+		if rule.MinScore < 0 || rule.MinScore > 1 {
+			return fmt.Errorf("severity rule %d: min_score must be between 0 and 1", i+1)
+		}
+		for _, mode := range rule.Modes {
+			if _, ok := c.Modes[mode]; !ok {
+				return fmt.Errorf("severity rule %d: mode %q is not configured", i+1, mode)
+			}
+		}
+		if rule.Schedule != nil {
+			if err := rule.Schedule.parse(); err != nil {
+				return fmt.Errorf("severity rule %d: schedule: %w", i+1, err)
+			}
+		}
+		if rule.When != "" {
+			var err error
+			if rule.Condition, err = CompileSeverityCondition(rule.When); err != nil {
+				return fmt.Errorf("severity rule %d: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseWatchlist parses the hours during which unknown plates are critical
+func (c *Config) parseWatchlist() error {
+	if c.UnknownPlateHours == nil {
+		return nil
+	}
+	if err := c.UnknownPlateHours.parse(); err != nil {
+		return fmt.Errorf("unknown_plate_hours: %w", err)
+	}
+	return nil
+}
+
+// parseQuietHours parses the quiet hours windows
+func (c *Config) parseQuietHours() error {
+	for i := range c.QuietHours {
+		if err := c.QuietHours[i].parse(); err != nil {
+			return fmt.Errorf("quiet_hours %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// parseReports parses the times of the daily and weekly reports and checks their notifiers
+func (c *Config) parseReports() error {
+	var err error
+	if c.DailyReportAt != "" {
+		if c.DailyReportMinute, err = parseClock(c.DailyReportAt); err != nil {
+			return fmt.Errorf("daily_report_at: %w", err)
+		}
+	}
+	if c.WeeklyReportAt != "" {
+		day, clock, _ := strings.Cut(c.WeeklyReportAt, " ")
+		weekdays, ok := weekdayNames[strings.ToLower(day)]
+		if !ok || len(weekdays) != 1 {
+			return fmt.Errorf("weekly_report_at %q must start with a day such as mon", c.WeeklyReportAt)
+		}
+		c.WeeklyReportDay = weekdays[0]
+		if c.WeeklyReportMinute, err = parseClock(strings.TrimSpace(clock)); err != nil {
+			return fmt.Errorf("weekly_report_at: %w", err)
+		}
+	}
+	if c.DailyReportAt == "" && c.WeeklyReportAt == "" {
+		return nil
+	}
+	for _, name := range c.ReportNotifiers {
+		if err := c.requireNotifier(name); err != nil {
+			return fmt.Errorf("report_notifiers: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseAnomalyDetection applies the defaults of the anomaly detection and validates it
+func (c *Config) parseAnomalyDetection() error {
+	anomaly := c.AnomalyDetection
+	if anomaly == nil {
+		return nil
+	}
+	if anomaly.BaselineWeeks == 0 {
+		anomaly.BaselineWeeks = 4
+	}
+	if anomaly.Factor == 0 {
+		anomaly.Factor = 5
+	}
+	if anomaly.MinCount == 0 {
+		anomaly.MinCount = 5
+	}
+	if anomaly.SilentMinCount == 0 {
+		anomaly.SilentMinCount = 1
+	}
+	if anomaly.BaselineWeeks < 1 || anomaly.Factor <= 1 || anomaly.MinCount < 1 || anomaly.SilentMinCount < 1 {
+		return fmt.Errorf("anomaly_detection: baseline_weeks, min_count and silent_min_count must be positive and factor above 1")
+	}
+	return nil
+}
+
+// requireNotifier checks that a notifier exists and is configured
+func (c *Config) requireNotifier(name string) error {
+	switch name {
+	case NotifierDiscord:
+		return nil
+	case NotifierEmail:
+		if c.SMTPHost == "" || c.EmailFrom == "" || len(c.EmailTo) == 0 {
+			return fmt.Errorf("the email notifier needs smtp_host, email_from and email_to")
+		}
+		return nil
+	case NotifierTelegram:
+		if c.TelegramToken == "" || c.TelegramChatID == "" {
+			return fmt.Errorf("the telegram notifier needs telegram_token and telegram_chat_id")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown notifier %q, use discord, email or telegram", name)
+	}
+}
+
+// Adjacent reports whether an object can move directly between two cameras; adjacency applies in both directions
+func (c *Config) Adjacent(a string, b string) bool {
+	return slices.Contains(c.CameraAdjacency[a], b) || slices.Contains(c.CameraAdjacency[b], a)
+}
+
+// parseClock parses a HH:MM time of day into minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
 // getEnv gets an environment variable or returns the default value
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -61,3 +896,44 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvBool gets a boolean environment variable or returns the default value if it is unset or invalid
+func getEnvBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvList gets a comma separated environment variable or returns the default value
+func getEnvList(key string, defaultValue []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// parseDuration parses an optional duration setting, treating an empty value as zero
+func parseDuration(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+	return d, nil
+}
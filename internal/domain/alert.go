@@ -1,37 +1,117 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 )
 
 // Alert represents a detection alert from Frigate
 type Alert struct {
-	ID              string    `json:"id"`
-	Type            string    `json:"type"`
-	CameraName      string    `json:"camera_name"`
-	TriggeredAt     time.Time `json:"triggered_at"`
-	AlertMessage    string    `json:"alert_message"`
+	ID             string     `json:"id"`
+	Type           string     `json:"type"`
+	CameraName     string     `json:"camera_name"`
+	Label          string     `json:"label"`
+	EventID        string     `json:"event_id,omitempty"`
+	TriggeredAt    time.Time  `json:"triggered_at"`
+	AlertMessage   string     `json:"alert_message"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	FalsePositive  bool       `json:"false_positive"`
+	// SuppressedBy says why no notification was sent for the alert; it is empty for notified alerts
+	SuppressedBy string `json:"suppressed_by,omitempty"`
+	// Mode is the arming mode in effect when the alert was raised
+	Mode string `json:"mode,omitempty"`
+	// EscalationLevel counts the escalation steps sent because nobody acknowledged the alert
+	EscalationLevel int `json:"escalation_level,omitempty"`
+	// IncidentID links the alert to the incident it was grouped into
+	IncidentID string `json:"incident_id,omitempty"`
+	// Severity is info, warning or critical, as decided by the severity rules when the alert was raised
+	Severity string `json:"severity,omitempty"`
 }
 
+// AlertTypeManual is the type of snapshots requested through the API, which are never escalated
+const AlertTypeManual = "manual"
+
+// AlertTypeLoitering is the type of alerts about objects that stayed in a zone for too long
+const AlertTypeLoitering = "loitering"
+
+// AlertTypeCount is the type of alerts about too many objects on a camera or in a zone at once
+const AlertTypeCount = "count"
+
+// AlertTypeAnomaly is the type of alerts about unusual alert volume on a camera compared with its baseline
+const AlertTypeAnomaly = "anomaly"
+
+// SuppressedByCondition marks alerts that did not meet the alert condition
+const SuppressedByCondition = "condition"
+
+// AlertTypeZoneSequence is the type of alerts about objects that passed through zones in a given order
+const AlertTypeZoneSequence = "zone_sequence"
+
 // FrigateEvent represents the event data received from MQTT
 type FrigateEvent struct {
-	Type   string      `json:"type"`
+	Type   string        `json:"type"`
 	Before FrigateBefore `json:"before"`
+	After  FrigateBefore `json:"after"`
 }
 
-// FrigateBefore represents the "before" data in the Frigate event
+// Object returns the latest state of the tracked object, which is the "after" data when the event carries it
+func (e *FrigateEvent) Object() *FrigateBefore {
+	if e.After.ID != "" {
+		return &e.After
+	}
+	return &e.Before
+}
+
+// FrigateBefore represents the "before" and "after" data in the Frigate event
 type FrigateBefore struct {
-	ID       string          `json:"id"`
-	Camera   string          `json:"camera"`
-	FrameTime float64         `json:"frame_time"`
-	Snapshot  FrigateSnapshot `json:"snapshot"`
+	ID     string `json:"id"`
+	Camera string `json:"camera"`
+	Label  string `json:"label"`
+	// SubLabel and RecognizedLicensePlate identify the object once Frigate recognized a face or plate
+	SubLabel               SubLabel `json:"sub_label"`
+	RecognizedLicensePlate string   `json:"recognized_license_plate"`
+	FrameTime              float64  `json:"frame_time"`
+	Score                  float64  `json:"score"`
+	TopScore               float64  `json:"top_score"`
+	// Box is the latest bounding box of the object as [x_min, y_min, x_max, y_max] in pixels, Area its size
+	Box  []int `json:"box"`
+	Area int   `json:"area"`
+	// CurrentZones are the zones the object is in, EnteredZones every zone it has entered since it appeared
+	CurrentZones []string        `json:"current_zones"`
+	EnteredZones []string        `json:"entered_zones"`
+	Snapshot     FrigateSnapshot `json:"snapshot"`
 }
 
 // FrigateSnapshot represents the snapshot data in the Frigate event
 type FrigateSnapshot struct {
-	FrameTime float64   `json:"frame_time"`
-	Box       []int     `json:"box"`
-	Area      int       `json:"area"`
-	Region    []int     `json:"region"`
-	Score     float64   `json:"score"`
+	FrameTime float64 `json:"frame_time"`
+	Box       []int   `json:"box"`
+	Area      int     `json:"area"`
+	Region    []int   `json:"region"`
+	Score     float64 `json:"score"`
+}
+
+// AlertFilter narrows down which alerts are queried; zero values disable a condition
+type AlertFilter struct {
+	Camera string
+	Label  string
+	Type   string
+	// Incident restricts the filter to the alerts of one incident
+	Incident string
+	Severity string
+	From     time.Time
+	To       time.Time
+	Limit    int
+	Offset   int
+}
+
+// ParseFilterTime parses a range bound given as an RFC 3339 timestamp or as a YYYY-MM-DD date in the given location
+func ParseFilterTime(value string, location *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, location); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 timestamp nor a YYYY-MM-DD date", value)
 }
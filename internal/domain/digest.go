@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// SuppressedByQuietHours marks alerts that were held back during quiet hours and summarized in a digest instead
+const SuppressedByQuietHours = "quiet_hours"
+
+// DigestCount is the number of alerts of a label on a camera during quiet hours
+type DigestCount struct {
+	Camera string `json:"camera"`
+	Label  string `json:"label"`
+	Count  int    `json:"count"`
+}
+
+// Digest summarizes the alerts held back during quiet hours
+type Digest struct {
+	From   time.Time     `json:"from"`
+	To     time.Time     `json:"to"`
+	Total  int           `json:"total"`
+	Counts []DigestCount `json:"counts"`
+	// Highlights are the alerts whose snapshots make up the collage, most severe first
+	Highlights []*Alert `json:"highlights"`
+	// Collage is a JPEG grid of the highlight snapshots, or nil when Frigate had none of them
+	Collage []byte `json:"-"`
+}
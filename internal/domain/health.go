@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+)
+
+// Dependency states reported by health checks
+const (
+	StateConnecting   = "connecting"
+	StateConnected    = "connected"
+	StateDisconnected = "disconnected"
+	StateUnreachable  = "unreachable"
+	StateReadOnly     = "read_only"
+)
+
+// DependencyStatus represents the health of a single external dependency
+type DependencyStatus struct {
+	Name           string     `json:"name"`
+	Healthy        bool       `json:"healthy"`
+	Required       bool       `json:"required"`
+	State          string     `json:"state"`
+	Detail         string     `json:"detail,omitempty"`
+	LastMessageAt  *time.Time `json:"last_message_at,omitempty"`
+	LastMessageAge string     `json:"last_message_age,omitempty"`
+	CheckedAt      time.Time  `json:"checked_at"`
+}
+
+// ReadinessReport represents the combined state of all dependencies
+type ReadinessReport struct {
+	Ready        bool               `json:"ready"`
+	CheckedAt    time.Time          `json:"checked_at"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrIncidentNotFound is returned when no incident has the requested ID
+var ErrIncidentNotFound = errors.New("incident not found")
+
+// Incident groups the alerts of one object moving past adjacent cameras, such as a person walking up the driveway to the front door
+type Incident struct {
+	ID          string    `json:"id"`
+	StartedAt   time.Time `json:"started_at"`
+	LastAlertAt time.Time `json:"last_alert_at"`
+	// Cameras is the path of the incident: the cameras in the order it reached them, repeated when it returns to one
+	Cameras    []string `json:"cameras"`
+	Labels     []string `json:"labels"`
+	AlertCount int      `json:"alert_count"`
+	// Severity is the highest severity of the alerts of the incident
+	Severity string `json:"severity,omitempty"`
+	// MessageID identifies the notification that is updated as the incident grows
+	MessageID string `json:"-"`
+}
+
+// IncidentDetails is an incident together with its alerts, oldest first
+type IncidentDetails struct {
+	Incident *Incident `json:"incident"`
+	Alerts   []*Alert  `json:"alerts"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// SuppressedByMode marks alerts that were recorded without a notification because the current mode disarms them
+const SuppressedByMode = "mode"
+
+// Sources of the current arming mode
+const (
+	ModeSourceManual     = "manual"
+	ModeSourceAlarmPanel = "alarm_panel"
+	ModeSourcePresence   = "presence"
+	ModeSourceSchedule   = "schedule"
+	ModeSourceDefault    = "default"
+	// ModeSourceDisabled means no modes are configured and every camera alerts
+	ModeSourceDisabled = "disabled"
+)
+
+// ErrInvalidMode is returned when a manual mode is not configured or does not end in the future
+var ErrInvalidMode = errors.New("invalid mode")
+
+// ModeOverride is a mode set manually, which takes precedence over the schedule until it expires or is cleared
+type ModeOverride struct {
+	Mode  string
+	Until *time.Time
+	SetBy string
+	SetAt time.Time
+}
+
+// ModeState describes the arming mode in effect and why
+type ModeState struct {
+	Mode   string     `json:"mode"`
+	Source string     `json:"source"`
+	Until  *time.Time `json:"until,omitempty"`
+	SetBy  string     `json:"set_by,omitempty"`
+	Modes  []string   `json:"modes"`
+	// Presence holds the last state reported on each presence topic
+	Presence map[string]string `json:"presence,omitempty"`
+	// AlarmPanel is the last state reported by the alarm panel
+	AlarmPanel string `json:"alarm_panel,omitempty"`
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Report periods
+const (
+	ReportDaily  = "daily"
+	ReportWeekly = "weekly"
+)
+
+// ErrUnknownReportPeriod is returned for report periods other than daily and weekly
+var ErrUnknownReportPeriod = errors.New("unknown report period")
+
+// HealthOutage is a time a dependency was unhealthy; Ended is nil while it still is
+type HealthOutage struct {
+	ID         string     `json:"id"`
+	Dependency string     `json:"dependency"`
+	State      string     `json:"state"`
+	Detail     string     `json:"detail,omitempty"`
+	Started    time.Time  `json:"started"`
+	Ended      *time.Time `json:"ended,omitempty"`
+}
+
+// ReportCamera is the activity of one camera in a report
+type ReportCamera struct {
+	Camera   string         `json:"camera"`
+	Total    int            `json:"total"`
+	Previous int            `json:"previous"`
+	ByLabel  map[string]int `json:"by_label"`
+}
+
+// ReportHour is the number of alerts raised at one hour of the day in a report
+type ReportHour struct {
+	Hour  int `json:"hour"`
+	Count int `json:"count"`
+}
+
+// Report summarizes the alerts of a day or week and compares them with the period before
+type Report struct {
+	Period   string    `json:"period"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Total    int       `json:"total"`
+	Previous int       `json:"previous"`
+	// Cameras are ordered by their number of alerts, busiest first, and include cameras only active in the period before
+	Cameras []ReportCamera `json:"cameras"`
+	// BusiestHours are the hours of the day with the most alerts, busiest first
+	BusiestHours []ReportHour `json:"busiest_hours"`
+	// Outages are the dependency outages that overlap the period
+	Outages []HealthOutage `json:"outages"`
+}
+
+// Change returns the difference in alerts to the period before as a signed percentage, or "new" when there were none
+func (r *Report) Change() string {
+	return percentChange(r.Total, r.Previous)
+}
+
+// Change returns the difference in alerts of the camera to the period before
+func (c *ReportCamera) Change() string {
+	return percentChange(c.Total, c.Previous)
+}
+
+// percentChange formats the change from previous to current
+func percentChange(current int, previous int) string {
+	switch {
+	case previous == 0 && current == 0:
+		return "±0%"
+	case previous == 0:
+		return "new"
+	}
+	change := (current - previous) * 100 / previous
+	if change == 0 {
+		return "±0%"
+	}
+	return fmt.Sprintf("%+d%%", change)
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrAlertNotFound is returned when no alert has the requested ID
+var ErrAlertNotFound = errors.New("alert not found")
+
+// ErrInvalidReview is returned when an acknowledgement, note or false-positive flag is incomplete
+var ErrInvalidReview = errors.New("invalid review")
+
+// Audit actions recorded for changes to an alert
+const (
+	AuditAcknowledged         = "acknowledged"
+	AuditNoteAdded            = "note_added"
+	AuditMarkedFalsePositive  = "marked_false_positive"
+	AuditClearedFalsePositive = "cleared_false_positive"
+	AuditFrigateSynced        = "frigate_synced"
+	AuditFrigateSyncFailed    = "frigate_sync_failed"
+	AuditEscalated            = "escalated"
+	AuditEscalationFailed     = "escalation_failed"
+)
+
+// AlertNote is a free-text note attached to an alert
+type AlertNote struct {
+	ID        int64     `json:"id"`
+	AlertID   string    `json:"alert_id"`
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditEntry records who changed an alert, how and when
+type AuditEntry struct {
+	ID        int64     `json:"id"`
+	AlertID   string    `json:"alert_id"`
+	Action    string    `json:"action"`
+	Actor     string    `json:"actor"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AlertDetails is an alert together with its notes and audit trail, oldest first
+type AlertDetails struct {
+	Alert *Alert       `json:"alert"`
+	Notes []AlertNote  `json:"notes"`
+	Audit []AuditEntry `json:"audit"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "slices"
+
+// Severity levels of an alert, from least to most urgent
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Severities lists the severity levels from least to most urgent
+var Severities = []string{SeverityInfo, SeverityWarning, SeverityCritical}
+
+// IsSeverity reports whether value is a known severity level
+func IsSeverity(value string) bool {
+	return slices.Contains(Severities, value)
+}
+
+// SeverityRank orders severity levels; unknown levels, such as those of alerts recorded before severities existed, rank lowest
+func SeverityRank(severity string) int {
+	return slices.Index(Severities, severity)
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// SuppressedBySnooze marks alerts that were recorded without a notification because the camera was snoozed
+const SuppressedBySnooze = "snooze"
+
+// Snooze silences notifications of a camera, or of a single label on a camera, until it expires
+type Snooze struct {
+	Camera    string    `json:"camera"`
+	Label     string    `json:"label,omitempty"`
+	Until     time.Time `json:"until"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Matches reports whether the snooze silences an alert with the given label at time t.
+// A snooze without a label silences every label of its camera.
+func (s Snooze) Matches(label string, t time.Time) bool {
+	return t.Before(s.Until) && (s.Label == "" || s.Label == label)
+}
+
+// ErrInvalidSnooze is returned when a snooze has no camera or does not end in the future
+var ErrInvalidSnooze = errors.New("invalid snooze")
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+)
+
+// AlertStats represents aggregated alert counts over a time range
+type AlertStats struct {
+	From    *time.Time    `json:"from,omitempty"`
+	To      *time.Time    `json:"to,omitempty"`
+	Total   int           `json:"total"`
+	Cameras []CameraStats `json:"cameras"`
+	Hourly  []StatsBucket `json:"hourly"`
+	Daily   []StatsBucket `json:"daily"`
+}
+
+// CameraStats represents the alert totals of a single camera
+type CameraStats struct {
+	Camera      string         `json:"camera"`
+	Total       int            `json:"total"`
+	LastAlertAt *time.Time     `json:"last_alert_at,omitempty"`
+	ByLabel     map[string]int `json:"by_label"`
+	ByType      map[string]int `json:"by_type"`
+}
+
+// StatsBucket represents the number of alerts of a camera, label and type within one hour or day
+type StatsBucket struct {
+	Start  time.Time `json:"start"`
+	Camera string    `json:"camera"`
+	Label  string    `json:"label"`
+	Type   string    `json:"type"`
+	Count  int       `json:"count"`
+}
+
+// HeatmapCell represents the number of alerts of a camera at one hour of one weekday
+type HeatmapCell struct {
+	Camera  string       `json:"camera"`
+	Weekday time.Weekday `json:"weekday"`
+	Hour    int          `json:"hour"`
+	Count   int          `json:"count"`
+}
@@ -0,0 +1,69 @@
+package domain
+
+import "errors"
+
+// Checks an alert passes through, as named in decision traces
+const (
+	CheckEvent      = "event"
+	CheckFilters    = "filters"
+	CheckMode       = "mode"
+	CheckSnooze     = "snooze"
+	CheckSeverity   = "severity"
+	CheckCondition  = "condition"
+	CheckWatchlist  = "watchlist"
+	CheckQuietHours = "quiet_hours"
+	CheckIncident   = "incident"
+	CheckDetectors  = "detectors"
+)
+
+// Outcomes of a check in a decision trace
+const (
+	// OutcomePass lets the alert through the check unchanged
+	OutcomePass = "pass"
+	// OutcomeReject means no alert is raised at all
+	OutcomeReject = "reject"
+	// OutcomeMute means the alert is recorded without a notification
+	OutcomeMute = "mute"
+	// OutcomeMatch means a rule or list entry applied and changed the alert
+	OutcomeMatch = "match"
+	// OutcomeSkip means the check could not be evaluated in a dry run
+	OutcomeSkip = "skip"
+)
+
+// DecisionStep is the outcome of one check of a dry run
+type DecisionStep struct {
+	Check   string `json:"check"`
+	Outcome string `json:"outcome"`
+	Detail  string `json:"detail"`
+}
+
+// PlannedNotification is a notification the alerter would send about an alert
+type PlannedNotification struct {
+	Notifier string `json:"notifier"`
+	// After is how long after the alert the notification is due; escalation steps are only sent while nobody acknowledged the alert
+	After  string `json:"after,omitempty"`
+	Detail string `json:"detail"`
+}
+
+// DecisionTrace explains what the alerter would do with a Frigate event or a stored alert under the current rules.
+// Producing it saves and sends nothing.
+type DecisionTrace struct {
+	// AlertID is the stored alert that was replayed, if any
+	AlertID string        `json:"alert_id,omitempty"`
+	Event   *FrigateEvent `json:"event,omitempty"`
+	// Alert is the alert that would be raised, or nil when the event raises none
+	Alert         *Alert                `json:"alert"`
+	Steps         []DecisionStep        `json:"steps"`
+	Notifications []PlannedNotification `json:"notifications"`
+}
+
+// Add appends a step to the trace; it does nothing on a nil trace, so live alerts can skip tracing
+func (t *DecisionTrace) Add(check string, outcome string, detail string) {
+	if t == nil {
+		return
+	}
+	t.Steps = append(t.Steps, DecisionStep{Check: check, Outcome: outcome, Detail: detail})
+}
+
+// ErrInvalidRuleTest is returned when a dry run names no event, alert or time range, or more than one of them
+var ErrInvalidRuleTest = errors.New("invalid rule test")
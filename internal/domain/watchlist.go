@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Kinds of identities Frigate recognizes
+const (
+	// WatchlistSubLabel matches sub labels such as the names of recognized faces
+	WatchlistSubLabel = "sub_label"
+	// WatchlistPlate matches recognized license plates
+	WatchlistPlate = "plate"
+)
+
+// Watchlist lists an identity can be on
+const (
+	// WatchlistAllow mutes alerts about known people and vehicles
+	WatchlistAllow = "allow"
+	// WatchlistDeny makes alerts about unwanted people and vehicles critical
+	WatchlistDeny = "deny"
+)
+
+// SuppressedByWatchlist marks alerts that were recorded without a notification because the identity is allowlisted
+const SuppressedByWatchlist = "watchlist"
+
+// AlertTypeWatchlist is the type of alerts about an identity on the denylist recognized after the object appeared
+const AlertTypeWatchlist = "watchlist"
+
+// ErrInvalidWatchlistEntry is returned when a watchlist entry has an unknown kind or list, or no value
+var ErrInvalidWatchlistEntry = errors.New("invalid watchlist entry")
+
+// ErrWatchlistEntryNotFound is returned when no watchlist entry has the requested kind and value
+var ErrWatchlistEntryNotFound = errors.New("watchlist entry not found")
+
+// WatchlistEntry puts a sub label or license plate on the allowlist or denylist
+type WatchlistEntry struct {
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"`
+	List      string    `json:"list"`
+	Note      string    `json:"note,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NormalizeIdentity makes values of a kind comparable: plates ignore case, spaces and dashes, sub labels only case
+func NormalizeIdentity(kind string, value string) string {
+	value = strings.TrimSpace(value)
+	if kind != WatchlistPlate {
+		return strings.ToLower(value)
+	}
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '.' {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, value)
+}
+
+// SubLabel is the sub label of a Frigate object. Frigate sends it as a plain string in older versions
+// and as a [name, score] pair since face recognition was added; both decode to the name.
+type SubLabel string
+
+// UnmarshalJSON decodes a sub label given as a string, a [name, score] pair or null
+func (s *SubLabel) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case string:
+		*s = SubLabel(value)
+	case []interface{}:
+		if len(value) > 0 {
+			name, _ := value[0].(string)
+			*s = SubLabel(name)
+		}
+	default:
+		*s = ""
+	}
+	return nil
+}
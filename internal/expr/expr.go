@@ -0,0 +1,209 @@
+// Package expr implements the small boolean expression language of rule conditions, such as
+// `label == "person" && score > 0.8 && "porch" in zones && hour >= 22`.
+//
+// Expressions are compiled once against the variables a rule may use, so unknown variables and type
+// mismatches are reported before any event is evaluated. They can only read the variables they are
+// given: there are no function calls, assignments or loops, and evaluation time grows with their length only.
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Type is the type of a value in an expression
+type Type int
+
+// The types of values; lists hold strings
+const (
+	Bool Type = iota + 1
+	Number
+	String
+	List
+)
+
+// String returns the name of the type as used in error messages
+func (t Type) String() string {
+	switch t {
+	case Bool:
+		return "boolean"
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	case List:
+		return "list"
+	default:
+		return "unknown"
+	}
+}
+
+// Env holds the values an expression is evaluated with: bool, float64, string or []string as declared.
+// Missing variables evaluate to the zero value of their type.
+type Env map[string]any
+
+// Error is a syntax or type error in an expression
+type Error struct {
+	// Column is the 1-based position in the source the error refers to
+	Column  int
+	Message string
+}
+
+// Error describes the error and where it is
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+// Expression is a compiled boolean expression
+type Expression struct {
+	source string
+	root   node
+}
+
+// Compile parses a boolean expression and checks it against the declared variables and their types
+func Compile(source string, variables map[string]Type) (*Expression, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, variables: variables}
+	root, typ, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEnd {
+		return nil, p.errorAt(next, "unexpected %s", next.describe())
+	}
+	if typ != Bool {
+		return nil, &Error{Column: 1, Message: fmt.Sprintf("the expression is a %s, it must be true or false", typ)}
+	}
+	return &Expression{source: strings.TrimSpace(source), root: root}, nil
+}
+
+// Eval evaluates the expression with the given variables
+func (e *Expression) Eval(env Env) bool {
+	return e.root.eval(env).(bool)
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// node is an element of a compiled expression; its type was checked when it was compiled
+type node interface {
+	eval(env Env) any
+}
+
+// literal is a constant value
+type literal struct {
+	value any
+}
+
+func (n literal) eval(Env) any {
+	return n.value
+}
+
+// variable reads a value from the environment
+type variable struct {
+	name string
+	typ  Type
+}
+
+func (n variable) eval(env Env) any {
+	if value, ok := env[n.name]; ok {
+		return value
+	}
+	switch n.typ {
+	case Bool:
+		return false
+	case Number:
+		return 0.0
+	case String:
+		return ""
+	default:
+		return []string(nil)
+	}
+}
+
+// not negates a boolean
+type not struct {
+	operand node
+}
+
+func (n not) eval(env Env) any {
+	return !n.operand.eval(env).(bool)
+}
+
+// negate changes the sign of a number
+type negate struct {
+	operand node
+}
+
+func (n negate) eval(env Env) any {
+	return -n.operand.eval(env).(float64)
+}
+
+// logical joins two booleans with && or ||, evaluating the right one only when needed
+type logical struct {
+	and         bool
+	left, right node
+}
+
+func (n logical) eval(env Env) any {
+	left := n.left.eval(env).(bool)
+	if left != n.and {
+		return left
+	}
+	return n.right.eval(env).(bool)
+}
+
+// comparison compares two values of the same type; ordering is only defined for numbers
+type comparison struct {
+	op          string
+	left, right node
+}
+
+func (n comparison) eval(env Env) any {
+	left, right := n.left.eval(env), n.right.eval(env)
+	switch n.op {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	}
+	a, b := left.(float64), right.(float64)
+	switch n.op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	default:
+		return a >= b
+	}
+}
+
+// membership reports whether a string is an element of a list, or a substring of a string
+type membership struct {
+	negated          bool
+	item, collection node
+}
+
+func (n membership) eval(env Env) any {
+	item := n.item.eval(env).(string)
+	found := false
+	switch collection := n.collection.eval(env).(type) {
+	case []string:
+		for _, element := range collection {
+			if element == item {
+				found = true
+				break
+			}
+		}
+	case string:
+		found = strings.Contains(collection, item)
+	}
+	return found != n.negated
+}
@@ -0,0 +1,136 @@
+package expr
+
+import (
+	"errors"
+	"testing"
+)
+
+var testVariables = map[string]Type{
+	"label":  String,
+	"score":  Number,
+	"zones":  List,
+	"parked": Bool,
+}
+
+func TestEval(t *testing.T) {
+	env := Env{"label": "person", "score": 0.9, "zones": []string{"driveway", "porch"}, "parked": true}
+
+	tests := []struct {
+		name   string
+		source string
+		want   bool
+	}{
+		// && binds tighter than ||, and ! and - tighter than comparisons
+		{"and before or", `true || false && false`, true},
+		{"parentheses", `(true || false) && false`, false},
+		{"not before and", `!false && false`, false},
+		{"not of a group", `!(false && true)`, true},
+		{"comparison before and", `score > 0.5 && label == "person"`, true},
+		{"comparison before or", `score < 0.5 || label != "car"`, true},
+		{"negative number", `-score < 0`, true},
+		{"double negation", `!!parked`, true},
+		{"short circuit", `parked || score > 1`, true},
+
+		{"in list", `"porch" in zones`, true},
+		{"not in list", `"street" not in zones`, true},
+		{"in literal list", `label in ["cat", "person"]`, true},
+		{"not in literal list", `label not in ["cat", "dog"]`, true},
+		{"in empty list", `label in []`, false},
+		{"substring", `"ers" in label`, true},
+		{"not substring", `"car" not in label`, true},
+		{"empty substring", `"" in label`, true},
+
+		{"escaped double quote", `"say \"hi\"" == 'say "hi"'`, true},
+		{"escaped single quote", `'it\'s' == "it's"`, true},
+		{"escaped backslash", `"a\\b" != "a\b"`, true},
+
+		{"number forms", `.5 == 0.5 && 1. == 1`, true},
+		{"ordering", `1 <= 1 && 1 >= 1 && !(1 < 1) && !(1 > 1)`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := Compile(tt.source, testVariables)
+			if err != nil {
+				t.Fatalf("Compile(%s) error = %v", tt.source, err)
+			}
+			if got := expression.Eval(env); got != tt.want {
+				t.Errorf("Eval(%s) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalMissingVariables(t *testing.T) {
+	// Variables missing from the environment evaluate to the zero value of their type
+	for _, source := range []string{
+		`label == ""`,
+		`score == 0`,
+		`"porch" not in zones`,
+		`!parked`,
+		`"" in label`,
+	} {
+		expression, err := Compile(source, testVariables)
+		if err != nil {
+			t.Fatalf("Compile(%s) error = %v", source, err)
+		}
+		if !expression.Eval(Env{}) {
+			t.Errorf("Eval(%s) with no variables = false, want true", source)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"chained comparison", `1 < score < 2`, `column 11: comparisons cannot be chained; join them with &&`},
+		{"chained equality", `label == "a" == true`, `column 14: comparisons cannot be chained; join them with &&`},
+		{"chained membership", `"a" in zones in zones`, `column 14: comparisons cannot be chained; join them with &&`},
+		{"unclosed parenthesis", `(parked || score > 1`, `column 21: expected ")" to close the "(" at column 1, found end of expression`},
+		{"unclosed nested parenthesis", `((parked)`, `column 10: expected ")" to close the "(" at column 1, found end of expression`},
+		{"unclosed list", `label in ["cat", "dog"`, `column 23: expected "," or "]" in the list at column 10, found end of expression`},
+		{"unclosed empty list", `label in [`, `column 11: lists hold strings, found end of expression`},
+		{"unclosed string", `label == "cat`, `column 10: string is not closed`},
+		{"number with two points", `score > 1.2.3`, `column 9: "1.2.3" is not a number`},
+		{"lone point", `score > .`, `column 9: "." is not a number`},
+		{"unknown variable", `scroe > 1`, `column 1: unknown variable "scroe", use one of label, parked, score, zones`},
+		{"single equals", `label = "cat"`, `column 7: unexpected character '=', did you mean "=="`},
+		{"single ampersand", `parked & parked`, `column 8: unexpected character '&', did you mean "&&"`},
+		{"not without in", `label not "cat"`, `column 7: "not" must be followed by "in"; use ! to negate`},
+		{"mixed types", `score == "high"`, `column 7: == compares values of the same type, not a number with a string`},
+		{"ordered strings", `label < "m"`, `column 7: < compares numbers, not strings`},
+		{"compared lists", `zones == ["porch"]`, `column 7: == cannot compare lists; use in to look for an element`},
+		{"in a number", `"a" in score`, `column 5: in looks in a list or string, not a number`},
+		{"number in a list", `score in zones`, `column 7: in looks for a string, not a number`},
+		{"list of numbers", `label in [1]`, `column 11: lists hold strings, found "1"`},
+		{"not a boolean", `score`, `column 1: the expression is a number, it must be true or false`},
+		{"and of a string", `label && parked`, `column 1: && needs true or false on its left, not a string`},
+		{"negated string", `!label`, `column 1: ! negates true or false, not a string; use != to compare`},
+		{"trailing token", `parked parked`, `column 8: unexpected "parked"`},
+		{"empty", ``, `column 1: expected a value, found end of expression`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.source, testVariables)
+			var exprErr *Error
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("Compile(%s) error = %v, want an *Error", tt.source, err)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Compile(%s) error = %q, want %q", tt.source, err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	expression, err := Compile(`  label == "cat"  `, testVariables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := expression.String(); got != `label == "cat"` {
+		t.Errorf("String() = %q, want the trimmed source", got)
+	}
+}
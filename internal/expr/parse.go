@@ -0,0 +1,318 @@
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind classifies the tokens of an expression
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+// token is a lexical element of an expression with its 1-based column
+type token struct {
+	kind   tokenKind
+	text   string
+	column int
+}
+
+// describe names the token for error messages
+func (t token) describe() string {
+	if t.kind == tokenEnd {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// operators are the operator and punctuation tokens, longest first so "<=" is not read as "<"
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ",", "-"}
+
+// comparisons are the operators comparing two values
+var comparisons = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// lex splits an expression into tokens; string literals keep their unescaped value as text
+func lex(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		column := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), column: column})
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), column: column})
+		case r == '"' || r == '\'':
+			var text strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				text.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, &Error{Column: column, Message: "string is not closed"}
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: text.String(), column: column})
+		default:
+			operator := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator == "" {
+				hint := ""
+				if r == '=' || r == '&' || r == '|' {
+					hint = fmt.Sprintf(", did you mean %q", string([]rune{r, r}))
+				}
+				return nil, &Error{Column: column, Message: fmt.Sprintf("unexpected character %q%s", r, hint)}
+			}
+			i += len([]rune(operator))
+			tokens = append(tokens, token{kind: tokenOperator, text: operator, column: column})
+		}
+	}
+	return append(tokens, token{kind: tokenEnd, column: len(runes) + 1}), nil
+}
+
+// parser builds and type checks the nodes of an expression by recursive descent.
+// From loosest to tightest: ||, &&, comparisons and in, then ! and -.
+type parser struct {
+	tokens    []token
+	pos       int
+	variables map[string]Type
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEnd {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the given operator or keyword
+func (p *parser) accept(text string) bool {
+	if t := p.peek(); (t.kind == tokenOperator || t.kind == tokenIdent) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorAt(t token, format string, args ...any) error {
+	return &Error{Column: t.column, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (node, Type, error) {
+	return p.parseLogical("||", false, p.parseAnd)
+}
+
+func (p *parser) parseAnd() (node, Type, error) {
+	return p.parseLogical("&&", true, p.parseComparison)
+}
+
+// parseLogical parses operands joined by a logical operator, all of which must be booleans
+func (p *parser) parseLogical(operator string, and bool, operand func() (node, Type, error)) (node, Type, error) {
+	start := p.peek()
+	left, typ, err := operand()
+	if err != nil {
+		return nil, 0, err
+	}
+	for {
+		op := p.peek()
+		if !p.accept(operator) {
+			return left, typ, nil
+		}
+		right, rightType, err := operand()
+		if err != nil {
+			return nil, 0, err
+		}
+		if typ != Bool {
+			return nil, 0, p.errorAt(start, "%s needs true or false on its left, not a %s", operator, typ)
+		}
+		if rightType != Bool {
+			return nil, 0, p.errorAt(op, "%s needs true or false on its right, not a %s", operator, rightType)
+		}
+		left = logical{and: and, left: left, right: right}
+	}
+}
+
+// parseComparison parses a single comparison or membership test; chains like a < b < c are rejected
+func (p *parser) parseComparison() (node, Type, error) {
+	left, leftType, err := p.parseUnary()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	op := p.peek()
+	negated := op.kind == tokenIdent && op.text == "not"
+	if negated || op.kind == tokenIdent && op.text == "in" {
+		p.next()
+		if negated && !p.accept("in") {
+			return nil, 0, p.errorAt(op, "\"not\" must be followed by \"in\"; use ! to negate")
+		}
+		collection, collectionType, err := p.parseUnary()
+		if err != nil {
+			return nil, 0, err
+		}
+		if leftType != String {
+			return nil, 0, p.errorAt(op, "in looks for a string, not a %s", leftType)
+		}
+		if collectionType != List && collectionType != String {
+			return nil, 0, p.errorAt(op, "in looks in a list or string, not a %s", collectionType)
+		}
+		return p.endComparison(membership{negated: negated, item: left, collection: collection})
+	}
+
+	if op.kind == tokenOperator && comparisons[op.text] {
+		p.next()
+		right, rightType, err := p.parseUnary()
+		if err != nil {
+			return nil, 0, err
+		}
+		if leftType != rightType {
+			return nil, 0, p.errorAt(op, "%s compares values of the same type, not a %s with a %s", op.text, leftType, rightType)
+		}
+		if leftType == List {
+			return nil, 0, p.errorAt(op, "%s cannot compare lists; use in to look for an element", op.text)
+		}
+		if op.text != "==" && op.text != "!=" && leftType != Number {
+			return nil, 0, p.errorAt(op, "%s compares numbers, not %ss", op.text, leftType)
+		}
+		return p.endComparison(comparison{op: op.text, left: left, right: right})
+	}
+	return left, leftType, nil
+}
+
+// endComparison rejects a comparison directly following another one
+func (p *parser) endComparison(n node) (node, Type, error) {
+	if t := p.peek(); t.kind == tokenOperator && comparisons[t.text] || t.kind == tokenIdent && (t.text == "in" || t.text == "not") {
+		return nil, 0, p.errorAt(t, "comparisons cannot be chained; join them with &&")
+	}
+	return n, Bool, nil
+}
+
+func (p *parser) parseUnary() (node, Type, error) {
+	op := p.peek()
+	switch {
+	case p.accept("!"):
+		operand, typ, err := p.parseUnary()
+		if err != nil {
+			return nil, 0, err
+		}
+		if typ != Bool {
+			return nil, 0, p.errorAt(op, "! negates true or false, not a %s; use != to compare", typ)
+		}
+		return not{operand: operand}, Bool, nil
+	case p.accept("-"):
+		operand, typ, err := p.parseUnary()
+		if err != nil {
+			return nil, 0, err
+		}
+		if typ != Number {
+			return nil, 0, p.errorAt(op, "- negates numbers, not a %s", typ)
+		}
+		return negate{operand: operand}, Number, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, Type, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, 0, p.errorAt(t, "%q is not a number", t.text)
+		}
+		return literal{value: value}, Number, nil
+	case tokenString:
+		return literal{value: t.text}, String, nil
+	case tokenIdent:
+		switch t.text {
+		case "true", "false":
+			return literal{value: t.text == "true"}, Bool, nil
+		case "in", "not":
+			return nil, 0, p.errorAt(t, "expected a value, found %q", t.text)
+		}
+		typ, ok := p.variables[t.text]
+		if !ok {
+			return nil, 0, p.errorAt(t, "unknown variable %q, use one of %s", t.text, p.variableNames())
+		}
+		return variable{name: t.text, typ: typ}, typ, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			n, typ, err := p.parseOr()
+			if err != nil {
+				return nil, 0, err
+			}
+			if closing := p.next(); closing.text != ")" || closing.kind != tokenOperator {
+				return nil, 0, p.errorAt(closing, "expected \")\" to close the \"(\" at column %d, found %s", t.column, closing.describe())
+			}
+			return n, typ, nil
+		case "[":
+			return p.parseList(t)
+		}
+	}
+	return nil, 0, p.errorAt(t, "expected a value, found %s", t.describe())
+}
+
+// parseList parses a list of string literals such as ["person", "car"]
+func (p *parser) parseList(open token) (node, Type, error) {
+	elements := []string{}
+	if p.accept("]") {
+		return literal{value: elements}, List, nil
+	}
+	for {
+		t := p.next()
+		if t.kind != tokenString {
+			return nil, 0, p.errorAt(t, "lists hold strings, found %s", t.describe())
+		}
+		elements = append(elements, t.text)
+		if p.accept("]") {
+			return literal{value: elements}, List, nil
+		}
+		if t := p.next(); t.text != "," || t.kind != tokenOperator {
+			return nil, 0, p.errorAt(t, "expected \",\" or \"]\" in the list at column %d, found %s", open.column, t.describe())
+		}
+	}
+}
+
+// variableNames lists the declared variables for error messages
+func (p *parser) variableNames() string {
+	names := make([]string, 0, len(p.variables))
+	for name := range p.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -1,6 +1,9 @@
 package ports
 
 import (
+	"context"
+	"time"
+
 	"github.com/vibin/frigate_alerter/internal/domain"
 )
 
@@ -14,4 +17,121 @@ type AlertRepository interface {
 	
 	// GetAlertsByCameraName retrieves alerts for a specific camera
 	GetAlertsByCameraName(cameraName string, limit int, offset int) ([]*domain.Alert, error)
+
+	// FindAlerts retrieves alerts matching the filter, newest first
+	FindAlerts(filter domain.AlertFilter) ([]*domain.Alert, error)
+
+	// StreamAlerts calls fn for every alert matching the filter, oldest first, without loading them all at once
+	StreamAlerts(ctx context.Context, filter domain.AlertFilter, fn func(alert *domain.Alert) error) error
+
+	// GetAlertStats aggregates alert counts per camera, label and type within the filter
+	GetAlertStats(filter domain.AlertFilter) (*domain.AlertStats, error)
+
+	// GetCameraStats returns the alert totals of each camera within the filter
+	GetCameraStats(filter domain.AlertFilter) ([]domain.CameraStats, error)
+
+	// GetFirstAlertTimes returns when each camera raised its first alert within the filter
+	GetFirstAlertTimes(filter domain.AlertFilter) (map[string]time.Time, error)
+
+	// GetActivityHeatmap counts alerts per camera by weekday and hour of day within the filter
+	GetActivityHeatmap(filter domain.AlertFilter) ([]domain.HeatmapCell, error)
+
+	// GetAlert retrieves a single alert, returning domain.ErrAlertNotFound if it does not exist
+	GetAlert(id string) (*domain.Alert, error)
+
+	// AcknowledgeAlert records who acknowledged an alert; acknowledging it again keeps the first acknowledgement
+	AcknowledgeAlert(id string, actor string, at time.Time) error
+
+	// AddAlertNote attaches a note to an alert and sets the note's ID
+	AddAlertNote(note *domain.AlertNote) error
+
+	// SetFalsePositive flags or unflags an alert as a false positive
+	SetFalsePositive(id string, actor string, falsePositive bool, at time.Time) error
+
+	// AddAuditEntry records an action on an alert that did not change it, such as syncing it to Frigate
+	AddAuditEntry(entry *domain.AuditEntry) error
+
+	// GetAlertNotes retrieves the notes of an alert, oldest first
+	GetAlertNotes(id string) ([]domain.AlertNote, error)
+
+	// GetAlertAudit retrieves the audit trail of an alert, oldest first
+	GetAlertAudit(id string) ([]domain.AuditEntry, error)
+
+	// GetUnacknowledgedAlerts retrieves the notified alerts since the given time that nobody acknowledged or flagged as false positive
+	GetUnacknowledgedAlerts(since time.Time) ([]*domain.Alert, error)
+
+	// SetEscalationLevel records that an escalation step was sent, unless the alert was acknowledged or escalated further meanwhile
+	SetEscalationLevel(id string, level int, entry *domain.AuditEntry) error
+}
+
+// SnoozeRepository defines the interface for storing camera snoozes
+type SnoozeRepository interface {
+	// SaveSnooze creates or replaces the snooze of a camera and label
+	SaveSnooze(snooze *domain.Snooze) error
+
+	// DeleteSnooze removes the snooze of a camera and label, if any
+	DeleteSnooze(camera string, label string) error
+
+	// GetActiveSnoozes retrieves the snoozes that have not expired at the given time
+	GetActiveSnoozes(now time.Time) ([]domain.Snooze, error)
+}
+
+// WatchlistRepository defines the interface for storing the allowlist and denylist of sub labels and plates
+type WatchlistRepository interface {
+	// GetWatchlist retrieves every watchlist entry, ordered by kind and value
+	GetWatchlist() ([]domain.WatchlistEntry, error)
+
+	// SaveWatchlistEntry creates or replaces the entry of a kind and value
+	SaveWatchlistEntry(entry *domain.WatchlistEntry) error
+
+	// DeleteWatchlistEntry removes the entry of a kind and value, returning domain.ErrWatchlistEntryNotFound if there is none
+	DeleteWatchlistEntry(kind string, value string) error
+}
+
+// HealthRepository defines the interface for storing dependency outages
+type HealthRepository interface {
+	// SaveHealthOutage creates or updates an outage
+	SaveHealthOutage(outage *domain.HealthOutage) error
+
+	// GetHealthOutages retrieves the outages that overlap the time range, oldest first; ongoing outages overlap any later range
+	GetHealthOutages(from time.Time, to time.Time) ([]domain.HealthOutage, error)
+}
+
+// DigestRepository defines the interface for recovering the alerts held back for the quiet hours digest
+type DigestRepository interface {
+	// GetDigestCursor retrieves when the latest alert of the last digest sent was raised, or the zero time if none was sent
+	GetDigestCursor() (time.Time, error)
+
+	// SaveDigestCursor records when the latest alert of a digest that was sent was raised
+	SaveDigestCursor(sentUntil time.Time) error
+
+	// GetHeldAlerts retrieves the alerts held back for the digest that were raised after the given time, oldest first
+	GetHeldAlerts(after time.Time) ([]*domain.Alert, error)
+}
+
+// ModeRepository defines the interface for persisting a manually set arming mode
+type ModeRepository interface {
+	// GetModeOverride retrieves the manual mode, or nil if none is set
+	GetModeOverride() (*domain.ModeOverride, error)
+
+	// SaveModeOverride sets the manual mode, replacing any previous one
+	SaveModeOverride(override *domain.ModeOverride) error
+
+	// DeleteModeOverride clears the manual mode
+	DeleteModeOverride() error
+}
+
+// IncidentRepository defines the interface for storing incidents
+type IncidentRepository interface {
+	// SaveIncident creates or updates an incident
+	SaveIncident(incident *domain.Incident) error
+
+	// GetIncident retrieves a single incident, returning domain.ErrIncidentNotFound if it does not exist
+	GetIncident(id string) (*domain.Incident, error)
+
+	// GetIncidents retrieves incidents, most recently active first
+	GetIncidents(limit int, offset int) ([]*domain.Incident, error)
+
+	// GetRecentIncidents retrieves the incidents with an alert at or after the given time, most recently active first
+	GetRecentIncidents(since time.Time) ([]*domain.Incident, error)
 }
@@ -1,6 +1,9 @@
 package ports
 
 import (
+	"context"
+	"io"
+
 	"github.com/vibin/frigate_alerter/internal/domain"
 )
 
@@ -10,6 +13,26 @@ type AlertNotifier interface {
 	SendAlert(alert *domain.Alert) error
 }
 
+// IncidentNotifier defines the interface for notifications that follow an incident as it grows
+type IncidentNotifier interface {
+	// SendIncident notifies about a new incident and returns the ID of the message to update later
+	SendIncident(incident *domain.Incident, alert *domain.Alert) (string, error)
+	// UpdateIncident updates the message of an incident with its latest alert
+	UpdateIncident(incident *domain.Incident, alert *domain.Alert, messageID string) error
+}
+
+// DigestNotifier defines the interface for sending the summary of alerts held back during quiet hours
+type DigestNotifier interface {
+	// SendDigest sends a digest with its collage, if any
+	SendDigest(digest *domain.Digest) error
+}
+
+// ReportNotifier defines the interface for delivering activity reports
+type ReportNotifier interface {
+	// SendReport sends a daily or weekly report
+	SendReport(report *domain.Report) error
+}
+
 // EventSubscriber defines the interface for subscribing to events
 type EventSubscriber interface {
 	// Subscribe starts listening for events
@@ -18,8 +41,32 @@ type EventSubscriber interface {
 	Close() error
 }
 
+// TopicSubscriber defines the interface for receiving raw messages on additional topics
+type TopicSubscriber interface {
+	// SubscribeTopic calls handler with every message published on topic, which may contain wildcards
+	SubscribeTopic(topic string, handler func(topic string, payload []byte)) error
+}
+
 // AlertService defines the interface for alert business logic
 type AlertService interface {
 	// ProcessEvent processes a Frigate event and triggers alerts if needed
 	ProcessEvent(event *domain.FrigateEvent) error
 }
+
+// HealthChecker defines the interface for reporting the state of a dependency
+type HealthChecker interface {
+	// CheckHealth reports the current state of the dependency
+	CheckHealth(ctx context.Context) domain.DependencyStatus
+}
+
+// SnapshotProvider defines the interface for fetching the snapshots archived with events
+type SnapshotProvider interface {
+	// GetEventSnapshot opens the snapshot stored for an event; the caller closes it
+	GetEventSnapshot(ctx context.Context, eventID string) (io.ReadCloser, error)
+}
+
+// FalsePositiveReporter defines the interface for forwarding false positives to the NVR
+type FalsePositiveReporter interface {
+	// ReportFalsePositive marks the event behind an alert as a false positive
+	ReportFalsePositive(ctx context.Context, eventID string) error
+}
@@ -0,0 +1,11 @@
+// Package web bundles the HTML templates and static assets of the web UI into the binary
+package web
+
+import (
+	"embed"
+)
+
+// Assets holds the templates and static files under their "templates/" and "static/" directories
+//
+//go:embed templates static
+var Assets embed.FS
@@ -1,5 +1,33 @@
 // Main JavaScript file for Frigate Alerter Web UI
 
+// Base path of the versioned REST API
+const API_BASE = '/api/v1';
+
+// apiRequest calls the REST API and resolves with the decoded JSON body,
+// or rejects with the message from the error envelope
+function apiRequest(path, options) {
+    return fetch(API_BASE + path, options)
+        .then(response => response.json().then(data => {
+            if (!response.ok) {
+                throw new Error(data.error ? data.error.message : response.statusText);
+            }
+            return data;
+        }));
+}
+
+// reviewUser returns the name recorded when alerts are acknowledged, annotated or flagged.
+// It is asked for once and remembered in the browser.
+function reviewUser() {
+    let user = localStorage.getItem('reviewUser');
+    if (!user) {
+        user = (prompt('Your name, recorded with your review:') || '').trim();
+        if (user) {
+            localStorage.setItem('reviewUser', user);
+        }
+    }
+    return user;
+}
+
 document.addEventListener('DOMContentLoaded', function() {
     // Enable tooltips everywhere
     const tooltips = document.querySelectorAll('[data-bs-toggle="tooltip"]');
@@ -40,4 +68,85 @@ document.addEventListener('DOMContentLoaded', function() {
     // Setup for any notification on the page
     const notifications = document.querySelectorAll('.alert-notification');
     notifications.forEach(setupNotificationFadeout);
+
+    // Show which dependencies are still connecting or unavailable
+    const dependencyStatus = document.getElementById('dependency-status');
+    function updateDependencyStatus() {
+        fetch('/readyz')
+            .then(response => response.json())
+            .then(report => {
+                const connecting = report.dependencies.filter(dep => dep.state === 'connecting').map(dep => dep.name);
+                const unhealthy = report.dependencies.filter(dep => !dep.healthy && dep.state !== 'connecting').map(dep => dep.name);
+
+                const messages = [];
+                if (connecting.length > 0) {
+                    messages.push('Still connecting to: ' + connecting.join(', '));
+                }
+                if (unhealthy.length > 0) {
+                    messages.push('Unavailable: ' + unhealthy.join(', '));
+                }
+
+                dependencyStatus.textContent = messages.join(' — ');
+                dependencyStatus.classList.toggle('d-none', messages.length === 0);
+            })
+            .catch(() => {
+                dependencyStatus.classList.add('d-none');
+            });
+    }
+
+    if (dependencyStatus) {
+        updateDependencyStatus();
+        setInterval(updateDependencyStatus, 10000);
+    }
+
+    // Show the arming mode and let it be changed from the navbar
+    const modeControl = document.getElementById('mode-control');
+    function renderMode(state) {
+        if (state.source === 'disabled') {
+            modeControl.classList.add('d-none');
+            return;
+        }
+        let label = (state.mode || 'none') + ' (' + state.source.replace('_', ' ') + ')';
+        if (state.until) {
+            label += ' until ' + new Date(state.until).toLocaleString();
+        }
+        document.getElementById('mode-current').textContent = label;
+
+        const menu = document.getElementById('mode-menu');
+        menu.innerHTML = '';
+        state.modes.forEach(mode => {
+            const item = document.createElement('li');
+            const button = document.createElement('button');
+            button.className = 'dropdown-item' + (mode === state.mode ? ' active' : '');
+            button.textContent = mode;
+            button.addEventListener('click', () => changeMode('PUT', { mode: mode, user: reviewUser() }));
+            item.appendChild(button);
+            menu.appendChild(item);
+        });
+        if (state.source === 'manual') {
+            menu.insertAdjacentHTML('beforeend', '<li><hr class="dropdown-divider"></li>');
+            const item = document.createElement('li');
+            const button = document.createElement('button');
+            button.className = 'dropdown-item';
+            button.textContent = 'Back to schedule';
+            button.addEventListener('click', () => changeMode('DELETE'));
+            item.appendChild(button);
+            menu.appendChild(item);
+        }
+        modeControl.classList.remove('d-none');
+    }
+    function changeMode(method, body) {
+        const options = { method: method };
+        if (body) {
+            options.headers = { 'Content-Type': 'application/json' };
+            options.body = JSON.stringify(body);
+        }
+        apiRequest('/mode', options)
+            .then(renderMode)
+            .catch(error => alert('Failed to change mode: ' + error.message));
+    }
+
+    if (modeControl) {
+        apiRequest('/mode').then(renderMode).catch(() => modeControl.classList.add('d-none'));
+    }
 });